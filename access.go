@@ -0,0 +1,199 @@
+package jLogger
+
+import (
+    "io"
+    "log"
+    "net/http"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/natefinch/lumberjack"
+)
+
+// CLF的时间格式
+const clfTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// AccessLogFormat 访问日志的格式
+type AccessLogFormat int
+
+const (
+    CommonLog   AccessLogFormat = iota // Apache Common Log Format
+    CombinedLog                        // Common加上Referer和User-Agent
+)
+
+// AccessLog 返回记录访问日志的http中间件，按Apache Common/Combined Log Format写入logDir下的 <logPrefix>_access.log，
+// 行首没有级别和时间前缀，GoAccess、awstats等分析工具可以直接读取。文件和其他日志一样按大小轮转。
+// 客户端地址按ClientIP取，经过反向代理时记录真实的客户端。访问日志在请求结束时同步写入，不经过缓冲区
+func (l *Logger) AccessLog(format AccessLogFormat) func(http.Handler) http.Handler {
+    out := l.pipeline().accessLogger()
+    return func(next http.Handler) http.Handler {
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            start := time.Now()
+            sw := &statusWriter{ResponseWriter: w}
+            next.ServeHTTP(sw, r)
+            out.Println(formatAccess(format, r, sw, start))
+        })
+    }
+}
+
+// 所有AccessLog中间件共用一个文件。和级别文件一样在openFiles中登记（WithSharedFiles的Logger共用同一个），
+// 多进程模式下加文件锁，并按平台和选项包装输出
+func (l *Logger) accessLogger() *log.Logger {
+    l.access_mu.Lock()
+    defer l.access_mu.Unlock()
+    if l.access != nil {
+        return l.access
+    }
+    var w io.Writer
+    if lj := l.claimAccessFile(); lj != nil {
+        w = lj
+    } else {
+        // 多进程模式或Close之后没有登记的文件，由本Logger自己打开和关闭
+        l.accessFile = newAccessFile(l.logDir, l.logPrefix)
+        w = l.accessFile
+        if l.multiProcess {
+            if lw, err := newLockedWriter(l.accessFile); err == nil {
+                w = lw
+            } else {
+                l.internalError("访问日志加文件锁失败:", err)
+            }
+        }
+    }
+    l.access = log.New(l.wrapOutput(w), "", 0)
+    return l.access
+}
+
+func newAccessFile(logDir, logPrefix string) *lumberjack.Logger {
+    return &lumberjack.Logger{
+        Filename:   filepath.Join(logDir, logPrefix+"_access.log"),
+        MaxSize:    50, // megabytes
+        MaxBackups: 365,
+        MaxAge:     30, // days
+        Compress:   true,
+        LocalTime:  true,
+    }
+}
+
+// Close时关闭本Logger自己打开的访问日志文件，登记在openFiles中的由最后一个使用者在releaseFiles时关闭
+func (l *Logger) closeAccessFile() {
+    l.access_mu.Lock()
+    defer l.access_mu.Unlock()
+    if l.accessFile != nil {
+        l.accessFile.Close()
+    }
+}
+
+// host ident authuser [time] "request" status bytes ["referer" "user-agent"]
+func formatAccess(format AccessLogFormat, r *http.Request, sw *statusWriter, start time.Time) string {
+    host := ClientIP(r)
+    user := "-"
+    if r.URL.User != nil && r.URL.User.Username() != "" {
+        user = r.URL.User.Username()
+    } else if name, _, ok := r.BasicAuth(); ok && name != "" {
+        user = name
+    }
+    uri := r.RequestURI
+    if uri == "" {
+        uri = r.URL.RequestURI()
+    }
+    size := "-"
+    if sw.bytes > 0 {
+        size = strconv.FormatInt(sw.bytes, 10)
+    }
+
+    var b strings.Builder
+    b.WriteString(clfField(host))
+    b.WriteString(" - ")
+    b.WriteString(clfField(user))
+    b.WriteString(" [")
+    b.WriteString(start.Format(clfTimeFormat))
+    b.WriteString("] \"")
+    b.WriteString(clfEscape(r.Method + " " + uri + " " + r.Proto))
+    b.WriteString("\" ")
+    b.WriteString(strconv.Itoa(sw.code()))
+    b.WriteByte(' ')
+    b.WriteString(size)
+    if format == CombinedLog {
+        b.WriteString(" \"")
+        b.WriteString(clfQuoted(r.Referer()))
+        b.WriteString("\" \"")
+        b.WriteString(clfQuoted(r.UserAgent()))
+        b.WriteByte('"')
+    }
+    return b.String()
+}
+
+// 不带引号的字段不能为空或包含空白
+func clfField(s string) string {
+    if s == "" {
+        return "-"
+    }
+    return strings.Join(strings.Fields(clfEscape(s)), "_")
+}
+
+// 引号内的可选内容，为空时写 "-"
+func clfQuoted(s string) string {
+    if s == "" {
+        return "-"
+    }
+    return clfEscape(s)
+}
+
+// 引号内的内容转义引号、反斜杠和控制字符，防止伪造字段或额外的行
+func clfEscape(s string) string {
+    var b strings.Builder
+    for i := 0; i < len(s); i++ {
+        c := s[i]
+        switch {
+        case c == '"' || c == '\\':
+            b.WriteByte('\\')
+            b.WriteByte(c)
+        case c < 0x20 || c == 0x7f:
+            b.WriteString(`\x`)
+            b.WriteString(strconv.FormatInt(int64(c)>>4, 16))
+            b.WriteString(strconv.FormatInt(int64(c)&0xf, 16))
+        default:
+            b.WriteByte(c)
+        }
+    }
+    return b.String()
+}
+
+// 记录响应状态码和字节数
+type statusWriter struct {
+    http.ResponseWriter
+    status int
+    bytes  int64
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+    if w.status == 0 {
+        w.status = code
+    }
+    w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(p []byte) (int, error) {
+    if w.status == 0 {
+        w.status = http.StatusOK
+    }
+    n, err := w.ResponseWriter.Write(p)
+    w.bytes += int64(n)
+    return n, err
+}
+
+// 转发Flush，包在中间件里的StreamHandler等流式响应仍然可用
+func (w *statusWriter) Flush() {
+    if f, ok := w.ResponseWriter.(http.Flusher); ok {
+        f.Flush()
+    }
+}
+
+func (w *statusWriter) code() int {
+    if w.status == 0 {
+        return http.StatusOK
+    }
+    return w.status
+}
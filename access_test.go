@@ -0,0 +1,53 @@
+package jLogger
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestAccessLogFile(t *testing.T) {
+    dir := t.TempDir()
+    l, err := NewLogger(dir, "app", 16, 10*time.Millisecond, "INFO", WithCRLF())
+    if err != nil {
+        t.Fatal(err)
+    }
+    h := l.AccessLog(CommonLog)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.Write([]byte("ok"))
+    }))
+    r := httptest.NewRequest("GET", "/health", nil)
+    r.RemoteAddr = "127.0.0.1:54321"
+    r.Header.Set("X-Forwarded-For", "203.0.113.7")
+    h.ServeHTTP(httptest.NewRecorder(), r)
+
+    key := filesKey(dir, "app")
+    openFiles_mu.Lock()
+    set := openFiles[key]
+    openFiles_mu.Unlock()
+    if set == nil || set.access == nil {
+        t.Fatal("访问日志文件没有登记在openFiles中")
+    }
+    l.Close()
+    openFiles_mu.Lock()
+    _, ok := openFiles[key]
+    openFiles_mu.Unlock()
+    if ok {
+        t.Fatal("Close之后文件组仍然登记在openFiles中")
+    }
+
+    data, err := os.ReadFile(filepath.Join(dir, "app_access.log"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    line := string(data)
+    if !strings.HasPrefix(line, "203.0.113.7 - - [") {
+        t.Fatalf("访问日志没有使用代理转发的客户端地址: %q", line)
+    }
+    if !strings.HasSuffix(line, "\r\n") {
+        t.Fatalf("访问日志没有按WithCRLF使用\\r\\n行尾: %q", line)
+    }
+}
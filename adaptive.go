@@ -0,0 +1,64 @@
+package jLogger
+
+import (
+    "sync/atomic"
+    "time"
+)
+
+// 连续多少次繁忙/空闲才调整一次间隔，避免偶发的突发流量造成抖动
+const adaptiveFlushTicks = 3
+
+// 定时刷新前判断缓冲区压力：任一缓冲区超过3/4容量，或者上个周期内发生过写满刷新，视为繁忙；三个缓冲区都为空视为空闲
+func (l *Logger) bufferPressure() (busy, idle bool) {
+    l.info_mu.Lock()
+    nInfo := len(l.bufferInfo)
+    l.info_mu.Unlock()
+    l.debug_mu.Lock()
+    nDebug := len(l.bufferDebug)
+    l.debug_mu.Unlock()
+    l.error_mu.Lock()
+    nError := len(l.bufferError)
+    l.error_mu.Unlock()
+
+    max := nInfo
+    if nDebug > max {
+        max = nDebug
+    }
+    if nError > max {
+        max = nError
+    }
+    full := atomic.SwapUint64(&l.fullFlushes, 0)
+    busy = full > 0 || max*4 >= l.bufferSize*3
+    idle = full == 0 && max == 0
+    return busy, idle
+}
+
+// 持续繁忙时间隔减半（不低于最小值），持续空闲时间隔加倍（不超过最大值），否则保持不变；结果总在[min, max]之间
+func (l *Logger) nextFlushInterval(cur time.Duration, busy, idle bool) time.Duration {
+    switch {
+    case busy:
+        l.busyTicks++
+        l.idleTicks = 0
+    case idle:
+        l.idleTicks++
+        l.busyTicks = 0
+    default:
+        l.busyTicks, l.idleTicks = 0, 0
+    }
+
+    if l.busyTicks >= adaptiveFlushTicks {
+        l.busyTicks = 0
+        cur /= 2
+    } else if l.idleTicks >= adaptiveFlushTicks {
+        l.idleTicks = 0
+        cur *= 2
+    }
+
+    if cur < l.minFlushInterval {
+        cur = l.minFlushInterval
+    }
+    if cur > l.maxFlushInterval {
+        cur = l.maxFlushInterval
+    }
+    return cur
+}
@@ -0,0 +1,26 @@
+package jLogger
+
+import (
+    "log"
+    "sync/atomic"
+)
+
+// AfterClosePolicy Close之后仍有日志写入时的处理方式。关闭过程中库代码、后台协程不一定能保证调用顺序，
+// 任何处理方式下日志方法和Flush在Close之后都可以安全调用，不会panic
+type AfterClosePolicy int
+
+const (
+    // AfterCloseWrite 在调用方协程中直接写入文件，不经过通道、缓冲区和Sink
+    AfterCloseWrite AfterClosePolicy = iota
+    // AfterCloseDrop 直接丢弃
+    AfterCloseDrop
+)
+
+// 通道关闭之后的记录，调用方持有send_mu的读锁
+func (l *Logger) writeAfterClose(logger *log.Logger, msg logMessage) {
+    atomic.AddUint64(&l.lateRecords, 1)
+    if l.afterClose == AfterCloseDrop {
+        return
+    }
+    logger.Println(l.encode(msg))
+}
@@ -0,0 +1,189 @@
+// bench 可复现的jLogger负载生成器：N个生产者按指定速率写入指定大小分布的消息，
+// 报告吞吐、丢弃条数和写入延迟，用来比较不同配置（bufferSize、flushInterval、各种Option）下管道的表现
+//
+//    res, err := bench.Run(bench.Config{Producers: 8, Rate: 10000, Duration: 10 * time.Second})
+//    fmt.Println(res)
+package bench
+
+import (
+    "fmt"
+    "math/rand"
+    "os"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/johnsonperl/jLogger"
+)
+
+// Size 消息大小分布中的一项：Weight越大，Bytes大小的消息出现得越多
+type Size struct {
+    Bytes  int
+    Weight int
+}
+
+// Config 一次压测的配置，零值的字段使用默认值
+type Config struct {
+    Producers     int           // 并发写日志的协程数，默认4
+    Rate          int           // 每个生产者每秒写入的条数，0表示不限速
+    Duration      time.Duration // 持续时间，默认5秒
+    Sizes         []Size        // 消息大小分布，默认全部为100字节
+    Level         string        // 写入的级别：INFO、DEBUG或ERROR，默认INFO
+    Seed          int64         // 随机种子，相同的种子和配置得到相同的消息序列
+    Dir           string        // 日志目录，为空时使用临时目录并在结束后删除
+    BufferSize    int           // 默认100
+    FlushInterval time.Duration // 默认1秒
+    Options       []jLogger.Option
+}
+
+// Result 压测结果
+type Result struct {
+    Sent       uint64        // 生产者调用日志方法的次数
+    Elapsed    time.Duration // 从开始写入到Close返回
+    Throughput float64       // 每秒写入的条数，按Elapsed计算
+    Bytes      uint64        // 消息内容的总字节数
+    Dropped    uint64        // 通道溢出、超出内存上限和降级丢弃的条数
+    P50        string        // 写入延迟，按直方图的桶上界估计
+    P99        string
+    Max        string
+}
+
+func (r Result) String() string {
+    return fmt.Sprintf("sent=%d elapsed=%s throughput=%.0f/s bytes=%d dropped=%d latency p50=%s p99=%s max=%s",
+        r.Sent, r.Elapsed.Truncate(time.Millisecond), r.Throughput, r.Bytes, r.Dropped, r.P50, r.P99, r.Max)
+}
+
+// Run 按cfg创建Logger并执行一次压测，结束时关闭Logger
+func Run(cfg Config) (Result, error) {
+    var res Result
+    cfg = withDefaults(cfg)
+    dir := cfg.Dir
+    if dir == "" {
+        tmp, err := os.MkdirTemp("", "jlogger-bench-")
+        if err != nil {
+            return res, err
+        }
+        defer os.RemoveAll(tmp)
+        dir = tmp
+    }
+    l, err := jLogger.NewLogger(dir, "bench", cfg.BufferSize, cfg.FlushInterval, cfg.Level, cfg.Options...)
+    if err != nil {
+        return res, err
+    }
+
+    payload := strings.Repeat("0123456789abcdefghijklmnopqrstuvwxyz", maxSize(cfg.Sizes)/36+1)
+    var (
+        wg    sync.WaitGroup
+        mu    sync.Mutex
+        start = time.Now()
+        stop  = start.Add(cfg.Duration)
+    )
+    for p := 0; p < cfg.Producers; p++ {
+        wg.Add(1)
+        go func(p int) {
+            defer wg.Done()
+            rng := rand.New(rand.NewSource(cfg.Seed + int64(p)))
+            var sent, bytes uint64
+            for i := 0; ; i++ {
+                now := time.Now()
+                if now.After(stop) {
+                    break
+                }
+                if cfg.Rate > 0 {
+                    // 按第i条应该发出的时间限速，落后时不补发
+                    next := start.Add(time.Duration(i) * time.Second / time.Duration(cfg.Rate))
+                    if d := next.Sub(now); d > 0 {
+                        time.Sleep(d)
+                    }
+                }
+                msg := payload[:pickSize(rng, cfg.Sizes)]
+                switch cfg.Level {
+                case "DEBUG":
+                    l.Debug(msg)
+                case "ERROR":
+                    l.Error(msg)
+                default:
+                    l.Info(msg)
+                }
+                sent++
+                bytes += uint64(len(msg))
+            }
+            mu.Lock()
+            res.Sent += sent
+            res.Bytes += bytes
+            mu.Unlock()
+        }(p)
+    }
+    wg.Wait()
+    l.Close()
+    res.Elapsed = time.Since(start)
+    res.Throughput = float64(res.Sent) / res.Elapsed.Seconds()
+
+    st := l.Stats()
+    for _, n := range st.Dropped {
+        res.Dropped += n
+    }
+    res.Dropped += st.DegradedOut
+    lat := st.WriteLatency[cfg.Level]
+    res.P50, res.P99, res.Max = lat.P50, lat.P99, lat.Max
+    return res, nil
+}
+
+func withDefaults(cfg Config) Config {
+    if cfg.Producers <= 0 {
+        cfg.Producers = 4
+    }
+    if cfg.Duration <= 0 {
+        cfg.Duration = 5 * time.Second
+    }
+    if len(cfg.Sizes) == 0 {
+        cfg.Sizes = []Size{{Bytes: 100, Weight: 1}}
+    }
+    switch strings.ToUpper(cfg.Level) {
+    case "DEBUG", "ERROR":
+        cfg.Level = strings.ToUpper(cfg.Level)
+    default:
+        cfg.Level = "INFO"
+    }
+    if cfg.BufferSize <= 0 {
+        cfg.BufferSize = 100
+    }
+    if cfg.FlushInterval <= 0 {
+        cfg.FlushInterval = time.Second
+    }
+    return cfg
+}
+
+func maxSize(sizes []Size) int {
+    max := 0
+    for _, s := range sizes {
+        if s.Bytes > max {
+            max = s.Bytes
+        }
+    }
+    return max
+}
+
+// 按权重随机选择一个大小
+func pickSize(rng *rand.Rand, sizes []Size) int {
+    total := 0
+    for _, s := range sizes {
+        if s.Weight > 0 {
+            total += s.Weight
+        }
+    }
+    if total == 0 {
+        return sizes[0].Bytes
+    }
+    n := rng.Intn(total)
+    for _, s := range sizes {
+        if s.Weight <= 0 {
+            continue
+        }
+        if n < s.Weight {
+            return s.Bytes
+        }
+        n -= s.Weight
+    }
+    return sizes[len(sizes)-1].Bytes
+}
@@ -0,0 +1,134 @@
+package jLogger
+
+import (
+    "hash/fnv"
+    "math"
+    "strings"
+    "unicode"
+)
+
+// 布隆过滤器的目标误判率
+const bloomFalsePositive = 0.01
+
+// Bloom 归档文件中出现过的词的布隆过滤器：MayContain返回false时文件中一定没有该词，搜索时可以直接跳过
+type Bloom struct {
+    Bits []uint64 `json:"bits"`
+    K    int      `json:"k"`
+}
+
+func newBloom(n int) *Bloom {
+    if n < 1 {
+        n = 1
+    }
+    m := int(math.Ceil(-float64(n) * math.Log(bloomFalsePositive) / (math.Ln2 * math.Ln2)))
+    k := int(math.Round(float64(m) / float64(n) * math.Ln2))
+    if k < 1 {
+        k = 1
+    }
+    return &Bloom{Bits: make([]uint64, (m+63)/64), K: k}
+}
+
+func bloomHashes(token string) (uint64, uint64) {
+    h := fnv.New64a()
+    h.Write([]byte(token))
+    h1 := h.Sum64()
+    h2 := h1>>33 | h1<<31
+    return h1, h2 | 1
+}
+
+func (b *Bloom) add(token string) {
+    m := uint64(len(b.Bits) * 64)
+    h1, h2 := bloomHashes(token)
+    for i := 0; i < b.K; i++ {
+        bit := (h1 + uint64(i)*h2) % m
+        b.Bits[bit/64] |= 1 << (bit % 64)
+    }
+}
+
+func (b *Bloom) has(token string) bool {
+    m := uint64(len(b.Bits) * 64)
+    if m == 0 {
+        return true
+    }
+    h1, h2 := bloomHashes(token)
+    for i := 0; i < b.K; i++ {
+        bit := (h1 + uint64(i)*h2) % m
+        if b.Bits[bit/64]&(1<<(bit%64)) == 0 {
+            return false
+        }
+    }
+    return true
+}
+
+// MayContain term可能作为子串出现在文件中时返回true。term首尾的字母数字可能只是更长单词的一部分，
+// 不是完整的词，只检查两侧都有分隔的完整单词和中日韩文字的token，否则会把含有该子串的文件误判跳过
+func (b *Bloom) MayContain(term string) bool {
+    if b == nil {
+        return true
+    }
+    for _, token := range tokenize(trimPartialWords(term)) {
+        if !b.has(token) {
+            return false
+        }
+    }
+    return true
+}
+
+// 去掉term开头和结尾连续的字母数字（中日韩文字除外），剩下部分中的单词两侧都有分隔
+func trimPartialWords(term string) string {
+    runes := []rune(term)
+    start, end := 0, len(runes)
+    for start < end && isWordRune(runes[start]) {
+        start++
+    }
+    for end > start && isWordRune(runes[end-1]) {
+        end--
+    }
+    return string(runes[start:end])
+}
+
+func isWordRune(r rune) bool {
+    return !isCJK(r) && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_')
+}
+
+func isCJK(r rune) bool {
+    return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r)
+}
+
+// 分词：字母数字连续的部分转小写作为一个词；中日韩文字没有空格分隔，单字和相邻两字都作为词，子串查询也能命中
+func tokenize(s string) []string {
+    var tokens []string
+    var word []rune
+    var cjk []rune
+    flushWord := func() {
+        if len(word) > 0 {
+            tokens = append(tokens, strings.ToLower(string(word)))
+            word = word[:0]
+        }
+    }
+    flushCJK := func() {
+        for i := range cjk {
+            tokens = append(tokens, string(cjk[i]))
+            if i+1 < len(cjk) {
+                tokens = append(tokens, string(cjk[i:i+2]))
+            }
+        }
+        cjk = cjk[:0]
+    }
+    for _, r := range s {
+        switch {
+        case isCJK(r):
+            flushWord()
+            cjk = append(cjk, r)
+        case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+            flushCJK()
+            word = append(word, r)
+        default:
+            flushWord()
+            flushCJK()
+        }
+    }
+    flushWord()
+    flushCJK()
+    return tokens
+}
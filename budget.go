@@ -0,0 +1,123 @@
+package jLogger
+
+import (
+    "sync"
+    "time"
+)
+
+// 统计窗口划分的桶数，告警的时间精度为 Window/budgetBuckets
+const budgetBuckets = 60
+
+// ErrorBudget Error条数的预算告警：窗口内的Error条数超过Limit时调用OnExceeded，
+// 回落到 Limit*RecoverRatio 及以下时调用OnRecovered，两个阈值之间不会反复触发。
+// 库自己写的心跳、START/STOP标记、降级和自检记录不计入
+type ErrorBudget struct {
+    Limit        int           // 窗口内允许的Error条数
+    Window       time.Duration // 统计窗口，默认1小时
+    RecoverRatio float64       // 恢复阈值占Limit的比例，默认0.8
+    OnExceeded   func(count int)
+    OnRecovered  func(count int)
+}
+
+// ErrorBudgetState 预算告警的当前状态，见Stats
+type ErrorBudgetState struct {
+    Limit    int       `json:"limit"`
+    Window   string    `json:"window"`
+    Count    int       `json:"count"`    // 当前窗口内的Error条数
+    Exceeded bool      `json:"exceeded"` // 是否处于超预算状态
+    Since    time.Time `json:"since"`    // 进入当前状态的时间
+}
+
+type errorBudget struct {
+    ErrorBudget
+    mu       sync.Mutex
+    bucket   time.Duration
+    counts   [budgetBuckets]int
+    epochs   [budgetBuckets]int64 // 每个桶对应的时间片，过期的桶在使用前清零
+    exceeded bool
+    since    time.Time
+}
+
+func newErrorBudget(b ErrorBudget) *errorBudget {
+    if b.Window <= 0 {
+        b.Window = time.Hour
+    }
+    if b.RecoverRatio <= 0 || b.RecoverRatio > 1 {
+        b.RecoverRatio = 0.8
+    }
+    bucket := b.Window / budgetBuckets
+    if bucket <= 0 {
+        bucket = 1
+    }
+    return &errorBudget{ErrorBudget: b, bucket: bucket, since: time.Now()}
+}
+
+// 记录一条Error，在生产者协程中调用，只计数不触发回调
+func (b *errorBudget) add(t time.Time) {
+    epoch := t.UnixNano() / int64(b.bucket)
+    i := epoch % budgetBuckets
+    b.mu.Lock()
+    if b.epochs[i] != epoch {
+        b.epochs[i] = epoch
+        b.counts[i] = 0
+    }
+    b.counts[i]++
+    b.mu.Unlock()
+}
+
+// 窗口内的Error条数，调用方持有锁
+func (b *errorBudget) count(now time.Time) int {
+    current := now.UnixNano() / int64(b.bucket)
+    n := 0
+    for i := range b.counts {
+        if current-b.epochs[i] < budgetBuckets {
+            n += b.counts[i]
+        }
+    }
+    return n
+}
+
+// 检查是否越过阈值，返回需要调用的回调
+func (b *errorBudget) check(now time.Time) (func(int), int) {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    n := b.count(now)
+    switch {
+    case !b.exceeded && n > b.Limit:
+        b.exceeded, b.since = true, now
+        return b.OnExceeded, n
+    case b.exceeded && float64(n) <= float64(b.Limit)*b.RecoverRatio:
+        b.exceeded, b.since = false, now
+        return b.OnRecovered, n
+    }
+    return nil, n
+}
+
+func (b *errorBudget) state() *ErrorBudgetState {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    return &ErrorBudgetState{
+        Limit:    b.Limit,
+        Window:   b.Window.String(),
+        Count:    b.count(time.Now()),
+        Exceeded: b.exceeded,
+        Since:    b.since,
+    }
+}
+
+// 每个桶的时间检查一次，回调在这里调用，回调中可以正常写日志
+func (l *Logger) runErrorBudget() {
+    defer l.emitters.Done()
+    ticker := time.NewTicker(l.budget.bucket)
+    defer ticker.Stop()
+    for {
+        select {
+        case <-l.done:
+            return
+        case now := <-ticker.C:
+            if fn, n := l.budget.check(now); fn != nil {
+                fn(n)
+            }
+        }
+    }
+}
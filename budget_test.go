@@ -0,0 +1,57 @@
+package jLogger
+
+import (
+    "sync"
+    "testing"
+    "time"
+)
+
+func TestInternalRecordsSkipBudgetAndSinks(t *testing.T) {
+    l, err := NewLogger(t.TempDir(), "app", 16, 5*time.Millisecond, "INFO",
+        WithErrorBudget(ErrorBudget{Limit: 100}), WithHeartbeat(5*time.Millisecond))
+    if err != nil {
+        t.Fatal(err)
+    }
+    var mu sync.Mutex
+    var delivered []Record
+    l.AddSink("cb", NewCallbackSink(func(batch []Record) error {
+        mu.Lock()
+        delivered = append(delivered, batch...)
+        mu.Unlock()
+        return nil
+    }))
+    ch, cancel := l.Subscribe(1000, nil)
+    defer cancel()
+
+    l.Error("boom")
+    time.Sleep(50 * time.Millisecond)
+    l.Flush()
+    if got := l.Stats().ErrorBudget.Count; got != 1 {
+        t.Fatalf("预算计数为%d, 期望只计入用户的1条Error", got)
+    }
+    l.Close()
+
+    mu.Lock()
+    defer mu.Unlock()
+    for _, r := range delivered {
+        if r.Message != "boom" {
+            t.Fatalf("Sink收到了内部记录: %+v", r)
+        }
+    }
+    if len(delivered) != 1 {
+        t.Fatalf("Sink收到%d条记录, 期望1条", len(delivered))
+    }
+    for {
+        select {
+        case r, ok := <-ch:
+            if !ok {
+                return
+            }
+            if r.Message != "boom" {
+                t.Fatalf("订阅者收到了内部记录: %+v", r)
+            }
+        default:
+            return
+        }
+    }
+}
@@ -0,0 +1,47 @@
+package jLogger
+
+import (
+    "fmt"
+    "runtime/debug"
+)
+
+// CallbackSink 把每批记录交给回调函数的Sink，不需要自己实现Sink接口就能把日志接到私有的采集代理、测试桩等目标。
+// 回调在该Sink的投递协程中调用，和日志管道及其他Sink隔离；积压的记录数有上限，超过时丢弃最旧的批次。
+// 回调返回错误时按Sink的规则重试；回调panic时这一批不再重试，见ErrSinkPermanent
+type CallbackSink struct {
+    fn      func(batch []Record) error
+    onClose func()
+}
+
+// NewCallbackSink 创建CallbackSink，挂载：l.AddSink("agent", jLogger.NewCallbackSink(fn))。batch不能修改，需要保留时自行复制
+func NewCallbackSink(fn func(batch []Record) error) *CallbackSink {
+    return &CallbackSink{fn: fn}
+}
+
+// OnClose 设置Sink关闭时调用的函数，例如关闭回调使用的连接，返回s本身
+func (s *CallbackSink) OnClose(fn func()) *CallbackSink {
+    s.onClose = fn
+    return s
+}
+
+func (s *CallbackSink) WriteBatch(batch []Record) (err error) {
+    defer func() {
+        if r := recover(); r != nil {
+            err = fmt.Errorf("%w: 回调panic: %v\n%s", ErrSinkPermanent, r, debug.Stack())
+        }
+    }()
+    return s.fn(batch)
+}
+
+func (s *CallbackSink) Close() (err error) {
+    if s.onClose == nil {
+        return nil
+    }
+    defer func() {
+        if r := recover(); r != nil {
+            err = fmt.Errorf("关闭回调panic: %v", r)
+        }
+    }()
+    s.onClose()
+    return nil
+}
@@ -0,0 +1,11 @@
+package jLogger
+
+import "time"
+
+// 记录的事件时间：调用日志方法时从时钟取得，默认time.Now，可以通过WithClock替换
+func (l *Logger) now() time.Time {
+    if clock := l.pipeline().clock; clock != nil {
+        return clock()
+    }
+    return time.Now()
+}
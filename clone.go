@@ -0,0 +1,41 @@
+package jLogger
+
+// CloneWith 基于当前Logger创建一个新的Logger句柄，与原Logger共享通道、缓冲区、日志文件和处理协程，
+// 只覆盖句柄级别的配置（WithLevel、WithPrefix），模块名、标签和字段沿用原句柄，适合按请求、按任务创建Logger而不必付出NewLogger的开销。
+// 没有用WithLevel设置级别的克隆跟随根Logger的级别，根Logger的SetLevel和远程配置的级别对它同样生效；
+// 从设置过级别的克隆再克隆时沿用该级别。
+// 输出格式、spool等管道级别的选项在克隆上不生效；克隆的Close不做任何事，管道由根Logger关闭
+func (l *Logger) CloneWith(opts ...Option) *Logger {
+    root := l.pipeline()
+    // 空表示跟随根Logger
+    level := ""
+    if l.shared != nil {
+        root.levels_mu.RLock()
+        level = l.log_level
+        root.levels_mu.RUnlock()
+    }
+
+    clone := &Logger{
+        InfoLogger:  l.InfoLogger,
+        DebugLogger: l.DebugLogger,
+        ErrorLogger: l.ErrorLogger,
+        log_level:   level,
+        prefix:      l.prefix,
+        module:      l.module,
+        tag:         l.tag,
+        fields:      l.fields,
+        shared:      root,
+    }
+    for _, opt := range opts {
+        opt(clone)
+    }
+    return clone
+}
+
+// 返回真正持有管道的Logger
+func (l *Logger) pipeline() *Logger {
+    if l.shared != nil {
+        return l.shared
+    }
+    return l
+}
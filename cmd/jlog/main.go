@@ -0,0 +1,286 @@
+// jlog jLogger日志文件的命令行工具
+//
+//    jlog purge -dir ./logs -prefix app -field user_id -value 123 [-redact]
+//    jlog search -dir ./logs -prefix app -term timeout
+//    jlog query -dir ./logs -prefix app -level ERROR -from "2024-01-02 15:00:00" -field user_id=123
+//    jlog validate -config jlogger.json
+//    jlog convert -dir ./logs -prefix app [-out ./logs-json]
+//    jlog selftest -dir ./logs -prefix app
+package main
+
+import (
+    "encoding/json"
+    "flag"
+    "fmt"
+    "os"
+    "strings"
+    "time"
+
+    "github.com/johnsonperl/jLogger"
+)
+
+func usage() {
+    fmt.Fprintln(os.Stderr, `用法: jlog <命令> [参数]
+
+命令:
+  purge    删除或脱敏指定字段值的日志行（含轮转归档）
+  search   在日志和归档中查找包含关键字的行
+  query    按时间、级别、关键字和字段查询历史记录，输出JSON
+  validate 检查配置（目录权限、级别、Sink连通性），不写任何日志
+  convert  把文本格式的日志和归档改写为JSON格式
+  selftest 写入标记记录并从文件读回，检查日志目录可用`)
+    os.Exit(2)
+}
+
+func main() {
+    if len(os.Args) < 2 {
+        usage()
+    }
+    var err error
+    switch os.Args[1] {
+    case "purge":
+        err = runPurge(os.Args[2:])
+    case "search":
+        err = runSearch(os.Args[2:])
+    case "query":
+        err = runQuery(os.Args[2:])
+    case "validate":
+        err = runValidate(os.Args[2:])
+    case "convert":
+        err = runConvert(os.Args[2:])
+    case "selftest":
+        err = runSelfTest(os.Args[2:])
+    default:
+        usage()
+    }
+    if err != nil {
+        fmt.Fprintln(os.Stderr, "jlog:", err)
+        os.Exit(1)
+    }
+}
+
+func runPurge(args []string) error {
+    fs := flag.NewFlagSet("purge", flag.ExitOnError)
+    dir := fs.String("dir", ".", "日志目录")
+    prefix := fs.String("prefix", "", "日志文件前缀")
+    field := fs.String("field", "", "字段名，例如 user_id")
+    value := fs.String("value", "", "字段值")
+    redact := fs.Bool("redact", false, "只脱敏字段值，保留日志行")
+    fs.Parse(args)
+    if *prefix == "" || *field == "" {
+        fs.Usage()
+        os.Exit(2)
+    }
+
+    res, err := jLogger.PurgeFiles(*dir, *prefix, jLogger.PurgeOptions{Field: *field, Value: *value, Redact: *redact})
+    if err != nil {
+        return err
+    }
+    fmt.Printf("扫描 %d 个文件，改写 %d 个，处理 %d 行\n", res.Files, res.Changed, res.Lines)
+    return nil
+}
+
+
+func runSearch(args []string) error {
+    fs := flag.NewFlagSet("search", flag.ExitOnError)
+    dir := fs.String("dir", ".", "日志目录")
+    prefix := fs.String("prefix", "", "日志文件前缀")
+    term := fs.String("term", "", "关键字")
+    showFile := fs.Bool("H", false, "输出文件名")
+    fs.Parse(args)
+    if *prefix == "" || *term == "" {
+        fs.Usage()
+        os.Exit(2)
+    }
+
+    return jLogger.Search(*dir, *prefix, *term, func(file, line string) bool {
+        if *showFile {
+            fmt.Printf("%s:%s\n", file, line)
+        } else {
+            fmt.Println(line)
+        }
+        return true
+    })
+}
+
+// 可重复的 -field key=value 参数
+type fieldFlags map[string]string
+
+func (f fieldFlags) String() string {
+    return fmt.Sprint(map[string]string(f))
+}
+
+func (f fieldFlags) Set(s string) error {
+    key, value, ok := strings.Cut(s, "=")
+    if !ok {
+        return fmt.Errorf("必须是 key=value 的形式: %s", s)
+    }
+    f[key] = value
+    return nil
+}
+
+func runQuery(args []string) error {
+    fs := flag.NewFlagSet("query", flag.ExitOnError)
+    dir := fs.String("dir", ".", "日志目录")
+    prefix := fs.String("prefix", "", "日志文件前缀")
+    from := fs.String("from", "", "开始时间，如 2024-01-02 15:04:05")
+    to := fs.String("to", "", "结束时间")
+    level := fs.String("level", "", "最低级别")
+    contains := fs.String("contains", "", "包含的关键字")
+    fields := fieldFlags{}
+    fs.Var(fields, "field", "字段条件 key=value，可重复")
+    fs.Parse(args)
+    if *prefix == "" {
+        fs.Usage()
+        os.Exit(2)
+    }
+
+    opts := jLogger.QueryOptions{Level: *level, Contains: *contains, Fields: fields}
+    var err error
+    if opts.From, err = jLogger.ParseQueryTime(*from); err != nil {
+        return err
+    }
+    if opts.To, err = jLogger.ParseQueryTime(*to); err != nil {
+        return err
+    }
+
+    it := jLogger.QueryFiles(*dir, *prefix, opts)
+    defer it.Close()
+    enc := json.NewEncoder(os.Stdout)
+    for it.Next() {
+        if err := enc.Encode(it.Record()); err != nil {
+            return err
+        }
+    }
+    return it.Err()
+}
+// 可重复的字符串参数
+type stringFlags []string
+
+func (f *stringFlags) String() string {
+    return strings.Join(*f, ",")
+}
+
+func (f *stringFlags) Set(s string) error {
+    *f = append(*f, s)
+    return nil
+}
+
+func runValidate(args []string) error {
+    fs := flag.NewFlagSet("validate", flag.ExitOnError)
+    config := fs.String("config", "", "JSON格式的配置文件，其余参数覆盖其中的同名项")
+    dir := fs.String("dir", "", "日志目录")
+    prefix := fs.String("prefix", "", "日志文件前缀")
+    buffer := fs.Int("buffer", 0, "缓冲区大小")
+    flush := fs.String("flush", "", "刷新间隔，如 5s")
+    level := fs.String("level", "", "日志级别")
+    modules := fs.String("modules", "", "模块级别，格式同JLOGGER_LEVELS")
+    var sinks stringFlags
+    fs.Var(&sinks, "sink", "Sink地址，可重复")
+    fs.Parse(args)
+
+    var cfg jLogger.Config
+    if *config != "" {
+        data, err := os.ReadFile(*config)
+        if err != nil {
+            return err
+        }
+        if err := json.Unmarshal(data, &cfg); err != nil {
+            return fmt.Errorf("%s: %v", *config, err)
+        }
+    }
+    if *dir != "" {
+        cfg.Dir = *dir
+    }
+    if *prefix != "" {
+        cfg.Prefix = *prefix
+    }
+    if *buffer != 0 {
+        cfg.BufferSize = *buffer
+    }
+    if *flush != "" {
+        cfg.FlushInterval = *flush
+    }
+    if *level != "" {
+        cfg.Level = *level
+    }
+    if *modules != "" {
+        // 不在这里校验级别，交给ValidateConfig和其他问题一起报告
+        cfg.Modules = make(map[string]string)
+        for _, item := range strings.Split(*modules, ",") {
+            module, level, _ := strings.Cut(strings.TrimSpace(item), "=")
+            if module != "" {
+                cfg.Modules[module] = level
+            }
+        }
+    }
+    cfg.Sinks = append(cfg.Sinks, sinks...)
+
+    if err := jLogger.ValidateConfig(cfg); err != nil {
+        for _, line := range strings.Split(err.Error(), "\n") {
+            fmt.Fprintln(os.Stderr, "✗", line)
+        }
+        os.Exit(1)
+    }
+    fmt.Println("配置有效")
+    return nil
+}
+
+func runConvert(args []string) error {
+    fs := flag.NewFlagSet("convert", flag.ExitOnError)
+    dir := fs.String("dir", ".", "日志目录")
+    prefix := fs.String("prefix", "", "日志文件前缀")
+    out := fs.String("out", "", "输出目录，默认原地替换")
+    fs.Parse(args)
+    if *prefix == "" {
+        fs.Usage()
+        os.Exit(2)
+    }
+
+    res, err := jLogger.ConvertFiles(*dir, *prefix, jLogger.ConvertOptions{OutDir: *out})
+    if err != nil {
+        return err
+    }
+    fmt.Printf("扫描 %d 个文件，转换 %d 个，共 %d 条记录（合并续行 %d 行）\n", res.Files, res.Converted, res.Records, res.Merged)
+    return nil
+}
+
+func runSelfTest(args []string) error {
+    fs := flag.NewFlagSet("selftest", flag.ExitOnError)
+    dir := fs.String("dir", ".", "日志目录")
+    prefix := fs.String("prefix", "", "日志文件前缀")
+    jsonFormat := fs.Bool("json", false, "使用JSON格式")
+    multi := fs.Bool("multiprocess", false, "应用正在写同一组文件时加文件锁，见WithMultiProcess")
+    fs.Parse(args)
+    if *prefix == "" {
+        fs.Usage()
+        os.Exit(2)
+    }
+
+    var opts []jLogger.Option
+    if *jsonFormat {
+        opts = append(opts, jLogger.WithJSON())
+    }
+    if *multi {
+        opts = append(opts, jLogger.WithMultiProcess())
+    }
+    l, err := jLogger.NewLogger(*dir, *prefix, 10, time.Second, "DEBUG", opts...)
+    if err != nil {
+        return err
+    }
+    defer l.Close()
+    res, err := l.SelfTest()
+    for _, level := range []string{"INFO", "DEBUG", "ERROR"} {
+        if d, ok := res.Latency[level]; ok {
+            fmt.Printf("%-5s ok %v\n", level, d)
+        }
+    }
+    for _, level := range res.Skipped {
+        fmt.Printf("%-5s 跳过\n", level)
+    }
+    if err != nil {
+        return err
+    }
+    fmt.Println("自检通过，标记:", res.Token)
+    return nil
+}
@@ -0,0 +1,259 @@
+package jLogger
+
+import (
+    "bufio"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "log"
+    "net"
+    "os"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+)
+
+// 多进程日志的汇聚：拥有日志文件的父进程（collector）监听Unix domain socket，
+// 子进程用DialCollector连接后把记录以JSON Lines发过去，由父进程统一写入、轮转和投递Sink。
+// 适用于pre-fork和插件架构，子进程不打开任何日志文件
+
+// ServeCollector 在path上监听Unix domain socket，接收子进程的记录写入当前Logger。
+// path上残留的socket文件会被删除。级别过滤在子进程中完成，收到的记录全部写入。
+// stop关闭监听和所有连接
+func (l *Logger) ServeCollector(path string) (stop func() error, err error) {
+    if fi, err := os.Lstat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+        os.Remove(path)
+    }
+    ln, err := net.Listen("unix", path)
+    if err != nil {
+        return nil, err
+    }
+    return l.ServeCollectorListener(ln), nil
+}
+
+// ServeCollectorListener 同ServeCollector，使用已有的监听，例如ActivationListener返回的systemd socket
+func (l *Logger) ServeCollectorListener(ln net.Listener) (stop func() error) {
+    l = l.pipeline()
+    var (
+        wg    sync.WaitGroup
+        mu    sync.Mutex
+        conns = make(map[net.Conn]struct{})
+    )
+    wg.Add(1)
+    go func() {
+        defer wg.Done()
+        for {
+            conn, err := ln.Accept()
+            if err != nil {
+                if !errors.Is(err, net.ErrClosed) {
+                    l.internalError("collector接受连接失败:", err)
+                }
+                return
+            }
+            mu.Lock()
+            conns[conn] = struct{}{}
+            mu.Unlock()
+            wg.Add(1)
+            go func() {
+                defer wg.Done()
+                l.serveCollectorConn(conn)
+                mu.Lock()
+                delete(conns, conn)
+                mu.Unlock()
+            }()
+        }
+    }()
+
+    var once sync.Once
+    return func() error {
+        var err error
+        once.Do(func() {
+            err = ln.Close()
+            mu.Lock()
+            for conn := range conns {
+                conn.Close()
+            }
+            mu.Unlock()
+            wg.Wait()
+        })
+        return err
+    }
+}
+
+func (l *Logger) serveCollectorConn(conn net.Conn) {
+    defer conn.Close()
+    dec := json.NewDecoder(bufio.NewReader(conn))
+    for {
+        var r Record
+        if err := dec.Decode(&r); err != nil {
+            if !errors.Is(err, net.ErrClosed) && !errors.Is(err, io.EOF) {
+                l.internalError("collector读取记录失败:", err)
+            }
+            return
+        }
+        l.ingest(r)
+    }
+}
+
+// 把子进程的记录放入管道，保留原来的事件时间、模块和标签
+func (l *Logger) ingest(r Record) {
+    level, ok := normalizeLevel(r.Level)
+    if !ok {
+        level = "INFO"
+    }
+    t := r.Time
+    if t.IsZero() {
+        t = l.now()
+    }
+    msg := l.newMessage(level, t, []interface{}{r.Message}, r.Fields...)
+    msg.module, msg.tag = r.Module, r.Tag
+    if r.LogID != "" {
+        // 保留客户端进程生成的ID，同一条记录在各处的ID一致
+        msg.id = r.LogID
+    }
+    logger, _, _ := l.levelOutput(level)
+    l.enqueue(logger, msg)
+}
+
+// ActivationListener 返回systemd socket activation传入的第一个监听（LISTEN_FDS），
+// 没有以socket activation方式启动时返回错误
+func ActivationListener() (net.Listener, error) {
+    if os.Getenv("LISTEN_PID") != strconv.Itoa(os.Getpid()) {
+        return nil, errors.New("没有通过socket activation启动")
+    }
+    n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+    if err != nil || n < 1 {
+        return nil, errors.New("LISTEN_FDS无效")
+    }
+    // 传入的fd从3开始
+    f := os.NewFile(3, "LISTEN_FD_3")
+    defer f.Close()
+    return net.FileListener(f)
+}
+
+// CollectorClient 子进程一侧的Logger，实现Interface，记录通过Unix domain socket发给collector。
+// 每条记录单独写出，子进程崩溃不会丢失已经写过的日志；连接断开时重连一次，仍然失败则写到标准错误
+type CollectorClient struct {
+    path   string
+    tag    string
+    mu     sync.Mutex
+    conn   net.Conn
+    enc    *json.Encoder
+    level  string
+    closed bool
+}
+
+// DialCollector 连接path上的collector。tag作为每条记录的标签，用来区分子进程，为空时使用pid
+func DialCollector(path, tag string) (*CollectorClient, error) {
+    if tag == "" {
+        tag = strconv.Itoa(os.Getpid())
+    }
+    c := &CollectorClient{path: path, tag: tag, level: "DEBUG"}
+    if err := c.dial(); err != nil {
+        return nil, err
+    }
+    return c, nil
+}
+
+var _ Interface = (*CollectorClient)(nil)
+
+func (c *CollectorClient) dial() error {
+    conn, err := net.DialTimeout("unix", c.path, defaultSinkTimeout)
+    if err != nil {
+        return err
+    }
+    c.conn = conn
+    c.enc = json.NewEncoder(conn)
+    c.enc.SetEscapeHTML(false)
+    return nil
+}
+
+// filter为false时不按级别过滤，用于指标记录
+func (c *CollectorClient) send(level string, v []interface{}, fields []Field, filter bool) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if c.closed || (filter && !levelEnabled(c.level, level)) {
+        return
+    }
+    args, inline := splitFields(v)
+    r := Record{
+        Time:    time.Now(),
+        Level:   level,
+        Tag:     c.tag,
+        Message: strings.TrimSpace(fmt.Sprintln(args...)),
+        Fields:  append(inline, fields...),
+    }
+    for attempt := 0; attempt < 2; attempt++ {
+        if c.conn == nil {
+            if err := c.dial(); err != nil {
+                break
+            }
+        }
+        if err := c.enc.Encode(r); err == nil {
+            return
+        }
+        c.conn.Close()
+        c.conn = nil
+    }
+    log.New(os.Stderr, level+": ", 0).Println(r.Time.Format(timeFormat), r.Message)
+}
+
+// 同Logger.enabled，按给定的当前级别判断
+func levelEnabled(current, level string) bool {
+    switch level {
+    case "DEBUG":
+        return current == "DEBUG"
+    case "INFO":
+        return current == "INFO" || current == "DEBUG"
+    }
+    return true
+}
+
+func (c *CollectorClient) Info(v ...interface{})  { c.send("INFO", v, nil, true) }
+func (c *CollectorClient) Debug(v ...interface{}) { c.send("DEBUG", v, nil, true) }
+func (c *CollectorClient) Error(v ...interface{}) { c.send("ERROR", v, nil, true) }
+
+func (c *CollectorClient) InfoFields(msg string, fields ...Field) {
+    c.send("INFO", []interface{}{msg}, fields, true)
+}
+
+func (c *CollectorClient) DebugFields(msg string, fields ...Field) {
+    c.send("DEBUG", []interface{}{msg}, fields, true)
+}
+
+func (c *CollectorClient) ErrorFields(msg string, fields ...Field) {
+    c.send("ERROR", []interface{}{msg}, fields, true)
+}
+
+// Count 同Logger.Count，指标记录不受级别过滤
+func (c *CollectorClient) Count(name string, delta int64, fields ...Field) {
+    c.send("INFO", []interface{}{"metric"}, append([]Field{String("name", name), String("type", "counter"), Int64("value", delta)}, fields...), false)
+}
+
+func (c *CollectorClient) Gauge(name string, value float64, fields ...Field) {
+    c.send("INFO", []interface{}{"metric"}, append([]Field{String("name", name), String("type", "gauge"), Float64("value", value)}, fields...), false)
+}
+
+// SetLevel 设置子进程的级别，默认DEBUG
+func (c *CollectorClient) SetLevel(level string) {
+    if name, ok := normalizeLevel(level); ok {
+        c.mu.Lock()
+        c.level = name
+        c.mu.Unlock()
+    }
+}
+
+// Flush 每条记录都已经直接写出，不需要刷新
+func (c *CollectorClient) Flush() {}
+
+func (c *CollectorClient) Close() {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.closed = true
+    if c.conn != nil {
+        c.conn.Close()
+        c.conn = nil
+    }
+}
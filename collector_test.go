@@ -0,0 +1,82 @@
+package jLogger
+
+import (
+    "fmt"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+)
+
+func TestCollectorConcurrentClients(t *testing.T) {
+    // Unix socket路径长度有限，不使用t.TempDir()的长路径
+    sockDir, err := os.MkdirTemp("", "jlc")
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer os.RemoveAll(sockDir)
+    path := filepath.Join(sockDir, "c.sock")
+
+    l := newTestLogger(t, "INFO")
+    const clients, n = 6, 500
+    ch, cancel := l.Subscribe(clients*n+100, func(r Record) bool { return strings.HasPrefix(r.Message, "rec ") })
+    defer cancel()
+    stop, err := l.ServeCollector(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer stop()
+
+    var wg sync.WaitGroup
+    for c := 0; c < clients; c++ {
+        wg.Add(1)
+        go func(c int) {
+            defer wg.Done()
+            client, err := DialCollector(path, fmt.Sprint("c", c))
+            if err != nil {
+                t.Error(err)
+                return
+            }
+            defer client.Close()
+            client.SetLevel("INFO")
+            for i := 0; i < n; i++ {
+                client.Debug("filtered", i)
+                client.Info("rec", i)
+            }
+        }(c)
+    }
+    wg.Wait()
+
+    next := make(map[string]int, clients)
+    timeout := time.After(5 * time.Second)
+    for got := 0; got < clients*n; got++ {
+        var r Record
+        select {
+        case r = <-ch:
+        case <-timeout:
+            t.Fatalf("收到%d条记录, 期望%d条", got, clients*n)
+        }
+        i, err := strconv.Atoi(strings.TrimPrefix(r.Message, "rec "))
+        if err != nil {
+            t.Fatalf("无法解析记录 %q", r.Message)
+        }
+        if i != next[r.Tag] {
+            t.Fatalf("客户端%s的记录乱序: 收到%d, 期望%d", r.Tag, i, next[r.Tag])
+        }
+        next[r.Tag]++
+    }
+    if len(next) != clients {
+        t.Fatalf("收到%d个客户端的记录, 期望%d个", len(next), clients)
+    }
+    if err := stop(); err != nil {
+        t.Fatal(err)
+    }
+    select {
+    case r := <-ch:
+        t.Fatalf("多出的记录: %+v", r)
+    default:
+    }
+}
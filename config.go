@@ -0,0 +1,121 @@
+package jLogger
+
+import (
+    "encoding/json"
+    "fmt"
+    "hash/fnv"
+    "io"
+    "sort"
+    "time"
+
+    "github.com/natefinch/lumberjack"
+)
+
+// RotationConfig 一个级别日志文件的轮转配置
+type RotationConfig struct {
+    File       string `json:"file"`
+    MaxSizeMB  int    `json:"max_size_mb"`
+    MaxBackups int    `json:"max_backups"`
+    MaxAgeDays int    `json:"max_age_days"`
+    Compress   bool   `json:"compress"`
+}
+
+// EffectiveConfig 实际生效的配置：级别、缓冲、轮转、输出目标和各选项，
+// 回答"这个实例到底配置成记录什么"。Hash为除Hash本身外所有内容的摘要，配置相同的实例Hash相同
+type EffectiveConfig struct {
+    Hash             string                    `json:"hash"`
+    Level            string                    `json:"level"`
+    ModuleLevels     map[string]string         `json:"module_levels"`
+    Dir              string                    `json:"dir"`
+    Prefix           string                    `json:"prefix"`
+    BufferSize       int                       `json:"buffer_size"`
+    FlushInterval    string                    `json:"flush_interval"`
+    ChannelSize      int                       `json:"channel_size"`
+    ErrorChannelSize int                       `json:"error_channel_size"`
+    Rotation         map[string]RotationConfig `json:"rotation"` // 被SetOutput替换成其他Writer的级别不出现
+    Sinks            []string                  `json:"sinks"`
+    SinkFields       map[string]FieldFilter    `json:"sink_fields,omitempty"` // 各Sink的字段过滤规则
+    Options          map[string]string         `json:"options"`
+}
+
+// EffectiveConfig 返回当前生效的配置，克隆的Logger返回共享管道的配置
+func (l *Logger) EffectiveConfig() EffectiveConfig {
+    l = l.pipeline()
+    c := EffectiveConfig{
+        ModuleLevels:     make(map[string]string),
+        Dir:              l.logDir,
+        Prefix:           l.logPrefix,
+        BufferSize:       l.bufferSize,
+        FlushInterval:    l.flushInterval.String(),
+        ChannelSize:      cap(l.logChannel),
+        ErrorChannelSize: cap(l.errorChannel),
+        Rotation:         make(map[string]RotationConfig, 3),
+        Options:          l.optionSummary(),
+    }
+
+    l.levels_mu.RLock()
+    c.Level = l.log_level
+    for module, level := range l.moduleLevels {
+        c.ModuleLevels[module] = level
+    }
+    l.levels_mu.RUnlock()
+
+    for _, level := range levelNames {
+        logger, _, _ := l.levelOutput(level)
+        if lj := rotatingFile(logger.Writer()); lj != nil {
+            c.Rotation[level] = RotationConfig{
+                File:       lj.Filename,
+                MaxSizeMB:  lj.MaxSize,
+                MaxBackups: lj.MaxBackups,
+                MaxAgeDays: lj.MaxAge,
+                Compress:   lj.Compress,
+            }
+        }
+    }
+
+    l.sinks_mu.RLock()
+    for name := range l.sinks {
+        c.Sinks = append(c.Sinks, name)
+    }
+    l.sinks_mu.RUnlock()
+    sort.Strings(c.Sinks)
+    c.SinkFields = l.sinkFieldRules()
+
+    // encoding/json按key排序输出map，同样的配置得到同样的字节
+    b, _ := json.Marshal(c)
+    h := fnv.New64a()
+    h.Write(b)
+    c.Hash = fmt.Sprintf("%016x", h.Sum64())
+    return c
+}
+
+// 取出级别输出背后的lumberjack.Logger，挂了Tee副本时看主输出，依次拆开换行转换、重试和文件锁的包装
+func rotatingFile(w io.Writer) *lumberjack.Logger {
+    if t, ok := w.(*teeWriter); ok {
+        t.mu.RLock()
+        w = t.primary
+        t.mu.RUnlock()
+    }
+    for {
+        u, ok := w.(interface{ Unwrap() io.Writer })
+        if !ok {
+            break
+        }
+        w = u.Unwrap()
+    }
+    lj, _ := w.(*lumberjack.Logger)
+    return lj
+}
+
+// 把生效的配置作为一条Info记录写出，不受级别过滤影响。NewLogger、ReloadEnvLevels和ApplyRemoteConfig之后调用
+func (l *Logger) logConfig(reason string) {
+    if !l.configDump {
+        return
+    }
+    c := l.EffectiveConfig()
+    l.enqueue(l.InfoLogger, l.newMessage("INFO", time.Now(), []interface{}{"config"},
+        String("reason", reason),
+        String("config_hash", c.Hash),
+        Any("config", c),
+    ))
+}
@@ -0,0 +1,234 @@
+package jLogger
+
+import (
+    "bufio"
+    "bytes"
+    "compress/gzip"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+)
+
+// ConvertOptions 文本日志转换为JSON格式的参数
+type ConvertOptions struct {
+    OutDir string // 转换结果写入的目录，文件名不变；为空时原地替换
+}
+
+// ConvertResult 转换结果
+type ConvertResult struct {
+    Files     int // 扫描的文件数
+    Converted int // 含有文本记录、被转换的文件数
+    Records   int // 转换的记录数
+    Merged    int // 并入上一条记录消息的续行数（未转义的多行消息）
+}
+
+// ConvertFiles 把logDir下logPrefix的当前文件和归档中的文本格式记录改写为当前版本的JSON格式，
+// 开启WithJSON之后历史日志仍能按字段查询。已经是JSON的行原样保留，.gz归档保持压缩，已有的索引会重建。
+// 文本格式中字段的类型已经丢失，字段值一律转换为字符串。和PurgeFiles一样，不要对正在写入的当前文件调用，
+// 运行中的Logger请使用 l.Convert
+func ConvertFiles(logDir, logPrefix string, opts ConvertOptions) (ConvertResult, error) {
+    files, err := LogFiles(logDir, logPrefix)
+    if err != nil {
+        return ConvertResult{}, err
+    }
+    return convertFiles(files, logPrefix, opts)
+}
+
+// Convert 刷新缓冲区并轮转当前日志文件，然后对全部归档执行ConvertFiles。
+// 轮转后的当前文件仍由lumberjack打开写入，不做转换
+func (l *Logger) Convert(opts ConvertOptions) (ConvertResult, error) {
+    l = l.pipeline()
+    if err := l.rotateAll(); err != nil {
+        return ConvertResult{}, err
+    }
+    files, err := archiveFiles(l.logDir, l.logPrefix)
+    if err != nil {
+        return ConvertResult{}, err
+    }
+    return convertFiles(files, l.logPrefix, opts)
+}
+
+func convertFiles(files []string, logPrefix string, opts ConvertOptions) (ConvertResult, error) {
+    var res ConvertResult
+    if opts.OutDir != "" {
+        if err := os.MkdirAll(opts.OutDir, 0755); err != nil {
+            return res, err
+        }
+    }
+    for _, file := range files {
+        res.Files++
+        out := file
+        if opts.OutDir != "" {
+            out = filepath.Join(opts.OutDir, filepath.Base(file))
+        }
+        level, _ := parseLogFileName(filepath.Base(file), logPrefix)
+        records, merged, err := convertFile(file, out, level)
+        if err != nil {
+            return res, fmt.Errorf("%s: %v", file, err)
+        }
+        if records > 0 {
+            res.Converted++
+            res.Records += records
+            res.Merged += merged
+        }
+    }
+    return res, nil
+}
+
+// 转换一个文件，写入out（可以和path相同），返回转换的记录数和并入的续行数。原地转换时没有文本记录的文件不改写。
+// level为文件名中的级别，用于无法解析的行
+func convertFile(path, out, level string) (int, int, error) {
+    in, err := os.Open(path)
+    if err != nil {
+        return 0, 0, err
+    }
+    defer in.Close()
+
+    gz := strings.HasSuffix(path, ".gz")
+    var r io.Reader = in
+    if gz {
+        zr, err := gzip.NewReader(in)
+        if err != nil {
+            return 0, 0, err
+        }
+        defer zr.Close()
+        r = zr
+    }
+
+    tmp, err := os.CreateTemp(filepath.Dir(out), filepath.Base(out)+".convert-*")
+    if err != nil {
+        return 0, 0, err
+    }
+    defer os.Remove(tmp.Name())
+    var w io.Writer = tmp
+    var zw *gzip.Writer
+    if gz {
+        zw = gzip.NewWriter(tmp)
+        w = zw
+    }
+    bw := bufio.NewWriter(w)
+
+    // 文本记录要等到下一条记录开始才能确定消息是否还有续行
+    var pending *Record
+    records, merged := 0, 0
+    emit := func() {
+        if pending != nil {
+            bw.WriteString(recordJSON(*pending))
+            bw.WriteByte('\n')
+            pending = nil
+        }
+    }
+
+    scanner := bufio.NewScanner(r)
+    scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+    for scanner.Scan() {
+        line := scanner.Text()
+        if strings.HasPrefix(line, "{") {
+            if _, ok := parseJSONRecord(line); ok {
+                emit()
+                bw.WriteString(line)
+                bw.WriteByte('\n')
+                continue
+            }
+        }
+        rec, ok := parseTextRecord(line)
+        if ok && (!rec.Time.IsZero() || rec.hasField("fallback")) {
+            emit()
+            pending = &rec
+            records++
+            continue
+        }
+        // 不是一条记录的开头：未转义的多行消息的续行，并入上一条；文件开头就是续行时单独成为一条
+        if pending != nil {
+            pending.Message += "\n" + line
+            merged++
+            continue
+        }
+        pending = &Record{Level: level, Message: line}
+        records++
+    }
+    if err := scanner.Err(); err != nil {
+        tmp.Close()
+        return 0, 0, err
+    }
+    emit()
+    if records == 0 && out == path {
+        tmp.Close()
+        return 0, 0, nil
+    }
+
+    if err := bw.Flush(); err != nil {
+        tmp.Close()
+        return 0, 0, err
+    }
+    if zw != nil {
+        if err := zw.Close(); err != nil {
+            tmp.Close()
+            return 0, 0, err
+        }
+    }
+    if err := tmp.Close(); err != nil {
+        return 0, 0, err
+    }
+    if info, err := os.Stat(path); err == nil {
+        os.Chmod(tmp.Name(), info.Mode())
+    }
+    in.Close()
+    if err := os.Rename(tmp.Name(), out); err != nil {
+        return 0, 0, err
+    }
+    // 偏移全部变了，旧索引作废
+    if _, err := os.Stat(out + indexSuffix); err == nil {
+        BuildIndex(out, 0)
+    }
+    return records, merged, nil
+}
+
+func (r Record) hasField(key string) bool {
+    _, ok := r.field(key)
+    return ok
+}
+
+// 按encodeJSON的格式编码一条从文件中解析出来的记录
+func recordJSON(r Record) string {
+    var b bytes.Buffer
+    b.WriteString(`{"time":`)
+    if r.Time.IsZero() {
+        writeJSONString(&b, "")
+    } else {
+        writeJSONString(&b, r.Time.Format(timeFormat))
+    }
+    b.WriteString(`,"level":`)
+    writeJSONString(&b, r.Level)
+    b.WriteString(`,"msg":`)
+    writeJSONString(&b, r.Message)
+    b.WriteString(`,"` + schemaKey + `":`)
+    b.WriteString(strconv.Itoa(SchemaVersion))
+    if r.Module != "" {
+        b.WriteString(`,"module":`)
+        writeJSONString(&b, r.Module)
+    }
+    if r.Tag != "" {
+        b.WriteString(`,"tag":`)
+        writeJSONString(&b, r.Tag)
+    }
+    if r.Seq != 0 {
+        b.WriteString(`,"seq":`)
+        b.WriteString(strconv.FormatUint(r.Seq, 10))
+    }
+    if r.LogID != "" {
+        b.WriteString(`,"log_id":`)
+        writeJSONString(&b, r.LogID)
+    }
+    for _, f := range renameReserved(r.Fields) {
+        b.WriteByte(',')
+        writeJSONString(&b, f.Key)
+        b.WriteByte(':')
+        writeJSONString(&b, f.text())
+    }
+    b.WriteByte('}')
+    return b.String()
+}
@@ -0,0 +1,61 @@
+package jLogger
+
+import (
+    "bufio"
+    "fmt"
+    "os"
+    "path/filepath"
+    "runtime/debug"
+    "time"
+)
+
+// Recover 在main和各goroutine的入口处 defer log.Recover()：发生panic时写一条Error记录，
+// 把最近的记录（见WithRecent）和调用栈写入logDir下的崩溃文件，刷新缓冲区后继续panic，进程仍按原样崩溃。
+// Go没有拦截未恢复panic的全局钩子，没有经过Recover的goroutine崩溃时缓冲区中的日志会丢失
+func (l *Logger) Recover() {
+    r := recover()
+    if r == nil {
+        return
+    }
+    root := l.pipeline()
+    stack := debug.Stack()
+    path, err := root.crashDump(r, stack)
+    if err != nil {
+        root.internalError("写入崩溃文件失败:", err)
+    }
+    l.ErrorFields(fmt.Sprint("panic: ", r), String("crash_file", path))
+    if root.markers {
+        root.writeStopMarker("panic")
+    }
+    root.Flush()
+    panic(r)
+}
+
+// 崩溃文件：<logPrefix>.crash-<时间>，不匹配日志文件的命名，不会被轮转、保留策略和查询当作日志文件处理
+func (l *Logger) crashDump(reason interface{}, stack []byte) (string, error) {
+    now := time.Now()
+    path := filepath.Join(l.logDir, l.logPrefix+".crash-"+now.Format("20060102-150405.000"))
+    f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+    if err != nil {
+        return "", err
+    }
+    defer f.Close()
+
+    w := bufio.NewWriter(f)
+    fmt.Fprintf(w, "time: %s\npanic: %v\n\n%s\n", now.Format(timeFormat), reason, stack)
+    if l.recent != nil {
+        msgs := l.recent.last(0)
+        fmt.Fprintf(w, "最近的 %d 条记录:\n", len(msgs))
+        for _, msg := range msgs {
+            if l.json {
+                fmt.Fprintln(w, l.encode(msg))
+            } else {
+                fmt.Fprintln(w, l.levelLabel(msg.level)+": "+l.encode(msg))
+            }
+        }
+    }
+    if err := w.Flush(); err != nil {
+        return "", err
+    }
+    return path, f.Sync()
+}
@@ -0,0 +1,171 @@
+package jLogger
+
+import (
+    "bufio"
+    "encoding/json"
+    "os"
+    "path/filepath"
+    "sync"
+    "time"
+)
+
+// DeadLetter 死信文件中的一行：一条最终没能投递给Sink的记录
+type DeadLetter struct {
+    Time   time.Time `json:"time"`   // 写入死信文件的时间
+    Sink   string    `json:"sink"`
+    Reason string    `json:"reason"` // 放弃投递的原因和最后一次写入错误
+    Record Record    `json:"record"`
+}
+
+// ReprocessResult ReprocessDeadLetters的结果
+type ReprocessResult struct {
+    Requeued int // 重新放入Sink投递队列的记录数
+    Kept     int // 对应的Sink未挂载，留在死信文件中的记录数
+}
+
+type deadLetterFile struct {
+    path string
+    mu   sync.Mutex
+}
+
+// 追加一批死信，每次打开文件，死信很少，不必保持文件句柄
+func (d *deadLetterFile) append(letters []DeadLetter) error {
+    d.mu.Lock()
+    defer d.mu.Unlock()
+    f, err := os.OpenFile(d.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+    if err != nil {
+        return err
+    }
+    bw := bufio.NewWriter(f)
+    enc := json.NewEncoder(bw)
+    enc.SetEscapeHTML(false)
+    for _, dl := range letters {
+        if err := enc.Encode(dl); err != nil {
+            f.Close()
+            return err
+        }
+    }
+    if err := bw.Flush(); err != nil {
+        f.Close()
+        return err
+    }
+    return f.Close()
+}
+
+// Sink放弃投递的批次写入死信文件，未配置WithDeadLetter时只计数
+func (l *Logger) deadLetter(sink, reason string, batches ...[]Record) {
+    if l.deadLetters == nil {
+        return
+    }
+    now := time.Now()
+    var letters []DeadLetter
+    for _, batch := range batches {
+        for _, r := range batch {
+            letters = append(letters, DeadLetter{Time: now, Sink: sink, Reason: reason, Record: r})
+        }
+    }
+    if len(letters) == 0 {
+        return
+    }
+    if err := l.deadLetters.append(letters); err != nil {
+        l.internalError("写入死信文件失败:", l.deadLetters.path, err)
+    }
+}
+
+// ReadDeadLetters 读取死信文件中的全部记录
+func ReadDeadLetters(path string) ([]DeadLetter, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+    var letters []DeadLetter
+    scanner := bufio.NewScanner(f)
+    scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+    for scanner.Scan() {
+        var dl DeadLetter
+        if err := json.Unmarshal(scanner.Bytes(), &dl); err != nil {
+            return nil, err
+        }
+        letters = append(letters, dl)
+    }
+    return letters, scanner.Err()
+}
+
+// ReprocessDeadLetters 把死信文件中的记录重新放入同名Sink的投递队列，通常在Sink恢复后调用，
+// path可以是WithDeadLetter配置的文件，也可以是之前运行留下的文件。对应Sink未挂载的记录留在文件中，
+// 重新投递仍然失败的记录会再次写入配置的死信文件
+func (l *Logger) ReprocessDeadLetters(path string) (ReprocessResult, error) {
+    l = l.pipeline()
+    res, requeue, err := l.rewriteDeadLetters(path)
+    if err != nil {
+        return res, err
+    }
+    // 投递在释放死信文件的锁之后进行：积压超限时push会把溢出的批次追加回死信文件
+    for w, records := range requeue {
+        w.push(records)
+        res.Requeued += len(records)
+    }
+    return res, nil
+}
+
+// 读出path中的死信，按Sink分组，把对应Sink未挂载的记录写回文件
+func (l *Logger) rewriteDeadLetters(path string) (ReprocessResult, map[*sinkWorker][]Record, error) {
+    var res ReprocessResult
+    if l.deadLetters != nil && samePath(path, l.deadLetters.path) {
+        // 读取和改写期间不能有新的死信追加进来
+        l.deadLetters.mu.Lock()
+        defer l.deadLetters.mu.Unlock()
+    }
+    letters, err := ReadDeadLetters(path)
+    if err != nil {
+        return res, nil, err
+    }
+
+    l.sinks_mu.RLock()
+    requeue := make(map[*sinkWorker][]Record)
+    var kept []DeadLetter
+    for _, dl := range letters {
+        if w, ok := l.sinks[dl.Sink]; ok {
+            requeue[w] = append(requeue[w], dl.Record)
+        } else {
+            kept = append(kept, dl)
+        }
+    }
+    l.sinks_mu.RUnlock()
+
+    // 先改写文件，再投递：投递失败的记录会追加回死信文件
+    tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".reprocess-*")
+    if err != nil {
+        return res, nil, err
+    }
+    defer os.Remove(tmp.Name())
+    bw := bufio.NewWriter(tmp)
+    enc := json.NewEncoder(bw)
+    enc.SetEscapeHTML(false)
+    for _, dl := range kept {
+        if err := enc.Encode(dl); err != nil {
+            tmp.Close()
+            return res, nil, err
+        }
+    }
+    if err := bw.Flush(); err != nil {
+        tmp.Close()
+        return res, nil, err
+    }
+    if err := tmp.Close(); err != nil {
+        return res, nil, err
+    }
+    if err := os.Rename(tmp.Name(), path); err != nil {
+        return res, nil, err
+    }
+
+    res.Kept = len(kept)
+    return res, requeue, nil
+}
+
+func samePath(a, b string) bool {
+    a, errA := filepath.Abs(a)
+    b, errB := filepath.Abs(b)
+    return errA == nil && errB == nil && a == b
+}
@@ -0,0 +1,63 @@
+package jLogger
+
+import (
+    "path/filepath"
+    "testing"
+    "time"
+)
+
+func TestReprocessDeadLettersOverflow(t *testing.T) {
+    dir := t.TempDir()
+    path := filepath.Join(dir, "dead.jsonl")
+    l := newTestLogger(t, "INFO", WithDeadLetter(path))
+    release := make(chan struct{})
+    entered := make(chan struct{}, 1)
+    l.AddSink("slow", NewCallbackSink(func(batch []Record) error {
+        select {
+        case entered <- struct{}{}:
+        default:
+        }
+        <-release
+        return nil
+    }))
+    defer close(release)
+
+    l.sinks_mu.RLock()
+    w := l.sinks["slow"]
+    l.sinks_mu.RUnlock()
+    // 投递协程阻塞在第一批，之后的批次把积压填满
+    w.push(make([]Record, 1))
+    <-entered
+    w.push(make([]Record, sinkBacklogLimit))
+
+    letters := make([]Record, 10)
+    for i := range letters {
+        letters[i] = Record{Message: "dead"}
+    }
+    l.deadLetter("slow", "test", letters)
+
+    done := make(chan error, 1)
+    go func() {
+        res, err := l.ReprocessDeadLetters(path)
+        if err == nil && res.Requeued != 10 {
+            t.Errorf("重新投递了%d条, 期望10条", res.Requeued)
+        }
+        done <- err
+    }()
+    select {
+    case err := <-done:
+        if err != nil {
+            t.Fatal(err)
+        }
+    case <-time.After(5 * time.Second):
+        t.Fatal("积压超限时ReprocessDeadLetters死锁")
+    }
+    // 溢出的批次写回了死信文件
+    back, err := ReadDeadLetters(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(back) != sinkBacklogLimit {
+        t.Fatalf("死信文件中有%d条, 期望溢出的%d条", len(back), sinkBacklogLimit)
+    }
+}
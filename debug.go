@@ -0,0 +1,60 @@
+package jLogger
+
+import (
+    "encoding/json"
+    "html/template"
+    "net/http"
+    "strings"
+)
+
+var debugPage = template.Must(template.New("jlogger").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>jLogger</title>
+<style>body{font-family:monospace}table{border-collapse:collapse}td,th{border:1px solid #ccc;padding:2px 8px;text-align:left}</style>
+</head><body>
+<h2>jLogger</h2>
+<table>
+<tr><th>config_hash</th><td>{{.ConfigHash}}</td></tr>
+<tr><th>level</th><td>{{.Level}}</td></tr>
+<tr><th>buffer_size</th><td>{{.BufferSize}}</td></tr>
+<tr><th>flush_interval</th><td>{{.FlushInterval}}</td></tr>
+<tr><th>channel_depth</th><td>{{.ChannelDepth}}</td></tr>
+<tr><th>error_channel_depth</th><td>{{.ErrorChannelDepth}}</td></tr>
+<tr><th>sequence</th><td>{{.Sequence}}</td></tr>
+</table>
+<h3>options</h3>
+<table>{{range $k, $v := .Options}}<tr><th>{{$k}}</th><td>{{$v}}</td></tr>{{end}}</table>
+<h3>rotation</h3>
+<table><tr><th>level</th><th>file</th><th>max_size_mb</th><th>max_backups</th><th>max_age_days</th><th>compress</th></tr>
+{{range $k, $v := .Rotation}}<tr><td>{{$k}}</td><td>{{$v.File}}</td><td>{{$v.MaxSizeMB}}</td><td>{{$v.MaxBackups}}</td><td>{{$v.MaxAgeDays}}</td><td>{{$v.Compress}}</td></tr>{{end}}
+</table>
+<h3>buffers</h3>
+<table><tr><th>level</th><th>depth</th><th>dropped</th><th>written</th><th>latency p50</th><th>latency p99</th></tr>
+{{range $k, $v := .BufferDepth}}{{$lat := index $.WriteLatency $k}}<tr><td>{{$k}}</td><td>{{$v}}</td><td>{{index $.Dropped $k}}</td><td>{{$lat.Count}}</td><td>{{$lat.P50}}</td><td>{{$lat.P99}}</td></tr>{{end}}
+</table>
+<h3>sinks</h3>
+<table><tr><th>name</th><th>healthy</th><th>backlog</th><th>delivered</th><th>failures</th><th>dropped</th><th>last error</th></tr>
+{{range .SinkHealth}}<tr><td>{{.Name}}</td><td>{{.Healthy}}</td><td>{{.Backlog}}</td><td>{{.Delivered}}</td><td>{{.Failures}}</td><td>{{.Dropped}}</td><td>{{.LastError}}</td></tr>{{else}}<tr><td>-</td></tr>{{end}}
+</table>
+<h3>module levels</h3>
+<table>{{range $k, $v := .ModuleLevels}}<tr><th>{{$k}}</th><td>{{$v}}</td></tr>{{else}}<tr><td>-</td></tr>{{end}}</table>
+<h3>recent errors</h3>
+<table>{{range .RecentErrors}}<tr><td>{{.Time.Format "2006-01-02 15:04:05.000"}}</td><td>{{.Message}}</td></tr>{{else}}<tr><td>-</td></tr>{{end}}</table>
+</body></html>
+`))
+
+// DebugHandler 返回展示Logger内部状态的http.Handler，可挂载到 /debug/jlogger：
+// 默认输出HTML页面，带 ?format=json 或 Accept: application/json 时输出Stats的JSON
+func (l *Logger) DebugHandler() http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        st := l.Stats()
+        if r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+            w.Header().Set("Content-Type", "application/json; charset=utf-8")
+            enc := json.NewEncoder(w)
+            enc.SetIndent("", "  ")
+            enc.Encode(st)
+            return
+        }
+        w.Header().Set("Content-Type", "text/html; charset=utf-8")
+        debugPage.Execute(w, st)
+    })
+}
@@ -0,0 +1,12 @@
+//go:build !jlogger_nodebug
+
+package jLogger
+
+// DebugEnabled 编译时是否保留Debug日志。使用 -tags jlogger_nodebug 编译时为false，
+// Debug和DebugFields变成可以内联的空函数，调用被编译器整体消除。
+// 参数中有函数调用等有副作用的表达式时，Go仍会对其求值，需要完全零开销的地方这样写：
+//
+//    if jLogger.DebugEnabled {
+//        log.Debug("state", dumpState())
+//    }
+const DebugEnabled = true
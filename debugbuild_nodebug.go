@@ -0,0 +1,6 @@
+//go:build jlogger_nodebug
+
+package jLogger
+
+// DebugEnabled 见debugbuild.go，当前以 -tags jlogger_nodebug 编译，Debug日志全部被移除
+const DebugEnabled = false
@@ -0,0 +1,137 @@
+package jLogger
+
+import (
+    "hash/fnv"
+    "sync/atomic"
+    "time"
+)
+
+// 降级的各个阶段，依次加重
+const (
+    degradeNormal     int32 = iota // 不降级
+    degradeDropDebug               // 丢弃Debug
+    degradeSampleInfo              // 丢弃Debug，Info按比例采样
+    degradeErrorOnly               // 只保留Error
+)
+
+var degradeStageNames = [...]string{"normal", "drop_debug", "sample_info", "error_only"}
+
+// Degradation 持续过载时的自动降级策略：每个检查周期内通道占用超过3/4、或者发生了通道溢出、或者超出内存上限，
+// 视为过载。连续Overload个周期过载升一级（先丢弃Debug，再对Info采样，最后只保留Error），
+// 连续Recover个周期空闲（通道占用低于1/4且没有溢出）降一级，直到恢复正常。阶段变化写入Error日志文件
+type Degradation struct {
+    Interval       time.Duration // 检查周期，默认1秒
+    Overload       int           // 连续过载多少个周期升级，默认3
+    Recover        int           // 连续空闲多少个周期恢复一级，默认10
+    InfoSampleRate float64       // sample_info阶段Info的保留比例，默认0.1
+}
+
+type degrader struct {
+    Degradation
+    threshold uint64 // InfoSampleRate换算的采样阈值
+    busy      int
+    calm      int
+    drops     uint64 // 上个周期结束时的溢出总数
+}
+
+func newDegrader(d Degradation) *degrader {
+    if d.Interval <= 0 {
+        d.Interval = time.Second
+    }
+    if d.Overload <= 0 {
+        d.Overload = 3
+    }
+    if d.Recover <= 0 {
+        d.Recover = 10
+    }
+    if d.InfoSampleRate <= 0 || d.InfoSampleRate > 1 {
+        d.InfoSampleRate = 0.1
+    }
+    return &degrader{Degradation: d, threshold: sampleThreshold(d.InfoSampleRate)}
+}
+
+// 当前降级阶段是否丢弃这条Info/Debug记录。Info的采样和SetSampleRate一样按(模块, 消息模板)的哈希决定
+func (l *Logger) degraded(level string, v []interface{}) bool {
+    root := l.pipeline()
+    stage := atomic.LoadInt32(&root.degradeStage)
+    drop := false
+    switch {
+    case stage == degradeNormal || level == "ERROR":
+        return false
+    case level == "DEBUG" || stage >= degradeErrorOnly:
+        drop = true
+    default:
+        var template string
+        if len(v) > 0 {
+            template, _ = v[0].(string)
+        }
+        h := fnv.New64a()
+        h.Write([]byte(l.module))
+        h.Write([]byte{0})
+        h.Write([]byte(template))
+        drop = h.Sum64()%sampleScale >= root.degrade.threshold
+    }
+    if drop {
+        atomic.AddUint64(&root.degradedOut, 1)
+    }
+    return drop
+}
+
+func (l *Logger) runDegradation() {
+    defer l.emitters.Done()
+    ticker := time.NewTicker(l.degrade.Interval)
+    defer ticker.Stop()
+    for {
+        select {
+        case <-l.done:
+            return
+        case <-ticker.C:
+        }
+        l.checkDegradation()
+    }
+}
+
+func (l *Logger) checkDegradation() {
+    d := l.degrade
+    var drops uint64
+    for i := range l.dropCounts {
+        drops += atomic.LoadUint64(&l.dropCounts[i])
+    }
+    drops += atomic.LoadUint64(&l.memRejected)
+    dropped := drops - d.drops
+    d.drops = drops
+
+    depth, size := len(l.logChannel), cap(l.logChannel)
+    switch {
+    case dropped > 0 || depth*4 >= size*3:
+        d.busy++
+        d.calm = 0
+    case depth*4 < size:
+        d.calm++
+        d.busy = 0
+    default:
+        d.busy, d.calm = 0, 0
+    }
+
+    stage := atomic.LoadInt32(&l.degradeStage)
+    next := stage
+    if d.busy >= d.Overload && stage < degradeErrorOnly {
+        next = stage + 1
+        d.busy = 0
+    } else if d.calm >= d.Recover && stage > degradeNormal {
+        next = stage - 1
+        d.calm = 0
+    }
+    if next == stage {
+        return
+    }
+    atomic.StoreInt32(&l.degradeStage, next)
+    msg := l.newMessage("ERROR", l.now(), []interface{}{"degradation"},
+        String("from", degradeStageNames[stage]),
+        String("to", degradeStageNames[next]),
+        Int("channel_depth", depth),
+        Int64("dropped", int64(dropped)),
+    )
+    msg.internal = true
+    l.enqueue(l.ErrorLogger, msg)
+}
@@ -0,0 +1,88 @@
+package jLogger
+
+import (
+    "strings"
+    "sync"
+    "sync/atomic"
+    "testing"
+    "time"
+)
+
+func TestDegradationLadderConcurrent(t *testing.T) {
+    dir := t.TempDir()
+    // Interval足够长，周期检查由测试手动调用checkDegradation
+    l, err := NewLogger(dir, "app", 1024, time.Millisecond, "DEBUG",
+        WithDegradation(Degradation{Interval: time.Hour, Overload: 1, Recover: 1}))
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer l.Close()
+
+    stop := make(chan struct{})
+    var errs int64
+    var wg sync.WaitGroup
+    for w := 0; w < 4; w++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for {
+                select {
+                case <-stop:
+                    return
+                default:
+                }
+                l.Debug("ladder-debug")
+                l.Info("ladder-info")
+                l.Error("ladder-error")
+                atomic.AddInt64(&errs, 1)
+            }
+        }()
+    }
+
+    for _, want := range []string{"drop_debug", "sample_info", "error_only"} {
+        time.Sleep(5 * time.Millisecond)
+        atomic.AddUint64(&l.dropCounts[0], 1) // 模拟本周期发生了通道溢出
+        l.checkDegradation()
+        if got := l.Stats().Degradation; got != want {
+            t.Fatalf("过载后阶段为%s, 期望%s", got, want)
+        }
+    }
+    before := l.Stats().DegradedOut
+    l.Info("ladder-info")
+    if after := l.Stats().DegradedOut; after <= before {
+        t.Fatalf("error_only阶段DegradedOut没有增长: %d -> %d", before, after)
+    }
+    close(stop)
+    wg.Wait()
+    l.Flush()
+    // 写入期间真实发生的通道溢出不计入恢复阶段的第一个周期
+    l.degrade.drops = atomic.LoadUint64(&l.memRejected)
+    for i := range l.dropCounts {
+        l.degrade.drops += atomic.LoadUint64(&l.dropCounts[i])
+    }
+
+    for _, want := range []string{"sample_info", "drop_debug", "normal"} {
+        l.checkDegradation()
+        if got := l.Stats().Degradation; got != want {
+            t.Fatalf("空闲后阶段为%s, 期望%s", got, want)
+        }
+        l.Flush()
+    }
+    l.Close()
+
+    var changes, errLines int64
+    for _, line := range readAllLines(t, dir, "app") {
+        switch {
+        case strings.Contains(line, "degradation"):
+            changes++
+        case strings.Contains(line, "ladder-error"):
+            errLines++
+        }
+    }
+    if changes != 6 {
+        t.Fatalf("阶段变化记录%d条, 期望6条", changes)
+    }
+    if errLines != atomic.LoadInt64(&errs) {
+        t.Fatalf("写入%d条Error, 期望%d条, 降级不应丢弃Error", errLines, errs)
+    }
+}
@@ -0,0 +1,41 @@
+package jLogger
+
+import "sync/atomic"
+
+var levelNames = [3]string{"INFO", "DEBUG", "ERROR"}
+
+// 级别在计数数组中的下标，未知级别返回-1
+func levelIndex(level string) int {
+    switch level {
+    case "INFO":
+        return 0
+    case "DEBUG":
+        return 1
+    case "ERROR":
+        return 2
+    }
+    return -1
+}
+
+// 记录一次通道溢出：记录被丢弃，或者绕过通道同步写入
+func (l *Logger) recordDrop(level string) {
+    if i := levelIndex(level); i >= 0 {
+        atomic.AddUint64(&l.dropCounts[i], 1)
+    }
+}
+
+// 把上次上报之后新增的溢出条数交给onDrop，由定时刷新协程和Close调用，调用频率不超过刷新间隔
+func (l *Logger) reportDrops() {
+    if l.onDrop == nil {
+        return
+    }
+    l.drop_mu.Lock()
+    defer l.drop_mu.Unlock()
+    for i, name := range levelNames {
+        total := atomic.LoadUint64(&l.dropCounts[i])
+        if n := total - l.dropReported[i]; n > 0 {
+            l.dropReported[i] = total
+            l.onDrop(name, int(n))
+        }
+    }
+}
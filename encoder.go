@@ -0,0 +1,69 @@
+package jLogger
+
+import (
+    "reflect"
+    "sync"
+    "sync/atomic"
+)
+
+// 按类型注册的字段编码函数，写时复制，读取不加锁
+var (
+    fieldEncoders    atomic.Value // map[reflect.Type]func(interface{}) interface{}
+    fieldEncoders_mu sync.Mutex
+)
+
+// RegisterFieldEncoder 为类型T注册编码函数：Any字段的值和消息参数是T时，先经过fn转换再输出，
+// 文本、JSON、Sink和订阅收到的记录都一致，例如：
+//
+//    jLogger.RegisterFieldEncoder(func(m Money) any { return m.String() + " " + m.Currency })
+//    jLogger.RegisterFieldEncoder(func(ip netip.Addr) any { return ip.Unmap().String() })
+//
+// 只匹配T本身，不匹配*T。应在程序启动时注册，重复注册同一类型时后注册的生效
+func RegisterFieldEncoder[T any](fn func(T) any) {
+    t := reflect.TypeOf((*T)(nil)).Elem()
+    fieldEncoders_mu.Lock()
+    defer fieldEncoders_mu.Unlock()
+    old, _ := fieldEncoders.Load().(map[reflect.Type]func(interface{}) interface{})
+    m := make(map[reflect.Type]func(interface{}) interface{}, len(old)+1)
+    for k, v := range old {
+        m[k] = v
+    }
+    m[t] = func(v interface{}) interface{} { return fn(v.(T)) }
+    fieldEncoders.Store(m)
+}
+
+// 值的类型注册了编码函数时返回转换后的值
+func encodeValue(v interface{}) interface{} {
+    m, _ := fieldEncoders.Load().(map[reflect.Type]func(interface{}) interface{})
+    if len(m) == 0 || v == nil {
+        return v
+    }
+    if fn, ok := m[reflect.TypeOf(v)]; ok {
+        return fn(v)
+    }
+    return v
+}
+
+// 对消息参数逐个应用编码函数，没有注册任何编码函数时原样返回
+func encodeArgs(args []interface{}) []interface{} {
+    m, _ := fieldEncoders.Load().(map[reflect.Type]func(interface{}) interface{})
+    if len(m) == 0 {
+        return args
+    }
+    var out []interface{}
+    for i, a := range args {
+        if a == nil {
+            continue
+        }
+        if fn, ok := m[reflect.TypeOf(a)]; ok {
+            if out == nil {
+                out = append(make([]interface{}, 0, len(args)), args...)
+            }
+            out[i] = fn(a)
+        }
+    }
+    if out == nil {
+        return args
+    }
+    return out
+}
@@ -0,0 +1,102 @@
+package jLogger
+
+import (
+    "fmt"
+    "os"
+    "strings"
+)
+
+// 按模块设置级别的环境变量，格式：JLOGGER_LEVELS=db=DEBUG,http=WARN,*=INFO
+// "*" 表示没有自己级别的模块的默认级别；没有WARN级别，WARN/WARNING按ERROR处理
+const EnvLevels = "JLOGGER_LEVELS"
+
+// ParseLevels 解析 module=LEVEL,module=LEVEL 形式的级别配置，级别不区分大小写
+func ParseLevels(spec string) (map[string]string, error) {
+    levels := make(map[string]string)
+    var bad []string
+    for _, item := range strings.Split(spec, ",") {
+        item = strings.TrimSpace(item)
+        if item == "" {
+            continue
+        }
+        kv := strings.SplitN(item, "=", 2)
+        if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+            bad = append(bad, item)
+            continue
+        }
+        level, ok := normalizeLevel(kv[1])
+        if !ok {
+            bad = append(bad, item)
+            continue
+        }
+        levels[strings.TrimSpace(kv[0])] = level
+    }
+    if len(bad) > 0 {
+        return levels, fmt.Errorf("无效的日志级别配置: %s", strings.Join(bad, ","))
+    }
+    return levels, nil
+}
+
+func normalizeLevel(level string) (string, bool) {
+    switch strings.ToUpper(strings.TrimSpace(level)) {
+    case "DEBUG":
+        return "DEBUG", true
+    case "INFO":
+        return "INFO", true
+    case "WARN", "WARNING", "ERROR":
+        return "ERROR", true
+    }
+    return "", false
+}
+
+// ReloadEnvLevels 重新读取JLOGGER_LEVELS并应用：上一次从环境变量设置的模块级别先被移除，
+// 通过SetModuleLevel或远程配置设置的级别不受影响，包括覆盖了环境变量的同名模块（重新加载后同名时以环境变量为准）
+func (l *Logger) ReloadEnvLevels() error {
+    l = l.pipeline()
+    err := l.applyEnvLevels()
+    l.logConfig("reload")
+    return err
+}
+
+// NewLogger时和ReloadEnvLevels时调用，无效的条目被忽略并写入Error日志，其余条目照常生效
+func (l *Logger) applyEnvLevels() error {
+    levels, err := ParseLevels(os.Getenv(EnvLevels))
+    if err != nil {
+        l.internalError(EnvLevels, err)
+    }
+
+    l.replaceModuleLevels(&l.envModules, levels)
+    return err
+}
+
+// 用levels替换上一次由同一来源（环境变量、远程配置）设置的模块级别，owner记录该来源设置过、
+// 之后没有被其他来源覆盖的模块
+func (l *Logger) replaceModuleLevels(owner *[]string, levels map[string]string) {
+    l.levels_mu.Lock()
+    defer l.levels_mu.Unlock()
+    for _, module := range *owner {
+        delete(l.moduleLevels, module)
+    }
+    *owner = (*owner)[:0]
+    if len(levels) > 0 && l.moduleLevels == nil {
+        l.moduleLevels = make(map[string]string)
+    }
+    for module, level := range levels {
+        l.disownModule(module)
+        l.moduleLevels[module] = level
+        *owner = append(*owner, module)
+    }
+}
+
+// 模块的级别被另一个来源（SetModuleLevel、环境变量、远程配置）覆盖后，不再属于原来的来源，
+// 原来的来源重新加载时不会把它移除。调用方持有levels_mu
+func (l *Logger) disownModule(module string) {
+    for _, owner := range []*[]string{&l.envModules, &l.remoteModules} {
+        for i, m := range *owner {
+            if m == module {
+                *owner = append((*owner)[:i], (*owner)[i+1:]...)
+                break
+            }
+        }
+    }
+}
@@ -0,0 +1,85 @@
+package jLogger
+
+import (
+    "fmt"
+    "strings"
+)
+
+// EscalationRule 级别提升规则：记录的消息包含Contains，或者带有Field字段（Value不为空时还要求值相等），
+// 就把它提升到Level（默认ERROR）。只会提升，不会降低级别
+type EscalationRule struct {
+    Contains string
+    Field    string
+    Value    string
+    Level    string
+}
+
+// 规则在级别过滤之前执行，被关闭的Debug记录命中规则后照样按提升后的级别写入。
+// 命中时以新级别重新写入并返回true，调用方直接返回；记录带有escalated_from字段，标明原来的级别
+func (l *Logger) escalated(level string, v []interface{}, fields []Field) bool {
+    rules := l.pipeline().escalation
+    if len(rules) == 0 {
+        return false
+    }
+    target := l.escalateLevel(rules, level, v, fields)
+    if target == "" {
+        return false
+    }
+    mark := String("escalated_from", level)
+    if fields != nil {
+        // *Fields方法：第一个参数是消息文本
+        msg, _ := v[0].(string)
+        fields = append(fields[:len(fields):len(fields)], mark)
+        switch target {
+        case "INFO":
+            l.InfoFields(msg, fields...)
+        default:
+            l.ErrorFields(msg, fields...)
+        }
+        return true
+    }
+    v = append(v[:len(v):len(v)], mark)
+    switch target {
+    case "INFO":
+        l.Info(v...)
+    default:
+        l.Error(v...)
+    }
+    return true
+}
+
+// 返回命中规则中最高的目标级别，没有命中或不高于当前级别时返回空
+func (l *Logger) escalateLevel(rules []EscalationRule, level string, v []interface{}, fields []Field) string {
+    args, inline := splitFields(v)
+    var text string
+    target := ""
+    for _, r := range rules {
+        to := r.Level
+        if levelRank(to) <= levelRank(level) || (target != "" && levelRank(to) <= levelRank(target)) {
+            continue
+        }
+        matched := false
+        if r.Contains != "" {
+            if text == "" {
+                text = strings.TrimSpace(fmt.Sprintln(args...))
+            }
+            matched = strings.Contains(text, r.Contains)
+        }
+        if !matched && r.Field != "" {
+            matched = hasField(inline, r.Field, r.Value) || hasField(fields, r.Field, r.Value)
+        }
+        if matched {
+            target = to
+        }
+    }
+    return target
+}
+
+func hasField(fields []Field, key, value string) bool {
+    for _, f := range fields {
+        if f.Key == key && (value == "" || f.text() == value) {
+            return true
+        }
+    }
+    return false
+}
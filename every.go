@@ -0,0 +1,129 @@
+package jLogger
+
+import (
+    "runtime"
+    "sync"
+    "time"
+)
+
+// 某个调用位置的限频状态
+type everyState struct {
+    calls      uint64
+    last       time.Time
+    suppressed int64 // 上次输出之后被跳过的次数
+}
+
+// InfoEveryN 同一调用位置每n次调用只输出一次（第1、n+1、2n+1...次），用于重试和轮询循环。
+// 输出的记录带有suppressed字段，表示上次输出之后跳过的次数。n<=0时按1处理，每次调用都输出
+func (l *Logger) InfoEveryN(n int, v ...interface{}) {
+    if !l.enabled("INFO") {
+        return
+    }
+    if ok, skipped := l.every(callSite(), n, 0); ok {
+        l.Info(withSuppressed(v, skipped)...)
+    }
+}
+
+// ErrorEveryN 同InfoEveryN，以Error级别输出
+func (l *Logger) ErrorEveryN(n int, v ...interface{}) {
+    if ok, skipped := l.every(callSite(), n, 0); ok {
+        l.Error(withSuppressed(v, skipped)...)
+    }
+}
+
+// InfoEvery 同一调用位置每d时间内最多输出一次，d<=0时每次调用都输出
+func (l *Logger) InfoEvery(d time.Duration, v ...interface{}) {
+    if !l.enabled("INFO") {
+        return
+    }
+    if ok, skipped := l.every(callSite(), 0, d); ok {
+        l.Info(withSuppressed(v, skipped)...)
+    }
+}
+
+// ErrorEvery 同InfoEvery，以Error级别输出
+func (l *Logger) ErrorEvery(d time.Duration, v ...interface{}) {
+    if ok, skipped := l.every(callSite(), 0, d); ok {
+        l.Error(withSuppressed(v, skipped)...)
+    }
+}
+
+// 调用XxxEvery的位置
+func callSite() uintptr {
+    var pcs [1]uintptr
+    runtime.Callers(3, pcs[:])
+    return pcs[0]
+}
+
+// 判断这次调用是否输出，返回上次输出之后跳过的次数。状态保存在共享管道上，按调用位置区分
+func (l *Logger) every(pc uintptr, n int, d time.Duration) (bool, int64) {
+    root := l.pipeline()
+    now := l.now()
+    root.every_mu.Lock()
+    defer root.every_mu.Unlock()
+    if root.everyStates == nil {
+        root.everyStates = make(map[uintptr]*everyState)
+    }
+    st, ok := root.everyStates[pc]
+    if !ok {
+        st = &everyState{}
+        root.everyStates[pc] = st
+    }
+    st.calls++
+    // n和d都没有有效值时不限频
+    emit := st.calls == 1 || (n <= 0 && d <= 0)
+    if !emit && n > 0 {
+        emit = (st.calls-1)%uint64(n) == 0
+    }
+    if !emit && d > 0 {
+        emit = now.Sub(st.last) >= d
+    }
+    if !emit {
+        st.suppressed++
+        return false, 0
+    }
+    skipped := st.suppressed
+    st.suppressed = 0
+    st.last = now
+    return true, skipped
+}
+
+func withSuppressed(v []interface{}, skipped int64) []interface{} {
+    if skipped == 0 {
+        return v
+    }
+    return append(v[:len(v):len(v)], Int64("suppressed", skipped))
+}
+
+// InfoOnce 同一个key在进程内只输出第一次，用于热路径中的弃用提示、启动告警等。
+// 进程内所有Logger（包括多次NewLogger创建的）共享同一份记录，和ErrorOnce也共用key。
+// Info未开启时不计入，级别打开后的第一次调用仍会输出
+func (l *Logger) InfoOnce(key string, v ...interface{}) {
+    if l.enabled("INFO") && first(key) {
+        l.Info(v...)
+    }
+}
+
+// ErrorOnce 同InfoOnce，以Error级别输出
+func (l *Logger) ErrorOnce(key string, v ...interface{}) {
+    if first(key) {
+        l.Error(v...)
+    }
+}
+
+// InfoOnce、ErrorOnce已经输出过的key，进程级别
+var onceKeys sync.Map
+
+// key是否在进程内第一次出现
+func first(key string) bool {
+    _, loaded := onceKeys.LoadOrStore(key, struct{}{})
+    return !loaded
+}
+
+// 清空已经输出过的key，供测试之间隔离
+func resetOnceKeys() {
+    onceKeys.Range(func(key, _ interface{}) bool {
+        onceKeys.Delete(key)
+        return true
+    })
+}
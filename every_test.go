@@ -0,0 +1,124 @@
+package jLogger
+
+import (
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+)
+
+func TestInfoOnceAcrossLoggers(t *testing.T) {
+    resetOnceKeys()
+    t.Cleanup(resetOnceKeys)
+    key := "deprecated-option"
+    dirs := []string{t.TempDir(), t.TempDir()}
+    for _, dir := range dirs {
+        l, err := NewLogger(dir, "app", 16, 10*time.Millisecond, "INFO")
+        if err != nil {
+            t.Fatal(err)
+        }
+        l.InfoOnce(key, "deprecated option")
+        l.Named("sub").InfoOnce(key, "deprecated option")
+        l.Close()
+    }
+    total := 0
+    for _, dir := range dirs {
+        data, err := os.ReadFile(filepath.Join(dir, "app_info.log"))
+        if err != nil && !os.IsNotExist(err) {
+            t.Fatal(err)
+        }
+        total += strings.Count(string(data), "deprecated option")
+    }
+    if total != 1 {
+        t.Fatalf("同一个key在进程内输出了%d次, 期望1次", total)
+    }
+}
+
+// 读出Info文件中包含text的行
+func infoLines(t *testing.T, dir, text string) []string {
+    t.Helper()
+    data, err := os.ReadFile(filepath.Join(dir, "app_info.log"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    var lines []string
+    for _, line := range strings.Split(string(data), "\n") {
+        if strings.Contains(line, text) {
+            lines = append(lines, line)
+        }
+    }
+    return lines
+}
+
+func TestInfoEveryN(t *testing.T) {
+    dir := t.TempDir()
+    l, err := NewLogger(dir, "app", 16, 10*time.Millisecond, "INFO")
+    if err != nil {
+        t.Fatal(err)
+    }
+    for i := 0; i < 10; i++ {
+        l.InfoEveryN(3, "retry")
+    }
+    for i := 0; i < 3; i++ {
+        l.InfoEveryN(0, "unlimited")
+    }
+    l.Close()
+
+    lines := infoLines(t, dir, "retry")
+    if len(lines) != 4 {
+        t.Fatalf("10次调用输出了%d行, 期望第1、4、7、10次共4行", len(lines))
+    }
+    if strings.Contains(lines[0], "suppressed") {
+        t.Fatalf("第一次输出不应带suppressed字段: %s", lines[0])
+    }
+    for _, line := range lines[1:] {
+        if !strings.Contains(line, "suppressed=2") {
+            t.Fatalf("期望suppressed=2: %s", line)
+        }
+    }
+    if n := len(infoLines(t, dir, "unlimited")); n != 3 {
+        t.Fatalf("n<=0时3次调用输出了%d行, 期望每次都输出", n)
+    }
+}
+
+func TestInfoEveryDuration(t *testing.T) {
+    dir := t.TempDir()
+    var mu sync.Mutex
+    now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local)
+    clock := func() time.Time {
+        mu.Lock()
+        defer mu.Unlock()
+        return now
+    }
+    advance := func(d time.Duration) {
+        mu.Lock()
+        now = now.Add(d)
+        mu.Unlock()
+    }
+    l, err := NewLogger(dir, "app", 16, 10*time.Millisecond, "INFO", WithClock(clock))
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, step := range []time.Duration{0, time.Second, 5 * time.Second, 4 * time.Second, time.Second} {
+        advance(step)
+        l.InfoEvery(10*time.Second, "poll")
+    }
+    for i := 0; i < 3; i++ {
+        l.InfoEvery(0, "always")
+    }
+    l.Close()
+
+    // 0s输出，1s、6s跳过，10s输出，11s跳过
+    lines := infoLines(t, dir, "poll")
+    if len(lines) != 2 {
+        t.Fatalf("输出了%d行, 期望2行: %v", len(lines), lines)
+    }
+    if !strings.Contains(lines[1], "suppressed=2") {
+        t.Fatalf("期望suppressed=2: %s", lines[1])
+    }
+    if n := len(infoLines(t, dir, "always")); n != 3 {
+        t.Fatalf("d<=0时3次调用输出了%d行, 期望每次都输出", n)
+    }
+}
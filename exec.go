@@ -0,0 +1,112 @@
+package jLogger
+
+import (
+    "bytes"
+    "io"
+    "os/exec"
+    "path/filepath"
+    "strings"
+    "sync"
+)
+
+// 单行的最大长度，超过时按这个长度切开，避免没有换行的输出占满内存
+const maxCaptureLine = 64 * 1024
+
+// CaptureOptions 子进程输出的捕获方式
+type CaptureOptions struct {
+    Name        string // 记录上cmd字段的值，默认为命令的文件名
+    StdoutLevel string // 标准输出的级别，默认INFO
+    StderrLevel string // 标准错误的级别，默认ERROR
+    Fields      []Field
+}
+
+// CaptureOutput 把cmd的标准输出和标准错误接到l上：按行写成记录，带cmd和stream字段，
+// 日志进入轮转的文件而不是控制台。必须在cmd.Start/Run之前调用，cmd.Wait返回后调用done写出最后不完整的一行：
+//
+//    done := jLogger.CaptureOutput(log, cmd, jLogger.CaptureOptions{})
+//    err := cmd.Run()
+//    done()
+func CaptureOutput(l Interface, cmd *exec.Cmd, opts CaptureOptions) (done func()) {
+    if opts.Name == "" {
+        opts.Name = filepath.Base(cmd.Path)
+    }
+    if opts.StdoutLevel == "" {
+        opts.StdoutLevel = "INFO"
+    }
+    if opts.StderrLevel == "" {
+        opts.StderrLevel = "ERROR"
+    }
+    fields := append([]Field{String("cmd", opts.Name)}, opts.Fields...)
+    stdout := LineWriter(l, opts.StdoutLevel, append(fields[:len(fields):len(fields)], String("stream", "stdout"))...)
+    stderr := LineWriter(l, opts.StderrLevel, append(fields[:len(fields):len(fields)], String("stream", "stderr"))...)
+    cmd.Stdout, cmd.Stderr = stdout, stderr
+    return func() {
+        stdout.Close()
+        stderr.Close()
+    }
+}
+
+// LineWriter 返回按行写日志的io.Writer：每个完整的行写成一条level级别的记录，带上fields，
+// 行尾的\r\n和空行被去掉。Close写出剩余的不完整行。可以用于任何只接受io.Writer的输出
+func LineWriter(l Interface, level string, fields ...Field) io.WriteCloser {
+    name, ok := normalizeLevel(level)
+    if !ok {
+        name = "INFO"
+    }
+    return &lineWriter{l: l, level: name, fields: fields}
+}
+
+type lineWriter struct {
+    l      Interface
+    level  string
+    fields []Field
+    mu     sync.Mutex
+    buf    []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    w.buf = append(w.buf, p...)
+    for {
+        i := bytes.IndexByte(w.buf, '\n')
+        if i < 0 {
+            if len(w.buf) >= maxCaptureLine {
+                w.emit(w.buf[:maxCaptureLine])
+                w.buf = w.buf[maxCaptureLine:]
+                continue
+            }
+            break
+        }
+        w.emit(w.buf[:i])
+        w.buf = w.buf[i+1:]
+    }
+    // 剩余部分移到开头，避免底层数组只增不减
+    w.buf = append(w.buf[:0:0], w.buf...)
+    return len(p), nil
+}
+
+func (w *lineWriter) Close() error {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    if len(w.buf) > 0 {
+        w.emit(w.buf)
+        w.buf = nil
+    }
+    return nil
+}
+
+func (w *lineWriter) emit(line []byte) {
+    s := strings.TrimRight(string(line), "\r")
+    if strings.TrimSpace(s) == "" {
+        return
+    }
+    switch w.level {
+    case "DEBUG":
+        w.l.DebugFields(s, w.fields...)
+    case "ERROR":
+        w.l.ErrorFields(s, w.fields...)
+    default:
+        w.l.InfoFields(s, w.fields...)
+    }
+}
@@ -0,0 +1,36 @@
+package jLogger
+
+import (
+    "fmt"
+    "os"
+    "time"
+)
+
+// 进程退出函数，测试中替换为不退出的实现
+var exit = os.Exit
+
+// Fatal 写一条带exit_code和fields的Error记录，然后有时限地关闭Logger：排空通道和缓冲区、投递Sink、
+// 执行RegisterOnClose注册的回调（STOP标记的原因为fatal），最后以code退出进程。
+// 关闭总共最多等待两倍的关闭超时（见WithCloseTimeout），超时后不再等待，直接退出。
+// 在句柄上调用时关闭的是共享管道。code为0时按1处理，Fatal不用于正常退出
+func (l *Logger) Fatal(code int, msg string, fields ...Field) {
+    if code == 0 {
+        code = 1
+    }
+    l.ErrorFields(msg, append(fields[:len(fields):len(fields)], Int("exit_code", code))...)
+
+    root := l.pipeline()
+    done := make(chan struct{})
+    go func() {
+        root.closeWith("fatal")
+        close(done)
+    }()
+    timer := time.NewTimer(2 * root.closeTimeout)
+    defer timer.Stop()
+    select {
+    case <-done:
+    case <-timer.C:
+        fmt.Fprintln(os.Stderr, "jLogger: Fatal关闭超时，部分日志可能没有写入")
+    }
+    exit(code)
+}
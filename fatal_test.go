@@ -0,0 +1,109 @@
+package jLogger
+
+import (
+    "context"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+)
+
+// 替换exit，返回记录退出码的指针，测试结束后恢复
+func stubExit(t *testing.T) *int {
+    t.Helper()
+    code := -1
+    exit = func(c int) { code = c }
+    t.Cleanup(func() { exit = os.Exit })
+    return &code
+}
+
+func TestFatalExitCode(t *testing.T) {
+    code := stubExit(t)
+    for _, c := range []struct{ in, want int }{{3, 3}, {0, 1}} {
+        l, err := NewLogger(t.TempDir(), "app", 16, time.Hour, "INFO")
+        if err != nil {
+            t.Fatal(err)
+        }
+        l.Fatal(c.in, "bye")
+        if *code != c.want {
+            t.Errorf("Fatal(%d) 退出码为%d, 期望%d", c.in, *code, c.want)
+        }
+    }
+}
+
+func TestFatalFlushesAndRunsHooks(t *testing.T) {
+    code := stubExit(t)
+    dir := t.TempDir()
+    l, err := NewLogger(dir, "app", 1000, time.Hour, "INFO", WithJSON(), WithLifecycleMarkers("test"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    hooked := false
+    l.RegisterOnClose(func(ctx context.Context) error {
+        hooked = true
+        return nil
+    })
+    // flushInterval为1小时，这些记录只在缓冲区中，依赖Fatal排空
+    for i := 0; i < 100; i++ {
+        l.Info("pending", i)
+    }
+    l.Named("db").Fatal(2, "disk full", String("path", "/data"))
+
+    if *code != 2 {
+        t.Fatalf("退出码为%d, 期望2", *code)
+    }
+    if !hooked {
+        t.Fatal("关闭回调没有执行")
+    }
+    info, err := os.ReadFile(filepath.Join(dir, "app_info.log"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if n := strings.Count(string(info), `"pending`); n != 100 {
+        t.Fatalf("缓冲区中的记录写入了%d条, 期望100条", n)
+    }
+    errs, err := os.ReadFile(filepath.Join(dir, "app_error.log"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    var fatal string
+    for _, line := range strings.Split(string(errs), "\n") {
+        if strings.Contains(line, `"msg":"disk full"`) {
+            fatal = line
+        }
+    }
+    for _, want := range []string{`"exit_code":2`, `"path":"/data"`, `"module":"db"`} {
+        if !strings.Contains(fatal, want) {
+            t.Errorf("Fatal记录缺少 %s: %s", want, fatal)
+        }
+    }
+    if !strings.Contains(string(errs), `"reason":"fatal"`) {
+        t.Error("STOP标记的原因不是fatal")
+    }
+}
+
+func TestFatalHangingHookBounded(t *testing.T) {
+    code := stubExit(t)
+    const timeout = 100 * time.Millisecond
+    l, err := NewLogger(t.TempDir(), "app", 16, time.Hour, "INFO", WithCloseTimeout(timeout))
+    if err != nil {
+        t.Fatal(err)
+    }
+    release := make(chan struct{})
+    defer close(release)
+    l.RegisterOnClose(func(ctx context.Context) error {
+        // 不理会ctx，一直阻塞
+        <-release
+        return nil
+    })
+
+    start := time.Now()
+    l.Fatal(5, "bye")
+    if elapsed := time.Since(start); elapsed > 2*timeout+100*time.Millisecond {
+        t.Fatalf("回调挂起时Fatal等待了%v, 超过两倍关闭超时", elapsed)
+    }
+    if *code != 5 {
+        t.Fatalf("退出码为%d, 期望5", *code)
+    }
+}
@@ -0,0 +1,285 @@
+package jLogger
+
+import (
+    "bytes"
+    "encoding/json"
+    "math"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// Field 结构化字段，和普通参数一起传给Info/Debug/Error即可：
+// log.Info("用户登录", jLogger.Any("uid", uid), jLogger.Any("ip", ip))
+// 热点路径上使用类型化的构造函数和InfoFields等方法，避免反射和interface装箱：
+// log.InfoFields("用户登录", jLogger.Int64("uid", uid), jLogger.String("ip", ip))
+// 文本格式输出为 key=value，JSON格式输出为独立的key
+type Field struct {
+    Key   string
+    Value interface{} // Any构造的值；类型化字段的值保存在下面的字段中
+    kind  fieldKind
+    num   int64
+    str   string
+}
+
+type fieldKind uint8
+
+const (
+    kindAny fieldKind = iota
+    kindString
+    kindInt64
+    kindFloat64
+    kindBool
+    kindDuration
+    kindTime
+    kindGroup // Value为[]Field
+)
+
+// Any 构造一个任意类型值的字段，值的类型注册了编码函数时先转换，见RegisterFieldEncoder
+func Any(key string, value interface{}) Field {
+    return Field{Key: key, Value: encodeValue(value)}
+}
+
+func String(key, value string) Field {
+    return Field{Key: key, kind: kindString, str: value}
+}
+
+func Int(key string, value int) Field {
+    return Field{Key: key, kind: kindInt64, num: int64(value)}
+}
+
+func Int64(key string, value int64) Field {
+    return Field{Key: key, kind: kindInt64, num: value}
+}
+
+func Float64(key string, value float64) Field {
+    return Field{Key: key, kind: kindFloat64, num: int64(math.Float64bits(value))}
+}
+
+func Bool(key string, value bool) Field {
+    var n int64
+    if value {
+        n = 1
+    }
+    return Field{Key: key, kind: kindBool, num: n}
+}
+
+func Duration(key string, value time.Duration) Field {
+    return Field{Key: key, kind: kindDuration, num: int64(value)}
+}
+
+// Time 按日志的时间格式输出，时区保存为*time.Location指针，不产生额外分配
+func Time(key string, value time.Time) Field {
+    return Field{Key: key, kind: kindTime, num: value.UnixNano(), Value: value.Location()}
+}
+
+// Group 把一组字段放在key下，可以嵌套。文本格式输出为 key.sub=value；JSON格式默认同样展开为带点的key，
+// 开启WithNestedGroups时输出为嵌套的对象。没有字段的分组不输出，key为空时字段直接展开到上一层，和slog的规则一致
+func Group(key string, fields ...Field) Field {
+    return Field{Key: key, kind: kindGroup, Value: fields}
+}
+
+// Err 构造key为error的字段，err为nil时输出 <nil>
+func Err(err error) Field {
+    return Field{Key: "error", Value: err}
+}
+
+// Interface 返回字段的值，类型化字段会被还原成对应的Go类型
+func (f Field) Interface() interface{} {
+    switch f.kind {
+    case kindString:
+        return f.str
+    case kindInt64:
+        return f.num
+    case kindFloat64:
+        return math.Float64frombits(uint64(f.num))
+    case kindBool:
+        return f.num == 1
+    case kindDuration:
+        return time.Duration(f.num)
+    case kindTime:
+        return f.time()
+    case kindGroup:
+        fields, _ := f.Value.([]Field)
+        m := make(map[string]interface{}, len(fields))
+        for _, sub := range fields {
+            m[sub.Key] = sub.Interface()
+        }
+        return m
+    }
+    return f.Value
+}
+
+func (f Field) time() time.Time {
+    t := time.Unix(0, f.num)
+    if loc, ok := f.Value.(*time.Location); ok && loc != nil {
+        t = t.In(loc)
+    }
+    return t
+}
+
+// Text 返回字段值的文本形式，和文本格式中 key=value 的value一致（不加引号）
+func (f Field) Text() string {
+    return f.text()
+}
+
+// 字段值的文本形式，类型化字段不经过fmt
+func (f Field) text() string {
+    switch f.kind {
+    case kindString:
+        return f.str
+    case kindInt64:
+        return strconv.FormatInt(f.num, 10)
+    case kindFloat64:
+        return strconv.FormatFloat(math.Float64frombits(uint64(f.num)), 'g', -1, 64)
+    case kindBool:
+        return strconv.FormatBool(f.num == 1)
+    case kindDuration:
+        return time.Duration(f.num).String()
+    case kindTime:
+        return f.time().Format(timeFormat)
+    case kindGroup:
+        fields, _ := f.Value.([]Field)
+        parts := make([]string, 0, len(fields))
+        for _, sub := range flattenGroups(fields) {
+            parts = append(parts, sub.Key+"="+quoteValue(sub.text()))
+        }
+        return "{" + strings.Join(parts, " ") + "}"
+    }
+    return fieldString(f.Value)
+}
+
+// MarshalJSON 输出为 {"key":...,"value":...}
+func (f Field) MarshalJSON() ([]byte, error) {
+    return json.Marshal(struct {
+        Key   string      `json:"key"`
+        Value interface{} `json:"value"`
+    }{f.Key, f.Interface()})
+}
+
+// UnmarshalJSON 解析MarshalJSON的输出，值按encoding/json的规则还原，整数还原为int64
+func (f *Field) UnmarshalJSON(data []byte) error {
+    var kv struct {
+        Key   string      `json:"key"`
+        Value interface{} `json:"value"`
+    }
+    dec := json.NewDecoder(bytes.NewReader(data))
+    dec.UseNumber()
+    if err := dec.Decode(&kv); err != nil {
+        return err
+    }
+    if n, ok := kv.Value.(json.Number); ok {
+        if i, err := n.Int64(); err == nil {
+            kv.Value = i
+        } else if x, err := n.Float64(); err == nil {
+            kv.Value = x
+        }
+    }
+    *f = Any(kv.Key, kv.Value)
+    return nil
+}
+
+// 把参数拆分为普通消息参数和结构化字段，字段保持调用时的顺序
+func splitFields(v []interface{}) ([]interface{}, []Field) {
+    n := 0
+    for _, a := range v {
+        if _, ok := a.(Field); ok {
+            n++
+        }
+    }
+    if n == 0 {
+        return v, nil
+    }
+    args := make([]interface{}, 0, len(v)-n)
+    fields := make([]Field, 0, n)
+    for _, a := range v {
+        if f, ok := a.(Field); ok {
+            fields = append(fields, f)
+        } else {
+            args = append(args, a)
+        }
+    }
+    return args, fields
+}
+
+// 拆分记录的消息参数和字段：混在参数中的字段在前，InfoFields等方法传入的字段在后
+func (m logMessage) split() ([]interface{}, []Field) {
+    args, fields := splitFields(m.msg)
+    if len(m.fields) > 0 {
+        fields = append(fields, m.fields...)
+    }
+    return args, fields
+}
+
+// 确定字段的输出顺序：order中列出的key按给定顺序排在最前，其余按插入顺序；
+// 重复的key只保留最后一次的值，位置取第一次出现的位置，保证同样的输入总是得到同样的输出
+func orderFields(fields []Field, order []string) []Field {
+    if len(fields) == 0 {
+        return fields
+    }
+    index := make(map[string]int, len(fields))
+    out := make([]Field, 0, len(fields))
+    for _, f := range fields {
+        if i, ok := index[f.Key]; ok {
+            out[i] = f
+            continue
+        }
+        index[f.Key] = len(out)
+        out = append(out, f)
+    }
+    if len(order) == 0 {
+        return out
+    }
+
+    sorted := make([]Field, 0, len(out))
+    used := make(map[string]bool, len(order))
+    for _, key := range order {
+        if i, ok := index[key]; ok && !used[key] {
+            sorted = append(sorted, out[i])
+            used[key] = true
+        }
+    }
+    for _, f := range out {
+        if !used[f.Key] {
+            sorted = append(sorted, f)
+        }
+    }
+    return sorted
+}
+
+// 把分组展开为带点的key，没有分组时返回原切片
+func flattenGroups(fields []Field) []Field {
+    grouped := false
+    for _, f := range fields {
+        if f.kind == kindGroup {
+            grouped = true
+            break
+        }
+    }
+    if !grouped {
+        return fields
+    }
+    out := make([]Field, 0, len(fields))
+    for _, f := range fields {
+        out = appendFlattened(out, "", f)
+    }
+    return out
+}
+
+func appendFlattened(out []Field, prefix string, f Field) []Field {
+    key := f.Key
+    if prefix != "" && key != "" {
+        key = prefix + "." + key
+    } else if prefix != "" {
+        key = prefix
+    }
+    if f.kind != kindGroup {
+        f.Key = key
+        return append(out, f)
+    }
+    fields, _ := f.Value.([]Field)
+    for _, sub := range fields {
+        out = appendFlattened(out, key, sub)
+    }
+    return out
+}
@@ -0,0 +1,77 @@
+package jLogger
+
+import (
+    "fmt"
+    "hash/fnv"
+    "regexp"
+    "runtime"
+    "strings"
+)
+
+// 调用栈最多捕获的层数，足够跳过本包内部的调用
+const fingerprintDepth = 8
+
+var (
+    fingerprintUUID   = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+    fingerprintHex    = regexp.MustCompile(`0[xX][0-9a-fA-F]+`)
+    fingerprintNumber = regexp.MustCompile(`\d+`)
+)
+
+// 在生产者中捕获调用栈（只记录pc，解析放到消费协程）
+func captureCallers() []uintptr {
+    pcs := make([]uintptr, fingerprintDepth)
+    n := runtime.Callers(3, pcs)
+    return pcs[:n]
+}
+
+// 计算Error记录的指纹：错误类型 + 归一化后的消息 + 本包之外的第一层调用函数，
+// 消息中的数字、十六进制、UUID被替换为占位符，同一类故障无论参数如何都得到同一个指纹，便于下游聚合
+func (l *Logger) fingerprint(msg logMessage) string {
+    args, fields := msg.split()
+
+    errType := "-"
+    for _, a := range args {
+        if err, ok := a.(error); ok {
+            errType = fmt.Sprintf("%T", err)
+            break
+        }
+    }
+    if errType == "-" {
+        for _, f := range fields {
+            if err, ok := f.Value.(error); ok {
+                errType = fmt.Sprintf("%T", err)
+                break
+            }
+        }
+    }
+
+    text := strings.TrimSpace(fmt.Sprintln(args...))
+    text = fingerprintUUID.ReplaceAllString(text, "<uuid>")
+    text = fingerprintHex.ReplaceAllString(text, "<hex>")
+    text = fingerprintNumber.ReplaceAllString(text, "<n>")
+
+    h := fnv.New64a()
+    h.Write([]byte(errType))
+    h.Write([]byte{0})
+    h.Write([]byte(text))
+    h.Write([]byte{0})
+    h.Write([]byte(callerFunction(msg.callers)))
+    return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// 跳过本包内部的栈帧，返回业务代码中调用日志方法的函数名
+func callerFunction(pcs []uintptr) string {
+    if len(pcs) == 0 {
+        return ""
+    }
+    frames := runtime.CallersFrames(pcs)
+    for {
+        frame, more := frames.Next()
+        if !strings.Contains(frame.Function, "/jLogger.") {
+            return frame.Function
+        }
+        if !more {
+            return frame.Function
+        }
+    }
+}
@@ -0,0 +1,200 @@
+package jLogger
+
+import (
+    "fmt"
+    "log"
+    "strconv"
+    "strings"
+    "sync/atomic"
+    "unicode/utf8"
+)
+
+// 把一条日志编码为写入文件的一行（不含换行），所有输出格式都从这里分发
+func (l *Logger) encode(msg logMessage) string {
+    return l.encodeExtra(msg)
+}
+
+// 同encode，额外附加一些字段
+func (l *Logger) encodeExtra(msg logMessage, extra ...Field) string {
+    fields := append(append(l.recordFields(msg), extra...), msg.fields...)
+    if l.json {
+        return l.encodeJSON(msg, fields)
+    }
+    return l.textHeader(msg) + l.formatArgs(msg.msg, fields...)
+}
+
+// 由Logger自动附加到每条记录上的字段
+func (l *Logger) recordFields(msg logMessage) []Field {
+    var fields []Field
+    if msg.id != "" {
+        fields = append(fields, String("log_id", msg.id))
+    }
+    if msg.module != "" {
+        fields = append(fields, Any("module", msg.module))
+    }
+    if msg.tag != "" {
+        fields = append(fields, Any("tag", msg.tag))
+    }
+    if l.emitSeq {
+        fields = append(fields, Any("seq", msg.seq))
+    }
+    if l.errorFingerprint && msg.level == "ERROR" {
+        fields = append(fields, String("fingerprint", l.fingerprint(msg)))
+    }
+    if l.writeTime {
+        // 编码发生在刷新缓冲区时，和time（事件时间）的差值就是异步管道带来的延迟
+        fields = append(fields, Time("write_time", l.now()))
+    }
+    if atomic.LoadInt32(&l.providerCount) > 0 {
+        fields = append(fields, l.providedFields()...)
+    }
+    return fields
+}
+
+// 不经过通道和缓冲区，按当前输出格式直接写入一条记录，用于管道尚未启动或已经关闭时的内部日志
+func (l *Logger) writeDirect(level string, v ...interface{}) {
+    msg := logMessage{level: level, msg: v, timestamp: l.now(), seq: atomic.AddUint64(&l.seq, 1)}
+    switch level {
+    case "INFO":
+        l.InfoLogger.Println(l.encode(msg))
+    case "DEBUG":
+        l.DebugLogger.Println(l.encode(msg))
+    case "ERROR":
+        l.ErrorLogger.Println(l.encode(msg))
+    }
+}
+
+// 通道已满时在调用方协程中同步写入
+func (l *Logger) writeFallback(logger *log.Logger, msg logMessage) {
+    l.publish(msg)
+    if l.ordered {
+        l.appendOrdered(msg)
+        return
+    }
+    extra := append(l.recordFields(msg), msg.fields...)
+    if l.json {
+        logger.Println(l.encodeJSON(msg, append(extra, Any("fallback", true))))
+        l.observeWrites([]logMessage{msg})
+        l.spoolAck([]logMessage{msg})
+        l.deliver([]logMessage{msg})
+        return
+    }
+    logger.Println("日志通道已满，进入主线程写入日志:", l.formatArgs(msg.msg, extra...))
+    l.observeWrites([]logMessage{msg})
+    l.spoolAck([]logMessage{msg})
+    l.deliver([]logMessage{msg})
+}
+
+// 把日志参数格式化为一行消息内容，结构化字段（含extra）以 key=value 的形式追加在消息后面
+func (l *Logger) formatArgs(v []interface{}, extra ...Field) string {
+    args, fields := splitFields(v)
+    if len(extra) > 0 {
+        fields = append(extra, fields...)
+    }
+    s := strings.TrimSpace(fmt.Sprintln(encodeArgs(args)...))
+    if len(fields) > 0 {
+        fields = flattenGroups(fields)
+        var b strings.Builder
+        b.WriteString(s)
+        for _, f := range orderFields(fields, l.fieldOrder) {
+            if b.Len() > 0 {
+                b.WriteByte(' ')
+            }
+            b.WriteString(f.Key)
+            b.WriteByte('=')
+            b.WriteString(quoteValue(f.text()))
+        }
+        s = b.String()
+    }
+    return l.sanitize(s)
+}
+
+// 转义和截断，文本格式输出的消息内容都必须经过这里；JSON格式只转义，截断对整条记录进行
+func (l *Logger) sanitize(s string) string {
+    s = l.escapeText(s)
+    if l.maxMessageBytes > 0 {
+        s = truncateMessage(s, l.maxMessageBytes)
+    }
+    return s
+}
+
+func (l *Logger) escapeText(s string) string {
+    if l.escape {
+        return escapeControl(s)
+    }
+    return s
+}
+
+func fieldString(v interface{}) string {
+    switch x := v.(type) {
+    case string:
+        return x
+    case error:
+        return x.Error()
+    case fmt.Stringer:
+        return x.String()
+    }
+    return fmt.Sprint(v)
+}
+
+// 值中包含空格、等号或引号时加引号，保证 key=value 可以被无歧义地解析
+func quoteValue(s string) string {
+    if s == "" || strings.ContainsAny(s, " =\"") {
+        return strconv.Quote(s)
+    }
+    return s
+}
+
+// 超过max字节时截断，并追加 "...(truncated, N bytes)"，N为截断前的总字节数
+// 截断位置回退到完整的UTF-8字符边界，避免写出半个中文
+func truncateMessage(s string, max int) string {
+    if len(s) <= max {
+        return s
+    }
+    cut := max
+    for cut > 0 && !utf8.RuneStart(s[cut]) {
+        cut--
+    }
+    return s[:cut] + fmt.Sprintf("...(truncated, %d bytes)", len(s))
+}
+
+// 需要转义的字符：C0/C1控制字符（含ESC，即ANSI序列的起始）、DEL、Unicode行/段分隔符，Tab保留原样
+func shouldEscape(r rune) bool {
+    return (r < 0x20 && r != '\t') || r == 0x7f || (r >= 0x80 && r <= 0x9f) || r == '\u2028' || r == '\u2029'
+}
+
+// 转义换行和控制字符，非法的UTF-8字节也按\x形式输出
+func escapeControl(s string) string {
+    clean := true
+    for _, r := range s {
+        if shouldEscape(r) || r == utf8.RuneError {
+            clean = false
+            break
+        }
+    }
+    if clean {
+        return s
+    }
+
+    var b strings.Builder
+    b.Grow(len(s) + 16)
+    for i := 0; i < len(s); {
+        r, size := utf8.DecodeRuneInString(s[i:])
+        switch {
+        case r == '\n':
+            b.WriteString(`\n`)
+        case r == '\r':
+            b.WriteString(`\r`)
+        case r == utf8.RuneError && size == 1:
+            fmt.Fprintf(&b, `\x%02x`, s[i])
+        case r < 0x80 && shouldEscape(r):
+            fmt.Fprintf(&b, `\x%02x`, r)
+        case shouldEscape(r):
+            fmt.Fprintf(&b, `\u%04x`, r)
+        default:
+            b.WriteString(s[i : i+size])
+        }
+        i += size
+    }
+    return b.String()
+}
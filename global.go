@@ -0,0 +1,136 @@
+package jLogger
+
+import (
+    "sync"
+    "sync/atomic"
+    "time"
+)
+
+// 默认Logger设置之前最多暂存的记录数，超过时丢弃最早的
+const preInitLimit = 1000
+
+// 默认Logger设置之前的一条记录
+type preInitRecord struct {
+    level  string
+    time   time.Time
+    v      []interface{}
+    fields []Field
+}
+
+var (
+    defaultLogger  atomic.Pointer[Logger]
+    preInit        []preInitRecord
+    preInitDropped int
+    preInit_mu     sync.Mutex
+)
+
+// Init 创建Logger（参数同NewLogger）并设为包级别函数使用的默认Logger，见SetDefault
+func Init(logDir, logPrefix string, bufferSize int, flushInterval time.Duration, log_level string, opts ...Option) (*Logger, error) {
+    l, err := NewLogger(logDir, logPrefix, bufferSize, flushInterval, log_level, opts...)
+    if err != nil {
+        return nil, err
+    }
+    SetDefault(l)
+    return l, nil
+}
+
+// SetDefault 设置包级别函数（jLogger.Info等）使用的默认Logger。在此之前调用的包级别函数暂存在内存中（最多1000条），
+// 设置时按原来的时间和顺序回放到l，带preinit=true字段，按l的级别过滤；启动早期的诊断日志不会丢失，也不会另外打到stderr。
+// 暂存溢出时回放后再写一条Error记录说明丢弃的条数。回放完成之后才发布l，期间其他协程的包级别调用等待回放，
+// 保证暂存的记录排在设置之后的记录前面。传nil时恢复为暂存
+func SetDefault(l *Logger) {
+    preInit_mu.Lock()
+    defer preInit_mu.Unlock()
+    if l == nil {
+        defaultLogger.Store(nil)
+        return
+    }
+    records, dropped := preInit, preInitDropped
+    preInit, preInitDropped = nil, 0
+
+    for _, r := range records {
+        if !l.enabled(r.level) {
+            continue
+        }
+        logger, _, _ := l.levelOutput(r.level)
+        fields := append(append(make([]Field, 0, len(r.fields)+1), r.fields...), Bool("preinit", true))
+        l.pipeline().enqueue(logger, l.newMessage(r.level, r.time, r.v, fields...))
+    }
+    if dropped > 0 {
+        l.ErrorFields("默认Logger设置之前的日志超过暂存上限，已丢弃最早的记录", Int("dropped", dropped))
+    }
+    defaultLogger.Store(l)
+}
+
+// Default 返回默认Logger，还没有设置时返回nil
+func Default() *Logger {
+    return defaultLogger.Load()
+}
+
+// 默认Logger已设置时返回它；否则暂存这条记录并返回nil
+func deferToDefault(level string, v []interface{}, fields []Field) *Logger {
+    if l := defaultLogger.Load(); l != nil {
+        return l
+    }
+    preInit_mu.Lock()
+    defer preInit_mu.Unlock()
+    // 加锁后再检查一次，SetDefault可能刚刚完成
+    if l := defaultLogger.Load(); l != nil {
+        return l
+    }
+    if len(preInit) >= preInitLimit {
+        preInit[0] = preInitRecord{}
+        preInit = preInit[1:]
+        preInitDropped++
+    }
+    preInit = append(preInit, preInitRecord{level: level, time: time.Now(), v: v, fields: fields})
+    return nil
+}
+
+// Info 写入默认Logger，见SetDefault
+func Info(v ...interface{}) {
+    if l := deferToDefault("INFO", v, nil); l != nil {
+        l.Info(v...)
+    }
+}
+
+// Debug 写入默认Logger，见SetDefault
+func Debug(v ...interface{}) {
+    if !DebugEnabled {
+        return
+    }
+    if l := deferToDefault("DEBUG", v, nil); l != nil {
+        l.Debug(v...)
+    }
+}
+
+// Error 写入默认Logger，见SetDefault
+func Error(v ...interface{}) {
+    if l := deferToDefault("ERROR", v, nil); l != nil {
+        l.Error(v...)
+    }
+}
+
+// InfoFields 写入默认Logger，见SetDefault
+func InfoFields(msg string, fields ...Field) {
+    if l := deferToDefault("INFO", []interface{}{msg}, fields); l != nil {
+        l.InfoFields(msg, fields...)
+    }
+}
+
+// DebugFields 写入默认Logger，见SetDefault
+func DebugFields(msg string, fields ...Field) {
+    if !DebugEnabled {
+        return
+    }
+    if l := deferToDefault("DEBUG", []interface{}{msg}, fields); l != nil {
+        l.DebugFields(msg, fields...)
+    }
+}
+
+// ErrorFields 写入默认Logger，见SetDefault
+func ErrorFields(msg string, fields ...Field) {
+    if l := deferToDefault("ERROR", []interface{}{msg}, fields); l != nil {
+        l.ErrorFields(msg, fields...)
+    }
+}
@@ -0,0 +1,64 @@
+package jLogger
+
+import (
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+)
+
+func TestSetDefaultReplaysBeforePublishing(t *testing.T) {
+    SetDefault(nil)
+    defer SetDefault(nil)
+    // 不超过暂存上限，并发写入的记录进入暂存时也不会挤掉early
+    const early = preInitLimit / 2
+    for i := 0; i < early; i++ {
+        InfoFields("early")
+    }
+
+    dir := t.TempDir()
+    l, err := NewLogger(dir, "app", 4096, time.Hour, "INFO", WithSequence(true))
+    if err != nil {
+        t.Fatal(err)
+    }
+    // 和SetDefault并发写入的记录必须排在回放的记录之后
+    var wg sync.WaitGroup
+    start := make(chan struct{})
+    for g := 0; g < 4; g++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            <-start
+            for i := 0; i < 100; i++ {
+                InfoFields("late")
+            }
+        }()
+    }
+    close(start)
+    SetDefault(l)
+    wg.Wait()
+    l.Close()
+
+    data, err := os.ReadFile(filepath.Join(dir, "app_info.log"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    gotEarly, late, sawLate := 0, 0, false
+    for _, line := range strings.Split(string(data), "\n") {
+        switch {
+        case strings.Contains(line, "early"):
+            if sawLate {
+                t.Fatalf("回放的记录排在了设置之后的记录后面: %s", line)
+            }
+            gotEarly++
+        case strings.Contains(line, "late"):
+            sawLate = true
+            late++
+        }
+    }
+    if gotEarly != early || late != 400 {
+        t.Fatalf("写入early %d条、late %d条, 期望%d条和400条", gotEarly, late, early)
+    }
+}
@@ -0,0 +1,116 @@
+// Package grpcstream 基于gRPC实现 proto/jlogger/v1/logstream.proto 中的LogStream服务，
+// 远程调试工具可以订阅运行中服务的实时日志、查询历史日志。单独作为一个模块，使用jLogger不会引入grpc依赖：
+//
+//    s := grpc.NewServer(grpc.Creds(creds))
+//    grpcstream.Register(s, log)
+//    s.Serve(lis)
+//
+// 服务没有自带鉴权，需要通过TLS客户端证书或拦截器自行做访问控制
+package grpcstream
+
+//go:generate protoc -I ../proto --go_out=. --go_opt=module=github.com/johnsonperl/jLogger/grpcstream --go-grpc_out=. --go-grpc_opt=module=github.com/johnsonperl/jLogger/grpcstream jlogger/v1/logstream.proto
+
+import (
+    "github.com/johnsonperl/jLogger"
+    "github.com/johnsonperl/jLogger/grpcstream/jloggerv1"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+    "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Stream订阅的通道容量，客户端处理不过来时多出的记录被丢弃，不会拖慢日志管道
+const streamBuffer = 256
+
+// Server LogStream服务的实现
+type Server struct {
+    jloggerv1.UnimplementedLogStreamServer
+    log *jLogger.Logger
+}
+
+// NewServer 创建读取log的实时日志和历史文件的服务
+func NewServer(log *jLogger.Logger) *Server {
+    return &Server{log: log}
+}
+
+// Register 创建服务并注册到s上
+func Register(s grpc.ServiceRegistrar, log *jLogger.Logger) *Server {
+    srv := NewServer(log)
+    jloggerv1.RegisterLogStreamServer(s, srv)
+    return srv
+}
+
+// Stream 推送新产生的日志，直到客户端断开
+func (s *Server) Stream(req *jloggerv1.StreamRequest, stream jloggerv1.LogStream_StreamServer) error {
+    records, cancel := s.log.Subscribe(streamBuffer, jLogger.LevelFilter(req.GetMinLevel(), req.GetModule()))
+    defer cancel()
+
+    ctx := stream.Context()
+    for {
+        select {
+        case <-ctx.Done():
+            return nil
+        case r, ok := <-records:
+            if !ok {
+                return nil
+            }
+            if err := stream.Send(toProto(r)); err != nil {
+                return err
+            }
+        }
+    }
+}
+
+// Query 按条件查询当前文件和轮转归档中的历史日志，limit为0表示不限条数
+func (s *Server) Query(req *jloggerv1.QueryRequest, stream jloggerv1.LogStream_QueryServer) error {
+    opts := jLogger.QueryOptions{
+        Level:    req.GetLevel(),
+        Contains: req.GetContains(),
+        Fields:   req.GetFields(),
+    }
+    if req.GetFrom() != nil {
+        opts.From = req.GetFrom().AsTime()
+    }
+    if req.GetTo() != nil {
+        opts.To = req.GetTo().AsTime()
+    }
+    it := s.log.Query(opts)
+    defer it.Close()
+
+    ctx := stream.Context()
+    limit := int(req.GetLimit())
+    for n := 0; limit == 0 || n < limit; n++ {
+        if !it.Next() {
+            break
+        }
+        if err := ctx.Err(); err != nil {
+            return status.FromContextError(err).Err()
+        }
+        if err := stream.Send(toProto(it.Record())); err != nil {
+            return err
+        }
+    }
+    if err := it.Err(); err != nil {
+        return status.Error(codes.Internal, err.Error())
+    }
+    return nil
+}
+
+func toProto(r jLogger.Record) *jloggerv1.LogRecord {
+    rec := &jloggerv1.LogRecord{
+        Time:    timestamppb.New(r.Time),
+        Level:   r.Level,
+        Module:  r.Module,
+        Seq:     r.Seq,
+        Message: r.Message,
+        Tag:     r.Tag,
+        LogId:   r.LogID,
+    }
+    if len(r.Fields) > 0 {
+        rec.Fields = make([]*jloggerv1.Field, len(r.Fields))
+        for i, f := range r.Fields {
+            rec.Fields[i] = &jloggerv1.Field{Key: f.Key, Value: f.Text()}
+        }
+    }
+    return rec
+}
@@ -0,0 +1,35 @@
+package jLogger
+
+import (
+    "log"
+    "sync/atomic"
+    "time"
+)
+
+// 默认的心跳间隔
+const defaultHeartbeatInterval = 5 * time.Minute
+
+// 定期向每个日志文件写一条心跳记录，不受级别过滤影响。
+// 文件长时间没有心跳说明日志管道出了问题，只有心跳说明服务空闲。心跳只写文件，不推送给订阅者和Sink
+func (l *Logger) runHeartbeat() {
+    defer l.emitters.Done()
+    ticker := time.NewTicker(l.heartbeatInterval)
+    defer ticker.Stop()
+    for {
+        select {
+        case <-l.done:
+            return
+        case <-ticker.C:
+        }
+        now := time.Now()
+        uptime := now.Sub(l.started).Truncate(time.Second)
+        for i, logger := range []*log.Logger{l.InfoLogger, l.DebugLogger, l.ErrorLogger} {
+            msg := l.newMessage(levelNames[i], now, []interface{}{"alive"},
+                Duration("uptime", uptime),
+                Int64("dropped", int64(atomic.LoadUint64(&l.dropCounts[i]))),
+            )
+            msg.internal, msg.fileOnly = true, true
+            l.enqueue(logger, msg)
+        }
+    }
+}
@@ -0,0 +1,40 @@
+package jLogger
+
+import (
+    "encoding/hex"
+    "fmt"
+)
+
+// HexDump的默认上限，max <= 0 时使用，避免误传整个大包把日志撑爆
+const defaultHexDumpMax = 4096
+
+// 延迟格式化的十六进制dump，实现fmt.Stringer
+// 生产者只复制数据，真正的格式化在写缓冲区的消费协程中通过String()完成，不占用主线程
+type hexDump struct {
+    data  []byte
+    total int // 原始数据长度，用于提示被截掉的字节数
+}
+
+// HexDump 生成一个有上限的 十六进制+ASCII dump，用于协议调试，例如：
+// log.Debug("recv packet", jLogger.HexDump(buf, 256))
+// 只复制前max个字节（调用方之后复用buf也不影响日志内容），格式化延迟到消费协程中执行
+func HexDump(b []byte, max int) fmt.Stringer {
+    if max <= 0 {
+        max = defaultHexDumpMax
+    }
+    n := len(b)
+    if n > max {
+        n = max
+    }
+    data := make([]byte, n)
+    copy(data, b[:n])
+    return hexDump{data: data, total: len(b)}
+}
+
+func (h hexDump) String() string {
+    s := fmt.Sprintf("(%d bytes)\n%s", h.total, hex.Dump(h.data))
+    if omitted := h.total - len(h.data); omitted > 0 {
+        s += fmt.Sprintf("... (省略 %d bytes)", omitted)
+    }
+    return s
+}
\ No newline at end of file
@@ -0,0 +1,57 @@
+package jLogger
+
+import (
+    "context"
+    "time"
+)
+
+// 关闭回调的默认总超时
+const defaultCloseTimeout = 5 * time.Second
+
+// RegisterOnClose 注册在Close最终刷新之后执行的回调（例如上传最后一个归档、POST一条下线标记），
+// 按注册顺序依次执行，所有回调共享一个总超时（默认5秒，可通过WithCloseTimeout调整）。
+// 每个回调最多使用剩余时间按剩余回调数平分的一份，ctx在这份时间用完后取消；超时仍未返回的回调不再等待，
+// 继续执行下一个，一个回调挂起不会让后面的回调被跳过。回调返回的错误、超时和未执行的回调都写入Error日志文件
+func (l *Logger) RegisterOnClose(fn func(ctx context.Context) error) {
+    l = l.pipeline()
+    l.hooks_mu.Lock()
+    l.closeHooks = append(l.closeHooks, fn)
+    l.hooks_mu.Unlock()
+}
+
+func (l *Logger) runCloseHooks() {
+    l.hooks_mu.Lock()
+    hooks := append([]func(ctx context.Context) error(nil), l.closeHooks...)
+    l.hooks_mu.Unlock()
+    if len(hooks) == 0 {
+        return
+    }
+
+    deadline := time.Now().Add(l.closeTimeout)
+    for i, hook := range hooks {
+        remaining := time.Until(deadline)
+        if remaining <= 0 {
+            l.internalError("关闭超时，关闭回调未执行:", i)
+            continue
+        }
+        l.runCloseHook(i, hook, remaining/time.Duration(len(hooks)-i))
+    }
+}
+
+// 执行一个回调，最多等待timeout
+func (l *Logger) runCloseHook(i int, hook func(ctx context.Context) error, timeout time.Duration) {
+    ctx, cancel := context.WithTimeout(context.Background(), timeout)
+    defer cancel()
+    done := make(chan error, 1)
+    go func() {
+        done <- hook(ctx)
+    }()
+    select {
+    case err := <-done:
+        if err != nil {
+            l.internalError("关闭回调执行失败:", i, err)
+        }
+    case <-ctx.Done():
+        l.internalError("关闭回调执行超时:", i, timeout)
+    }
+}
@@ -0,0 +1,50 @@
+package jLogger
+
+import (
+    "context"
+    "strings"
+    "sync/atomic"
+    "testing"
+    "time"
+)
+
+func TestCloseHooksContinueAfterTimeout(t *testing.T) {
+    const timeout = 300 * time.Millisecond
+    l, err := NewLogger(t.TempDir(), "app", 16, 10*time.Millisecond, "INFO", WithCloseTimeout(timeout))
+    if err != nil {
+        t.Fatal(err)
+    }
+    release := make(chan struct{})
+    defer close(release)
+    var ran int32
+    l.RegisterOnClose(func(ctx context.Context) error {
+        <-release
+        return nil
+    })
+    for i := 0; i < 2; i++ {
+        l.RegisterOnClose(func(ctx context.Context) error {
+            atomic.AddInt32(&ran, 1)
+            return nil
+        })
+    }
+
+    start := time.Now()
+    l.Close()
+    elapsed := time.Since(start)
+    if n := atomic.LoadInt32(&ran); n != 2 {
+        t.Fatalf("挂起的回调之后执行了%d个回调, 期望2个", n)
+    }
+    // 第一个回调只能使用总超时的三分之一
+    if elapsed > timeout/3+100*time.Millisecond {
+        t.Fatalf("Close等待了%v, 挂起的回调超出了它的份额", elapsed)
+    }
+    reported := false
+    for _, e := range l.Stats().RecentErrors {
+        if strings.Contains(e.Message, "关闭回调执行超时") {
+            reported = true
+        }
+    }
+    if !reported {
+        t.Fatal("超时的回调没有报告")
+    }
+}
@@ -0,0 +1,53 @@
+package jLogger
+
+import (
+    "crypto/rand"
+    "encoding/binary"
+    "encoding/hex"
+    "time"
+)
+
+// IDGenerator 为每条记录生成唯一ID，在调用日志方法的协程中调用，必须可以并发使用
+type IDGenerator func() string
+
+// ULID的Crockford Base32字母表
+const ulidAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewULID 生成ULID（26个字符）：前48位为毫秒时间戳，后80位随机，按字符串排序即按时间排序
+func NewULID() string {
+    var b [16]byte
+    binary.BigEndian.PutUint64(b[:8], uint64(time.Now().UnixMilli())<<16)
+    rand.Read(b[6:])
+
+    // 128位按5位一组从高位编码，第一个字符只有3位
+    var out [26]byte
+    hi := binary.BigEndian.Uint64(b[:8])
+    lo := binary.BigEndian.Uint64(b[8:])
+    for i := 25; i >= 0; i-- {
+        out[i] = ulidAlphabet[lo&0x1f]
+        lo = lo>>5 | hi<<59
+        hi >>= 5
+    }
+    return string(out[:])
+}
+
+// NewUUIDv7 生成RFC 9562的UUIDv7：前48位为毫秒时间戳，其余为版本、变体和随机数
+func NewUUIDv7() string {
+    var b [16]byte
+    binary.BigEndian.PutUint64(b[:8], uint64(time.Now().UnixMilli())<<16)
+    rand.Read(b[6:])
+    b[6] = b[6]&0x0f | 0x70
+    b[8] = b[8]&0x3f | 0x80
+
+    var out [36]byte
+    hex.Encode(out[0:8], b[0:4])
+    out[8] = '-'
+    hex.Encode(out[9:13], b[4:6])
+    out[13] = '-'
+    hex.Encode(out[14:18], b[6:8])
+    out[18] = '-'
+    hex.Encode(out[19:23], b[8:10])
+    out[23] = '-'
+    hex.Encode(out[24:], b[10:])
+    return string(out[:])
+}
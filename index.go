@@ -0,0 +1,207 @@
+package jLogger
+
+import (
+    "bufio"
+    "compress/gzip"
+    "encoding/json"
+    "io"
+    "os"
+    "sort"
+    "strings"
+    "time"
+)
+
+// 默认每隔多少条记录保存一个偏移标记
+const defaultIndexEvery = 1000
+
+// 索引文件后缀，放在日志文件旁边
+const indexSuffix = ".idx"
+
+// FileIndex 单个日志文件的时间范围索引，查询时可以跳过不相关的文件，或二分定位到起始偏移，不必扫描整个归档
+type FileIndex struct {
+    Size  int64       `json:"size"`  // 建索引时的文件大小，不一致说明索引已过期
+    First time.Time   `json:"first"` // 第一条带时间戳的记录
+    Last  time.Time   `json:"last"`
+    Count int         `json:"count"` // 记录（行）数
+    Marks []IndexMark `json:"marks"` // 每隔N条记录一个标记；.gz文件的偏移为解压后的偏移
+    Bloom *Bloom      `json:"bloom"` // 文件中出现过的词
+}
+
+// IndexMark 某条记录的起始偏移和时间
+type IndexMark struct {
+    Offset int64     `json:"offset"`
+    Time   time.Time `json:"time"`
+}
+
+// BuildIndex 扫描日志文件建立索引并写入旁边的 .idx 文件，every为标记间隔，<=0时使用默认值1000
+func BuildIndex(path string, every int) (*FileIndex, error) {
+    if every <= 0 {
+        every = defaultIndexEvery
+    }
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+    info, err := f.Stat()
+    if err != nil {
+        return nil, err
+    }
+
+    var r io.Reader = f
+    if strings.HasSuffix(path, ".gz") {
+        zr, err := gzip.NewReader(f)
+        if err != nil {
+            return nil, err
+        }
+        defer zr.Close()
+        r = zr
+    }
+
+    idx := &FileIndex{Size: info.Size()}
+    tokens := make(map[string]struct{})
+    br := bufio.NewReaderSize(r, 64*1024)
+    var offset int64
+    for {
+        line, err := br.ReadString('\n')
+        if len(line) > 0 {
+            for _, token := range tokenize(line) {
+                tokens[token] = struct{}{}
+            }
+            if t, ok := parseLineTime(line); ok {
+                if idx.First.IsZero() {
+                    idx.First = t
+                }
+                idx.Last = t
+                if idx.Count%every == 0 {
+                    idx.Marks = append(idx.Marks, IndexMark{Offset: offset, Time: t})
+                }
+            }
+            idx.Count++
+            offset += int64(len(line))
+        }
+        if err == io.EOF {
+            break
+        }
+        if err != nil {
+            return nil, err
+        }
+    }
+
+    idx.Bloom = newBloom(len(tokens))
+    for token := range tokens {
+        idx.Bloom.add(token)
+    }
+
+    data, err := json.Marshal(idx)
+    if err != nil {
+        return nil, err
+    }
+    return idx, os.WriteFile(path+indexSuffix, data, 0644)
+}
+
+// LoadIndex 读取日志文件的索引，索引不存在或已过期（文件大小变化）时重新建立
+func LoadIndex(path string, every int) (*FileIndex, error) {
+    info, err := os.Stat(path)
+    if err != nil {
+        return nil, err
+    }
+    if data, err := os.ReadFile(path + indexSuffix); err == nil {
+        var idx FileIndex
+        if json.Unmarshal(data, &idx) == nil && idx.Size == info.Size() {
+            return &idx, nil
+        }
+    }
+    return BuildIndex(path, every)
+}
+
+// 只读取已有且未过期的索引，不建立也不写入 .idx 文件，供查询和搜索使用：
+// 索引由WithIndex的后台任务或显式调用BuildIndex、LoadIndex维护
+func cachedIndex(path string) (*FileIndex, bool) {
+    info, err := os.Stat(path)
+    if err != nil {
+        return nil, false
+    }
+    data, err := os.ReadFile(path + indexSuffix)
+    if err != nil {
+        return nil, false
+    }
+    var idx FileIndex
+    if json.Unmarshal(data, &idx) != nil || idx.Size != info.Size() || idx.Bloom == nil {
+        return nil, false
+    }
+    return &idx, true
+}
+
+// Overlaps 文件中的记录时间范围是否和[from, to]有交集，零值表示不限
+func (idx *FileIndex) Overlaps(from, to time.Time) bool {
+    if idx.Count == 0 || idx.First.IsZero() {
+        return true
+    }
+    if !from.IsZero() && idx.Last.Before(from) {
+        return false
+    }
+    if !to.IsZero() && idx.First.After(to) {
+        return false
+    }
+    return true
+}
+
+// Seek 返回可以开始读取的偏移：时间不晚于from的最后一个标记，之前的记录都早于from可以直接跳过
+func (idx *FileIndex) Seek(from time.Time) int64 {
+    if from.IsZero() || len(idx.Marks) == 0 {
+        return 0
+    }
+    i := sort.Search(len(idx.Marks), func(i int) bool { return !idx.Marks[i].Time.Before(from) })
+    if i == 0 {
+        return 0
+    }
+    return idx.Marks[i-1].Offset
+}
+
+// 解析一行日志的时间戳，支持文本格式（"INFO: 2006-01-02 15:04:05.000 ..."）和JSON格式（"time"字段）
+func parseLineTime(line string) (time.Time, bool) {
+    if strings.HasPrefix(line, "{") {
+        var obj struct {
+            Time string `json:"time"`
+        }
+        if json.Unmarshal([]byte(line), &obj) != nil {
+            return time.Time{}, false
+        }
+        t, err := time.ParseInLocation(timeFormat, obj.Time, time.Local)
+        return t, err == nil
+    }
+    // 跳过 log.Logger 的级别前缀
+    if i := strings.Index(line, ": "); i >= 0 && i < 16 {
+        line = line[i+2:]
+    }
+    if len(line) < len(timeFormat) {
+        return time.Time{}, false
+    }
+    t, err := time.ParseInLocation(timeFormat, line[:len(timeFormat)], time.Local)
+    return t, err == nil
+}
+
+// 后台为轮转出的归档建立索引，当前文件仍在写入，不建索引
+func (l *Logger) runIndexer() {
+    ticker := time.NewTicker(time.Minute)
+    defer ticker.Stop()
+    for {
+        files, err := l.listLogFiles()
+        if err == nil {
+            for _, f := range files {
+                if f.current {
+                    continue
+                }
+                if _, err := LoadIndex(f.path, l.indexEvery); err != nil {
+                    l.internalError("建立日志索引失败:", f.path, err)
+                }
+            }
+        }
+        select {
+        case <-l.done:
+            return
+        case <-ticker.C:
+        }
+    }
+}
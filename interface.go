@@ -0,0 +1,21 @@
+package jLogger
+
+// Interface 写日志的方法集合。业务代码依赖这个接口而不是*Logger，单元测试中可以换成
+// jloggermock.Logger，不需要日志文件和后台协程就能断言写了哪些日志。
+// 创建句柄（Named、CloneWith）和运行时管理的方法不在接口中，仍然通过*Logger调用。
+// 包内的集成（StartTimer以及中间件、适配器）都接受Interface，用户自己包装的Logger也能直接使用
+type Interface interface {
+    Info(v ...interface{})
+    Debug(v ...interface{})
+    Error(v ...interface{})
+    InfoFields(msg string, fields ...Field)
+    DebugFields(msg string, fields ...Field)
+    ErrorFields(msg string, fields ...Field)
+    Count(name string, delta int64, fields ...Field)
+    Gauge(name string, value float64, fields ...Field)
+    SetLevel(level string)
+    Flush()
+    Close()
+}
+
+var _ Interface = (*Logger)(nil)
@@ -0,0 +1,156 @@
+// jloggermock 提供jLogger.Interface的内存实现，用于在单元测试中断言日志调用
+package jloggermock
+
+import (
+    "fmt"
+    "strings"
+    "sync"
+
+    "github.com/johnsonperl/jLogger"
+)
+
+// Call 一次方法调用
+type Call struct {
+    Method string        // 方法名，如 "Info"、"ErrorFields"、"Count"
+    Level  string        // 写日志的方法对应的级别，其余方法为空
+    Args   []interface{} // Info/Debug/Error的参数；*Fields方法为消息文本；Count/Gauge为名称和数值
+    Fields []jLogger.Field
+}
+
+// Message 按Logger的文本格式拼出的消息内容（不含字段）
+func (c Call) Message() string {
+    return strings.TrimSpace(fmt.Sprintln(c.Args...))
+}
+
+// Field 按key取字段，不存在时ok为false
+func (c Call) Field(key string) (jLogger.Field, bool) {
+    for _, f := range c.Fields {
+        if f.Key == key {
+            return f, true
+        }
+    }
+    return jLogger.Field{}, false
+}
+
+// Logger 记录所有调用的jLogger.Interface实现，并发安全，零值可以直接使用
+type Logger struct {
+    mu     sync.Mutex
+    calls  []Call
+    level  string
+    closed bool
+}
+
+var _ jLogger.Interface = (*Logger)(nil)
+
+// New 创建一个Logger
+func New() *Logger {
+    return &Logger{}
+}
+
+func (m *Logger) record(c Call) {
+    m.mu.Lock()
+    m.calls = append(m.calls, c)
+    m.mu.Unlock()
+}
+
+// 参数中的jLogger.Field拆分出来，和Logger的处理方式一致
+func split(method, level string, v []interface{}) Call {
+    c := Call{Method: method, Level: level}
+    for _, a := range v {
+        if f, ok := a.(jLogger.Field); ok {
+            c.Fields = append(c.Fields, f)
+        } else {
+            c.Args = append(c.Args, a)
+        }
+    }
+    return c
+}
+
+func (m *Logger) Info(v ...interface{})  { m.record(split("Info", "INFO", v)) }
+func (m *Logger) Debug(v ...interface{}) { m.record(split("Debug", "DEBUG", v)) }
+func (m *Logger) Error(v ...interface{}) { m.record(split("Error", "ERROR", v)) }
+
+func (m *Logger) InfoFields(msg string, fields ...jLogger.Field) {
+    m.record(Call{Method: "InfoFields", Level: "INFO", Args: []interface{}{msg}, Fields: fields})
+}
+
+func (m *Logger) DebugFields(msg string, fields ...jLogger.Field) {
+    m.record(Call{Method: "DebugFields", Level: "DEBUG", Args: []interface{}{msg}, Fields: fields})
+}
+
+func (m *Logger) ErrorFields(msg string, fields ...jLogger.Field) {
+    m.record(Call{Method: "ErrorFields", Level: "ERROR", Args: []interface{}{msg}, Fields: fields})
+}
+
+func (m *Logger) Count(name string, delta int64, fields ...jLogger.Field) {
+    m.record(Call{Method: "Count", Args: []interface{}{name, delta}, Fields: fields})
+}
+
+func (m *Logger) Gauge(name string, value float64, fields ...jLogger.Field) {
+    m.record(Call{Method: "Gauge", Args: []interface{}{name, value}, Fields: fields})
+}
+
+func (m *Logger) SetLevel(level string) {
+    m.mu.Lock()
+    m.level = level
+    m.mu.Unlock()
+    m.record(Call{Method: "SetLevel", Args: []interface{}{level}})
+}
+
+func (m *Logger) Flush() { m.record(Call{Method: "Flush"}) }
+
+func (m *Logger) Close() {
+    m.mu.Lock()
+    m.closed = true
+    m.mu.Unlock()
+    m.record(Call{Method: "Close"})
+}
+
+// Calls 返回到目前为止的所有调用
+func (m *Logger) Calls() []Call {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    return append([]Call(nil), m.calls...)
+}
+
+// Records 返回某个级别的所有日志调用，level为空时返回全部日志调用
+func (m *Logger) Records(level string) []Call {
+    var out []Call
+    for _, c := range m.Calls() {
+        if c.Level != "" && (level == "" || c.Level == level) {
+            out = append(out, c)
+        }
+    }
+    return out
+}
+
+// Contains 是否有某个级别的日志消息包含substr，level为空时不限级别
+func (m *Logger) Contains(level, substr string) bool {
+    for _, c := range m.Records(level) {
+        if strings.Contains(c.Message(), substr) {
+            return true
+        }
+    }
+    return false
+}
+
+// Level 最后一次SetLevel设置的级别
+func (m *Logger) Level() string {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    return m.level
+}
+
+// Closed 是否调用过Close
+func (m *Logger) Closed() bool {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    return m.closed
+}
+
+// Reset 清空记录的调用
+func (m *Logger) Reset() {
+    m.mu.Lock()
+    m.calls = nil
+    m.mu.Unlock()
+}
@@ -0,0 +1,276 @@
+package jLogger
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "math"
+    "strconv"
+    "strings"
+    "unicode/utf8"
+)
+
+// JSON编码：固定以 time、level、msg、v（格式版本）开头，随后是结构化字段
+// 字段顺序由orderFields决定，同样的输入总是得到字节级一致的输出，方便做diff测试和对接严格的解析器
+// extra中已包含msg.fields
+func (l *Logger) encodeJSON(msg logMessage, extra []Field) string {
+    args, fields := splitFields(msg.msg)
+    if len(extra) > 0 {
+        fields = append(extra, fields...)
+    }
+    text := l.escapeText(strings.TrimSpace(fmt.Sprintln(encodeArgs(args)...)))
+    if l.nestedGroups {
+        fields = inlineGroups(fields)
+    } else {
+        fields = flattenGroups(fields)
+    }
+
+    // 字段先编码到body，ends[i]为前i+1个字段编码后的长度，截断时按字段整体保留或丢弃
+    var body bytes.Buffer
+    var ends []int
+    for _, f := range orderFields(renameReserved(fields), l.fieldOrder) {
+        body.WriteByte(',')
+        writeJSONString(&body, f.Key)
+        body.WriteByte(':')
+        l.writeJSONField(&body, f)
+        ends = append(ends, body.Len())
+    }
+    out := l.jsonRecord(msg, text, body.Bytes(), "")
+    if l.maxMessageBytes <= 0 || len(out) <= l.maxMessageBytes {
+        return out
+    }
+    return l.truncateJSON(msg, text, body.Bytes(), ends, len(out))
+}
+
+func (l *Logger) jsonRecord(msg logMessage, text string, body []byte, tail string) string {
+    var b bytes.Buffer
+    b.WriteString(`{"time":`)
+    writeJSONString(&b, msg.timestamp.Format(timeFormat))
+    b.WriteString(`,"level":`)
+    writeJSONString(&b, l.levelLabel(msg.level))
+    b.WriteString(`,"msg":`)
+    writeJSONString(&b, text)
+    b.WriteString(`,"` + schemaKey + `":`)
+    b.WriteString(strconv.Itoa(SchemaVersion))
+    b.Write(body)
+    b.WriteString(tail)
+    b.WriteByte('}')
+    return b.String()
+}
+
+// 整条记录编码后超过maxMessageBytes时重新组织：msg最多先占一半空间，剩下的按顺序保留放得下的字段，
+// 放不下的字段整个丢弃，最后用剩余空间截断msg，并附加truncated字段记录截断前的字节数，输出仍是合法的JSON。
+// 上限小到连固定部分都放不下时，只保证截断后尽量短
+func (l *Logger) truncateJSON(msg logMessage, text string, body []byte, ends []int, size int) string {
+    tail := `,"` + truncatedKey + `":` + strconv.Itoa(size)
+    budget := l.maxMessageBytes - len(l.jsonRecord(msg, "", nil, tail)) // msg的文本和字段可用的字节数
+
+    msgShare := jsonStringLen(text) - 2
+    if msgShare > budget/2 {
+        msgShare = budget / 2
+    }
+    keep := 0
+    for keep < len(ends) && ends[keep] <= budget-msgShare {
+        keep++
+    }
+    fieldsLen := 0
+    if keep > 0 {
+        fieldsLen = ends[keep-1]
+    }
+
+    // 转义会让编码后的长度大于原文，超出多少就再少保留多少，直到放得下
+    room := budget - fieldsLen - 2
+    cut := text
+    for n := room; ; {
+        cut = truncateMessage(text, n)
+        over := jsonStringLen(cut) - 2 - room
+        if over <= 0 || n <= 0 {
+            break
+        }
+        n -= over
+        if n < 0 {
+            n = 0
+        }
+    }
+    return l.jsonRecord(msg, cut, body[:fieldsLen], tail)
+}
+
+// 字符串按writeJSONString编码后的字节数，含两端的引号
+func jsonStringLen(s string) int {
+    var b bytes.Buffer
+    writeJSONString(&b, s)
+    return b.Len()
+}
+
+// 记录超过WithMaxMessageBytes被截断时附加的字段，值为截断前编码后的字节数
+const truncatedKey = "truncated"
+
+// 固定输出的key，和它们同名的用户字段改名为 fields.<key>，避免同一个对象里出现重复的key
+var reservedJSONKeys = map[string]bool{"time": true, "level": true, "msg": true, schemaKey: true, truncatedKey: true}
+
+// 返回改名后的字段，没有冲突时返回原切片，不复制
+func renameReserved(fields []Field) []Field {
+    var out []Field
+    for i, f := range fields {
+        if !reservedJSONKeys[f.Key] {
+            if out != nil {
+                out = append(out, f)
+            }
+            continue
+        }
+        if out == nil {
+            out = append(make([]Field, 0, len(fields)), fields[:i]...)
+        }
+        f.Key = "fields." + f.Key
+        out = append(out, f)
+    }
+    if out == nil {
+        return fields
+    }
+    return out
+}
+
+// 类型化字段直接写出，不经过encoding/json的反射
+func (l *Logger) writeJSONField(b *bytes.Buffer, f Field) {
+    switch f.kind {
+    case kindString:
+        writeJSONString(b, l.escapeText(f.str))
+    case kindInt64, kindDuration:
+        b.WriteString(strconv.FormatInt(f.num, 10))
+    case kindFloat64:
+        v := math.Float64frombits(uint64(f.num))
+        if math.IsNaN(v) || math.IsInf(v, 0) {
+            // JSON没有NaN和Inf，按字符串输出
+            writeJSONString(b, strconv.FormatFloat(v, 'g', -1, 64))
+        } else {
+            b.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+        }
+    case kindBool:
+        b.WriteString(strconv.FormatBool(f.num == 1))
+    case kindTime:
+        writeJSONString(b, f.text())
+    case kindGroup:
+        // 分组内同样去重并保持顺序，key为空的子分组展开到当前对象
+        fields, _ := f.Value.([]Field)
+        b.WriteByte('{')
+        for i, sub := range orderFields(inlineGroups(fields), nil) {
+            if i > 0 {
+                b.WriteByte(',')
+            }
+            writeJSONString(b, sub.Key)
+            b.WriteByte(':')
+            l.writeJSONField(b, sub)
+        }
+        b.WriteByte('}')
+    default:
+        writeJSONValue(b, l.jsonFieldValue(f.Value))
+    }
+}
+
+// 字符串类的值同样要经过转义（截断对整条记录进行，见truncateJSON）；error和Stringer取其文本，避免被序列化成 {}
+func (l *Logger) jsonFieldValue(v interface{}) interface{} {
+    switch x := v.(type) {
+    case string:
+        return l.escapeText(x)
+    case error:
+        return l.escapeText(x.Error())
+    case fmt.Stringer:
+        return l.escapeText(x.String())
+    }
+    return v
+}
+
+// 不做HTML转义，<、>、& 原样输出，便于人工查看。字符串直接转义写入，其他类型才经过encoding/json
+func writeJSONValue(b *bytes.Buffer, v interface{}) {
+    if s, ok := v.(string); ok {
+        writeJSONString(b, s)
+        return
+    }
+    var tmp bytes.Buffer
+    enc := json.NewEncoder(&tmp)
+    enc.SetEscapeHTML(false)
+    if err := enc.Encode(v); err != nil {
+        tmp.Reset()
+        enc.Encode(fmt.Sprint(v))
+    }
+    b.Write(bytes.TrimRight(tmp.Bytes(), "\n"))
+}
+
+const hexDigits = "0123456789abcdef"
+
+// 把s编码为JSON字符串写入b，规则同encoding/json（不做HTML转义）：引号、反斜杠和控制字符转义，
+// 无效的UTF-8替换为U+FFFD，U+2028、U+2029转义以便嵌入JavaScript
+func writeJSONString(b *bytes.Buffer, s string) {
+    b.WriteByte('"')
+    start := 0
+    for i := 0; i < len(s); {
+        if c := s[i]; c < utf8.RuneSelf {
+            if c >= 0x20 && c != '"' && c != '\\' {
+                i++
+                continue
+            }
+            b.WriteString(s[start:i])
+            switch c {
+            case '"', '\\':
+                b.WriteByte('\\')
+                b.WriteByte(c)
+            case '\n':
+                b.WriteString(`\n`)
+            case '\r':
+                b.WriteString(`\r`)
+            case '\t':
+                b.WriteString(`\t`)
+            default:
+                b.WriteString(`\u00`)
+                b.WriteByte(hexDigits[c>>4])
+                b.WriteByte(hexDigits[c&0xF])
+            }
+            i++
+            start = i
+            continue
+        }
+        r, size := utf8.DecodeRuneInString(s[i:])
+        if r == utf8.RuneError && size == 1 {
+            b.WriteString(s[start:i])
+            b.WriteString("\ufffd")
+            i += size
+            start = i
+            continue
+        }
+        if r == '\u2028' || r == '\u2029' {
+            b.WriteString(s[start:i])
+            b.WriteString(`\u202`)
+            b.WriteByte(hexDigits[r&0xF])
+            i += size
+            start = i
+            continue
+        }
+        i += size
+    }
+    b.WriteString(s[start:])
+    b.WriteByte('"')
+}
+
+// 嵌套输出时，key为空的分组把字段直接放到上一层，没有字段的分组去掉
+func inlineGroups(fields []Field) []Field {
+    var out []Field
+    for i, f := range fields {
+        sub, _ := f.Value.([]Field)
+        if f.kind == kindGroup && (f.Key == "" || len(sub) == 0) {
+            if out == nil {
+                out = append(make([]Field, 0, len(fields)), fields[:i]...)
+            }
+            if f.Key == "" {
+                out = append(out, inlineGroups(sub)...)
+            }
+            continue
+        }
+        if out != nil {
+            out = append(out, f)
+        }
+    }
+    if out == nil {
+        return fields
+    }
+    return out
+}
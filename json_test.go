@@ -0,0 +1,133 @@
+package jLogger
+
+import (
+    "bytes"
+    "encoding/json"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+)
+
+// 按顺序读出JSON对象顶层的key
+func jsonKeys(t *testing.T, line string) []string {
+    t.Helper()
+    dec := json.NewDecoder(strings.NewReader(line))
+    if _, err := dec.Token(); err != nil {
+        t.Fatalf("%s: %v", line, err)
+    }
+    var keys []string
+    for dec.More() {
+        tok, err := dec.Token()
+        if err != nil {
+            t.Fatalf("%s: %v", line, err)
+        }
+        keys = append(keys, tok.(string))
+        var v json.RawMessage
+        if err := dec.Decode(&v); err != nil {
+            t.Fatalf("%s: %v", line, err)
+        }
+    }
+    return keys
+}
+
+func TestJSONReservedKeys(t *testing.T) {
+    dir := t.TempDir()
+    l, err := NewLogger(dir, "app", 16, 10*time.Millisecond, "INFO", WithJSON())
+    if err != nil {
+        t.Fatal(err)
+    }
+    l.InfoFields("hello", String("msg", "user"), String("level", "x"), Int("v", 9), String("time", "t"), String("k", "v"))
+    l.Close()
+
+    data, err := os.ReadFile(filepath.Join(dir, "app_info.log"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    line := strings.TrimSpace(string(data))
+    seen := make(map[string]bool)
+    for _, key := range jsonKeys(t, line) {
+        if seen[key] {
+            t.Fatalf("重复的key %q: %s", key, line)
+        }
+        seen[key] = true
+    }
+    var obj map[string]interface{}
+    if err := json.Unmarshal([]byte(line), &obj); err != nil {
+        t.Fatal(err)
+    }
+    if obj["msg"] != "hello" || obj["fields.msg"] != "user" || obj["fields.level"] != "x" || obj["fields.time"] != "t" || obj["k"] != "v" {
+        t.Fatalf("字段改名不正确: %s", line)
+    }
+}
+
+func TestWriteJSONString(t *testing.T) {
+    cases := []string{
+        "", "plain", `quote " and \ backslash`, "<a href='x'>&amp;</a>",
+        "line\nbreak\r\ttab", "\x00\x01\x1f\x7f", "中文 émoji 😀", "bad \xff utf8", "sep \u2028 \u2029",
+    }
+    for _, s := range cases {
+        var b bytes.Buffer
+        writeJSONString(&b, s)
+        var got string
+        if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+            t.Fatalf("%q 编码为 %s, 不是合法的JSON: %v", s, b.String(), err)
+        }
+        if want := strings.ToValidUTF8(s, "�"); got != want {
+            t.Errorf("%q 编码后解析为 %q", s, got)
+        }
+        var std bytes.Buffer
+        enc := json.NewEncoder(&std)
+        enc.SetEscapeHTML(false)
+        enc.Encode(s)
+        if !strings.ContainsAny(s, "\b\f\x7f") && b.String() != strings.TrimSuffix(std.String(), "\n") {
+            t.Errorf("%q 编码为 %s, encoding/json为 %s", s, b.String(), std.String())
+        }
+    }
+}
+
+func BenchmarkWriteJSONString(b *testing.B) {
+    var buf bytes.Buffer
+    s := "user alice logged in from 10.0.0.1 <ok>"
+    for i := 0; i < b.N; i++ {
+        buf.Reset()
+        writeJSONString(&buf, s)
+    }
+}
+
+func TestJSONMaxMessageBytesWholeRecord(t *testing.T) {
+    dir := t.TempDir()
+    const max = 300
+    l, err := NewLogger(dir, "app", 16, 10*time.Millisecond, "INFO", WithJSON(), WithMaxMessageBytes(max))
+    if err != nil {
+        t.Fatal(err)
+    }
+    // 每个字段都没有超过上限，合起来超过
+    l.InfoFields("short", String("a", strings.Repeat("a", 200)), String("b", strings.Repeat("b", 200)))
+    l.InfoFields(strings.Repeat("长", 500), String("k", "v"))
+    l.InfoFields("fits", String("k", "v"))
+    l.Close()
+
+    data, err := os.ReadFile(filepath.Join(dir, "app_info.log"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+    if len(lines) != 3 {
+        t.Fatalf("写入%d行, 期望3行", len(lines))
+    }
+    for i, line := range lines {
+        if len(line) > max {
+            t.Errorf("第%d行编码后%d字节, 超过上限%d: %s", i+1, len(line), max, line)
+        }
+        var obj map[string]interface{}
+        if err := json.Unmarshal([]byte(line), &obj); err != nil {
+            t.Fatalf("第%d行不是合法的JSON: %v", i+1, err)
+        }
+        _, truncated := obj["truncated"]
+        if truncated != (i < 2) {
+            t.Errorf("第%d行的truncated字段不正确: %s", i+1, line)
+        }
+    }
+}
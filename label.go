@@ -0,0 +1,21 @@
+package jLogger
+
+// 输出中使用的级别文字，未设置时就是级别名本身
+func (l *Logger) levelLabel(level string) string {
+    if label, ok := l.levelLabels[level]; ok {
+        return label
+    }
+    return level
+}
+
+// 从输出的级别文字还原级别名，用于读取本Logger写出的文件
+func (l *Logger) labelLevels() map[string]string {
+    if len(l.levelLabels) == 0 {
+        return nil
+    }
+    levels := make(map[string]string, len(l.levelLabels))
+    for level, label := range l.levelLabels {
+        levels[label] = level
+    }
+    return levels
+}
@@ -0,0 +1,137 @@
+package jLogger
+
+import (
+    "fmt"
+    "net/http"
+    "sort"
+    "sync/atomic"
+    "time"
+)
+
+// 写入延迟直方图的桶上界，最后还有一个+Inf桶
+var latencyBounds = []time.Duration{
+    time.Millisecond,
+    5 * time.Millisecond,
+    10 * time.Millisecond,
+    50 * time.Millisecond,
+    100 * time.Millisecond,
+    500 * time.Millisecond,
+    time.Second,
+    5 * time.Second,
+    10 * time.Second,
+    30 * time.Second,
+}
+
+// 桶的个数，比latencyBounds多一个+Inf桶
+const latencyBuckets = 11
+
+// 从记录产生到写入文件的耗时分布，计数全部用原子操作，写入路径上不加锁
+type latencyHistogram struct {
+    counts [latencyBuckets]uint64 // 对应latencyBounds和+Inf，非累积
+    sum    int64      // 纳秒
+}
+
+func (h *latencyHistogram) observe(d time.Duration) {
+    i := sort.Search(len(latencyBounds), func(i int) bool { return d <= latencyBounds[i] })
+    atomic.AddUint64(&h.counts[i], 1)
+    atomic.AddInt64(&h.sum, int64(d))
+}
+
+// LatencyBucket 直方图的一个桶，Count为耗时不超过Le的累计条数
+type LatencyBucket struct {
+    Le    string `json:"le"` // 上界，最后一个桶为+Inf
+    Count uint64 `json:"count"`
+}
+
+// LatencyStats 一个级别从记录产生到写入文件的耗时分布，用来根据实际数据调整bufferSize和flushInterval
+type LatencyStats struct {
+    Count   uint64          `json:"count"`
+    Sum     string          `json:"sum"`
+    P50     string          `json:"p50"` // 按桶上界估计
+    P99     string          `json:"p99"`
+    Max     string          `json:"max"` // 最大值所在桶的上界
+    Buckets []LatencyBucket `json:"buckets"`
+}
+
+func (h *latencyHistogram) stats() LatencyStats {
+    var st LatencyStats
+    var counts [latencyBuckets]uint64
+    for i := range counts {
+        counts[i] = atomic.LoadUint64(&h.counts[i])
+        st.Count += counts[i]
+    }
+    st.Sum = time.Duration(atomic.LoadInt64(&h.sum)).String()
+
+    var cum uint64
+    for i, n := range counts {
+        cum += n
+        st.Buckets = append(st.Buckets, LatencyBucket{Le: latencyBound(i), Count: cum})
+        if st.P50 == "" && st.Count > 0 && cum*2 >= st.Count {
+            st.P50 = latencyBound(i)
+        }
+        if st.P99 == "" && st.Count > 0 && cum*100 >= st.Count*99 {
+            st.P99 = latencyBound(i)
+        }
+        if n > 0 {
+            st.Max = latencyBound(i)
+        }
+    }
+    return st
+}
+
+func latencyBound(i int) string {
+    if i >= len(latencyBounds) {
+        return "+Inf"
+    }
+    return latencyBounds[i].String()
+}
+
+// 记录一批刚写入文件的记录的延迟，在写入之后调用
+func (l *Logger) observeWrites(msgs []logMessage) {
+    if len(msgs) == 0 {
+        return
+    }
+    now := l.now()
+    for _, msg := range msgs {
+        if i := levelIndex(msg.level); i >= 0 {
+            l.latency[i].observe(now.Sub(msg.timestamp))
+        }
+    }
+}
+
+// MetricsHandler 以Prometheus文本格式输出管道自身的指标：各级别的写入延迟直方图、通道溢出条数和队列深度，
+// 不依赖Prometheus客户端库，可直接挂载到 /metrics 或合并到已有的抓取端点
+func (l *Logger) MetricsHandler() http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        root := l.pipeline()
+        w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+        fmt.Fprintln(w, "# HELP jlogger_write_latency_seconds 从记录产生到写入文件的耗时")
+        fmt.Fprintln(w, "# TYPE jlogger_write_latency_seconds histogram")
+        for i, level := range levelNames {
+            h := &root.latency[i]
+            var cum uint64
+            for b := range h.counts {
+                cum += atomic.LoadUint64(&h.counts[b])
+                le := "+Inf"
+                if b < len(latencyBounds) {
+                    le = fmt.Sprint(latencyBounds[b].Seconds())
+                }
+                fmt.Fprintf(w, "jlogger_write_latency_seconds_bucket{level=%q,le=%q} %d\n", level, le, cum)
+            }
+            fmt.Fprintf(w, "jlogger_write_latency_seconds_sum{level=%q} %g\n", level, time.Duration(atomic.LoadInt64(&h.sum)).Seconds())
+            fmt.Fprintf(w, "jlogger_write_latency_seconds_count{level=%q} %d\n", level, cum)
+        }
+
+        fmt.Fprintln(w, "# HELP jlogger_dropped_total 通道溢出的记录数")
+        fmt.Fprintln(w, "# TYPE jlogger_dropped_total counter")
+        for i, level := range levelNames {
+            fmt.Fprintf(w, "jlogger_dropped_total{level=%q} %d\n", level, atomic.LoadUint64(&root.dropCounts[i]))
+        }
+
+        fmt.Fprintln(w, "# HELP jlogger_channel_depth 通道中排队的记录数")
+        fmt.Fprintln(w, "# TYPE jlogger_channel_depth gauge")
+        fmt.Fprintf(w, "jlogger_channel_depth{channel=\"log\"} %d\n", len(root.logChannel))
+        fmt.Fprintf(w, "jlogger_channel_depth{channel=\"error\"} %d\n", len(root.errorChannel))
+    })
+}
@@ -0,0 +1,50 @@
+package jLogger
+
+import (
+    "log"
+    "os"
+    "runtime"
+    "sync/atomic"
+    "time"
+)
+
+// START/STOP标记记录的消息文本，工具按这两个消息切分进程的生命周期
+const (
+    markerStart = "START"
+    markerStop  = "STOP"
+)
+
+// 向每个日志文件写一条START记录，不受级别过滤影响。标记只写文件，不计入Error预算
+func (l *Logger) writeStartMarker() {
+    now := time.Now()
+    for i, logger := range []*log.Logger{l.InfoLogger, l.DebugLogger, l.ErrorLogger} {
+        msg := l.newMessage(levelNames[i], now, []interface{}{markerStart},
+            String("version", l.appVersion),
+            String("config_hash", l.EffectiveConfig().Hash),
+            Int("pid", os.Getpid()),
+            String("go", runtime.Version()),
+        )
+        msg.internal, msg.fileOnly = true, true
+        l.enqueue(logger, msg)
+    }
+}
+
+// 向每个日志文件写一条STOP记录，reason为close、panic、fatal等，只写一次。必须在通道关闭之前调用
+func (l *Logger) writeStopMarker(reason string) {
+    if !atomic.CompareAndSwapUint32(&l.stopped, 0, 1) {
+        return
+    }
+    now := time.Now()
+    uptime := now.Sub(l.started).Truncate(time.Millisecond)
+    for i, logger := range []*log.Logger{l.InfoLogger, l.DebugLogger, l.ErrorLogger} {
+        msg := l.newMessage(levelNames[i], now, []interface{}{markerStop},
+            String("version", l.appVersion),
+            String("reason", reason),
+            Int("pid", os.Getpid()),
+            Duration("uptime", uptime),
+            Int64("dropped", int64(atomic.LoadUint64(&l.dropCounts[i]))),
+        )
+        msg.internal, msg.fileOnly = true, true
+        l.enqueue(logger, msg)
+    }
+}
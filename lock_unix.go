@@ -0,0 +1,16 @@
+//go:build !windows
+
+package jLogger
+
+import (
+    "os"
+    "syscall"
+)
+
+func lockFile(f *os.File) error {
+    return syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+}
+
+func unlockFile(f *os.File) error {
+    return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
@@ -1,6 +1,7 @@
 package jLogger
 
 import (
+    "context"
     "log"
     "os"
     "path/filepath"
@@ -8,14 +9,24 @@ import (
     "time"
     "sync"
     "errors"
-    "fmt"
-    "strings"
+    "sync/atomic"
+    "sort"
 )
 
 type logMessage struct {
     level string
     timestamp time.Time   // 记录日志产生时间
     msg   []interface{}
+    seq   uint64 // 全局递增序号，生产者写入通道前分配
+    module string // 产生记录的模块名（Named），为空表示根Logger
+    tag   string // 产生记录的句柄标签（Tagged），如worker编号
+    fields []Field // InfoFields等结构化方法传入的字段
+    callers []uintptr // 调用栈，用于计算Error记录的指纹和WithStdFlags的文件行号
+    barrier chan struct{} // 非nil时不是日志记录，而是Flush放入通道的屏障，处理到时关闭
+    size  int64 // 占用的内存配额，见WithMemoryLimit，未计入时为0
+    id    string // 记录的唯一ID，见WithRecordID
+    internal bool // 库自己产生的记录（心跳、START/STOP标记、降级、自检），不计入Error预算
+    fileOnly bool // 只写日志文件，不推送给订阅者、不投递给Sink，用于心跳和START/STOP标记
 }
 
 const timeFormat = "2006-01-02 15:04:05.000"
@@ -29,6 +40,7 @@ type Logger struct {
     DebugLogger *log.Logger
     ErrorLogger *log.Logger
     logChannel  chan logMessage
+    errorChannel chan logMessage // Error专用通道，Info/Debug刷屏时也不会挤占Error
     bufferInfo []logMessage // Info缓冲区
     bufferDebug []logMessage // Debug缓冲区
     bufferError []logMessage // Error缓冲区
@@ -37,13 +49,123 @@ type Logger struct {
     info_mu sync.Mutex
     debug_mu sync.Mutex
     error_mu sync.Mutex
+    info_flush_mu sync.Mutex // 保证同一级别的批次按取出的先后顺序写入文件，不会互相穿插
+    debug_flush_mu sync.Mutex
+    error_flush_mu sync.Mutex
     once      sync.Once // 保证Close方法只执行一次
     wg        sync.WaitGroup // 保证所有日志写入完成后再关闭
-    closed    bool // 保证Close方法只执行一次
+    closed    bool // Close关闭通道之后为true，之后的记录按afterClose处理
+    send_mu   sync.RWMutex // 发送到通道时持读锁，Close关闭通道时持写锁，保证不会向已关闭的通道发送
     log_level string // 日志级别
+    escape    bool // 是否转义换行和控制字符，防止日志注入
+    maxMessageBytes int // 单条消息的最大字节数，<=0 表示不限制
+    json      bool // 是否以JSON格式输出
+    fieldOrder []string // JSON输出时固定排在前面的字段key
+    seq       uint64 // 最近一次分配的序号，原子递增
+    emitSeq   bool // 是否把序号作为seq字段输出
+    ordered   bool // 严格有序模式：按序号的水位刷新，通道已满时也不绕过缓冲区，见WithOrdered
+    order_mu  sync.Mutex // 保护inflight和orderLow
+    inflight  map[uint64]struct{} // 有序模式下已分配序号、还没放入缓冲区的记录
+    orderLow  uint64 // 有序模式的水位：小于它的序号都已经放入缓冲区或者不会再出现
+    merged    bool // 合并刷新模式：三个级别的缓冲区一起取出，按时间顺序统一写入
+    logDir    string
+    logPrefix string
+    useSpool  bool // 是否启用磁盘预写spool
+    spool     *spool
+    onRecover func(n int) // 启动时从spool恢复记录后的回调
+    dropCounts [3]uint64 // 各级别累计的丢弃/同步写入条数，按levelIndex索引，原子操作
+    dropReported [3]uint64 // 已经通过onDrop上报过的条数
+    drop_mu   sync.Mutex // 保证上报不会并发执行
+    onDrop    func(level string, count int)
+    adaptiveFlush bool // 是否根据负载自动调整刷新间隔
+    minFlushInterval time.Duration
+    maxFlushInterval time.Duration
+    fullFlushes uint64 // 因缓冲区写满触发的刷新次数，原子操作，定时刷新时清零
+    busyTicks int // 连续繁忙的定时刷新次数，只在定时刷新协程中访问
+    idleTicks int // 连续空闲的定时刷新次数
+    errorFlushDelay time.Duration // Error记录最多在缓冲区停留多久，0表示立即刷新，<0表示和其他级别一样批量刷新
+    errorFlushPending int32 // 是否已经安排了一次Error缓冲区刷新，原子操作
+    closeHooks []func(ctx context.Context) error // Close时执行的回调
+    hooks_mu  sync.Mutex
+    closeTimeout time.Duration // 关闭回调的总超时
+    prefix    string // 追加在每条消息最前面的前缀
+    shared    *Logger // 通过CloneWith创建时指向共享管道的根Logger，根Logger自身为nil
+    module    string // 模块名，点分层级，如 server.http.handlers
+    tag       string // 句柄标签，见Tagged
+    fields    []Field // 句柄字段，见With
+    moduleLevels map[string]string // 按模块设置的日志级别，只在根Logger上使用
+    levels_mu sync.RWMutex
+    envModules []string // 上一次从环境变量设置的模块，重新加载时先移除
+    remoteModules []string // 上一次从远程配置设置的模块
+    recentErrors []InternalError // 最近的内部错误
+    errors_mu sync.Mutex
+    subscribers map[*subscriber]struct{} // Subscribe的订阅者
+    subCount  int32 // 订阅者数量，原子操作，没有订阅者时跳过推送
+    subs_mu   sync.Mutex
+    sinks     map[string]*sinkWorker // 文件之外的输出目标
+    sinkCount int32 // Sink数量，原子操作，没有Sink时跳过投递
+    sinks_mu  sync.RWMutex
+    errorFingerprint bool // 是否为Error记录计算指纹
+    sampleThreshold uint64 // 采样阈值（万分比），原子操作，sampleScale表示不采样
+    sampledOut uint64 // 被采样丢弃的条数
+    done      chan struct{} // Close时关闭，通知后台协程退出
+    retention *RetentionPolicy // 跨文件的保留策略
+    indexEvery int // >0时为归档文件维护时间索引，值为标记间隔
+    validateSchema bool // 是否按RegisterSchema登记的约定校验字段
+    schemas   map[string]FieldSchema // 消息模板 -> 字段约定
+    schemaReported map[string]bool // 已经记过内部错误的消息模板，最多schemaReportedMax个
+    schemaUnreported uint64 // schemaReported已满、没有记内部错误的违反次数
+    schemas_mu sync.RWMutex
+    schemaViolations uint64 // 违反字段约定的记录数
+    levelLabels map[string]string // 级别 -> 写入文件的级别文字
+    recent    *ring // 最近的记录，不受级别过滤影响
+    emitters  sync.WaitGroup // 后台写日志的协程，Close在关闭通道之前等待它们退出
+    runtimeInterval time.Duration // >0时定期输出运行时指标
+    runtimeLevel string
+    heartbeatInterval time.Duration // >0时定期向每个文件写心跳记录
+    started   time.Time
+    budget    *errorBudget // Error条数的预算告警
+    access    *log.Logger // 访问日志，第一次使用AccessLog时创建
+    accessFile *lumberjack.Logger // 没有登记在openFiles中、由本Logger自己关闭的访问日志文件
+    access_mu sync.Mutex
+    everyStates map[uintptr]*everyState // InfoEvery等限频方法按调用位置的状态
+    every_mu  sync.Mutex
+    escalation []EscalationRule // 级别提升规则
+    markers   bool   // 启动和关闭时写START/STOP标记
+    appVersion string // 标记中的应用版本
+    stopped   uint32 // 已写过STOP标记
+    configDump bool  // 启动和重新加载配置时写一条生效配置的记录
+    clock     func() time.Time // 事件时间的来源，nil时为time.Now
+    writeTime bool   // 附加写入时间字段write_time
+    latency   [3]latencyHistogram // 各级别从产生到写入文件的耗时，下标同dropCounts
+    memoryLimit  int64 // 通道和缓冲区中排队记录的内存上限（估算），0表示不限
+    memoryPolicy MemoryPolicy
+    memUsed      int64  // 原子操作
+    memRejected  uint64 // 超出上限被丢弃或改为同步写入的条数
+    memOver      uint32 // 已报告超限，降到上限一半以下后清零
+    degrade      *degrader // 过载降级，nil表示未启用
+    degradeStage int32     // 当前降级阶段，原子操作
+    degradedOut  uint64    // 因降级被丢弃的条数
+    multiProcess bool      // 多个进程写同一组文件，写入和轮转加文件锁
+    afterClose   AfterClosePolicy // Close之后写日志的处理方式
+    lateRecords  uint64    // Close之后收到的记录数
+    shareFiles   bool      // 同一进程中已有Logger使用相同的目录和前缀时共享它的文件
+    filesKey     string    // 在openFiles中登记的key，见claimFiles
+    providers    []*FieldProvider // 写入时计算字段的函数，见AddFieldProvider
+    providers_mu sync.RWMutex
+    providerCount int32    // len(providers)，编码时不加锁判断
+    crlf         bool      // 行尾使用\r\n
+    portableNames bool     // 在所有平台上按Windows的规则处理文件名前缀
+    deadLetters  *deadLetterFile // Sink放弃投递的记录，见WithDeadLetter
+    sinkFields   map[string]*fieldFilter // 各Sink的字段过滤规则，由sinks_mu保护
+    sinkFieldSpecs map[string]FieldFilter
+    stdFlags     map[string]int // 按标准库log标志格式化行首的级别，见WithStdFlags
+    nestedGroups bool      // JSON格式中分组输出为嵌套对象
+    newID        IDGenerator // 不为nil时为每条记录生成log_id
+    daily        *dailyRotation // 每天定时轮转，见WithDailyRotation
 }
 
-func NewLogger(logDir, logPrefix string, bufferSize int, flushInterval time.Duration, log_level string) (*Logger, error) {
+func NewLogger(logDir, logPrefix string, bufferSize int, flushInterval time.Duration, log_level string, opts ...Option) (*Logger, error) {
     if err := os.MkdirAll(logDir, 0755); err != nil {
         log.Fatalf("创建或访问日志目录失败: %v", err)
     }
@@ -52,6 +174,10 @@ func NewLogger(logDir, logPrefix string, bufferSize int, flushInterval time.Dura
         return nil, errors.New("bufferSize必须大于0")
     }
 
+    if flushInterval <= 0 {
+        return nil, errors.New("flushInterval必须大于0")
+    }
+
     infoLogPath := filepath.Join(logDir, logPrefix+"_info.log")
     debugLogPath := filepath.Join(logDir, logPrefix+"_debug.log")
     errorLogPath := filepath.Join(logDir, logPrefix+"_error.log")
@@ -89,26 +215,116 @@ func NewLogger(logDir, logPrefix string, bufferSize int, flushInterval time.Dura
         DebugLogger: debugLogger,
         ErrorLogger: errorLogger,
         logChannel:  make(chan logMessage, 5000), // 缓冲通道，容量为5000
+        errorChannel: make(chan logMessage, 20000), // Error通道容量更大，写满时阻塞等待而不是走溢出路径
         bufferInfo:  make([]logMessage, 0, bufferSize),
         bufferDebug: make([]logMessage, 0, bufferSize),
         bufferError: make([]logMessage, 0, bufferSize),
         bufferSize:  bufferSize,
         flushInterval: flushInterval,
         log_level: log_level,
+        logDir: logDir,
+        logPrefix: logPrefix,
+        errorFlushDelay: defaultErrorFlushDelay,
+        closeTimeout: defaultCloseTimeout,
+        sampleThreshold: sampleScale,
+        done: make(chan struct{}),
+        started: time.Now(),
+    }
+    for _, opt := range opts {
+        opt(logger)
+    }
+    logger.sanitizeFileNames()
+    logPrefix = logger.logPrefix
+    if logger.json {
+        // JSON模式下级别写在字段里，去掉log.Logger的前缀，保证每行都是合法JSON
+        infoLogger.SetPrefix("")
+        debugLogger.SetPrefix("")
+        errorLogger.SetPrefix("")
+    } else if len(logger.levelLabels) > 0 {
+        infoLogger.SetPrefix(logger.levelLabel("INFO") + ": ")
+        debugLogger.SetPrefix(logger.levelLabel("DEBUG") + ": ")
+        errorLogger.SetPrefix(logger.levelLabel("ERROR") + ": ")
     }
+    logger.applyEnvLevels()
 
-    go logger.processLogMessages()
-    logger.wg.Add(1) // 保证processLogMessages执行完毕后再关闭
+    if logger.multiProcess {
+        // 多进程模式下文件锁已经保证了写入和轮转互斥，同一进程中的多个Logger也按多个进程处理
+        if err := logger.enableMultiProcess(); err != nil {
+            return nil, err
+        }
+    } else if err := logger.claimFiles(); err != nil {
+        return nil, err
+    }
+    logger.wrapFileOutputs()
+
+    if logger.useSpool {
+        sp, err := openSpool(spoolPath(logDir, logPrefix))
+        if err != nil {
+            logger.releaseFiles()
+            return nil, err
+        }
+        logger.spool = sp
+        n, err := logger.replaySpool(spoolPath(logDir, logPrefix))
+        if err != nil {
+            logger.releaseFiles()
+            return nil, err
+        }
+        if logger.onRecover != nil {
+            logger.onRecover(n)
+        }
+    }
+
+    logger.wg.Add(2) // 保证processLogMessages执行完毕后再关闭
+    go logger.processLogMessages(logger.logChannel)
+    go logger.processLogMessages(logger.errorChannel)
 
     go logger.flushBufferPeriodically()
 
+    if logger.retention != nil {
+        go logger.runRetention()
+    }
+    if logger.indexEvery > 0 {
+        go logger.runIndexer()
+    }
+    if logger.runtimeInterval > 0 {
+        logger.emitters.Add(1)
+        go logger.runRuntimeMetrics()
+    }
+    if logger.heartbeatInterval > 0 {
+        logger.emitters.Add(1)
+        go logger.runHeartbeat()
+    }
+    if logger.budget != nil {
+        logger.emitters.Add(1)
+        go logger.runErrorBudget()
+    }
+    if logger.degrade != nil {
+        logger.emitters.Add(1)
+        go logger.runDegradation()
+    }
+    if logger.daily != nil {
+        logger.emitters.Add(1)
+        go logger.runDailyRotation()
+    }
+    if logger.markers {
+        logger.writeStartMarker()
+    }
+    logger.logConfig("start")
+
     return logger, nil
 }
 
-func (l *Logger) processLogMessages() {
+// 每个通道一个处理协程：logChannel承载Info/Debug，errorChannel单独承载Error
+func (l *Logger) processLogMessages(ch chan logMessage) {
     defer l.wg.Done()
 
-    for msg := range l.logChannel {
+    for msg := range ch {
+        if msg.barrier != nil {
+            close(msg.barrier)
+            continue
+        }
+        l.publish(msg)
+
         var needFlushInfo, needFlushDebug, needFlushError bool
         if msg.level == "INFO" {
             l.info_mu.Lock()
@@ -128,9 +344,19 @@ func (l *Logger) processLogMessages() {
             // 判断是否需要刷新缓冲区，放在锁内，避免在所外判断，buffer大小已经发生变化
             needFlushError = len(l.bufferError) >= l.bufferSize
             l.error_mu.Unlock()
+            if !needFlushError {
+                needFlushError = l.scheduleErrorFlush()
+            }
         }
 
         // log.Println("写入缓冲区:", msg.level, msg.msg)
+        if l.ordered {
+            l.orderArrived(msg.seq)
+        }
+
+        if needFlushInfo || needFlushDebug || needFlushError {
+            atomic.AddUint64(&l.fullFlushes, 1)
+        }
 
         if needFlushInfo{
             // log.Println("Info缓冲区已满，刷新缓冲区")
@@ -150,41 +376,222 @@ func (l *Logger) processLogMessages() {
 }
 
 // 统一flush方法
-func (l *Logger) flushBuffer(buffer *[]logMessage, mu *sync.Mutex, logger *log.Logger) {
+func (l *Logger) flushBuffer(buffer *[]logMessage, mu *sync.Mutex, flushMu *sync.Mutex, logger *log.Logger) {
+    // flushMu只在刷新之间互斥，不影响生产者继续写缓冲区
+    flushMu.Lock()
+    defer flushMu.Unlock()
+
     mu.Lock()
+    n := len(*buffer)
+    if l.ordered {
+        // 多个协程竞争发送时到达顺序可能和序号不一致，这里按序号还原；
+        // 序号不小于水位的记录前面还有记录在路上，留在缓冲区等下一次刷新
+        b := *buffer
+        sort.Slice(b, func(i, j int) bool { return b[i].seq < b[j].seq })
+        low := l.orderWatermark()
+        n = sort.Search(len(b), func(i int) bool { return b[i].seq >= low })
+    }
     // 复制数据后立即释放锁
-    tmp := make([]logMessage, len(*buffer))
+    tmp := make([]logMessage, n)
     copy(tmp, *buffer)
-    *buffer = (*buffer)[:0]
+    *buffer = append((*buffer)[:0], (*buffer)[n:]...)
     mu.Unlock()
-    
+
     // 写入文件（无需持有锁）
     for _, msg := range tmp {
-        logger.Println(msg.timestamp.Format(timeFormat), strings.TrimSpace(fmt.Sprintln(msg.msg...)))
+        logger.Println(l.encode(msg))
     }
+    l.observeWrites(tmp)
+    l.releaseMemory(tmp)
+    l.spoolAck(tmp)
+    l.deliver(tmp)
 }
 
 func (l *Logger) flushInfoBuffer() {
-    l.flushBuffer(&l.bufferInfo, &l.info_mu, l.InfoLogger)
+    if l.merged {
+        l.flushMerged()
+        return
+    }
+    l.flushBuffer(&l.bufferInfo, &l.info_mu, &l.info_flush_mu, l.InfoLogger)
 }
 
 func (l *Logger) flushDebugBuffer() {
-    l.flushBuffer(&l.bufferDebug, &l.debug_mu, l.DebugLogger)
+    if l.merged {
+        l.flushMerged()
+        return
+    }
+    l.flushBuffer(&l.bufferDebug, &l.debug_mu, &l.debug_flush_mu, l.DebugLogger)
 }
 
 func (l *Logger) flushErrorBuffer() {
-    l.flushBuffer(&l.bufferError, &l.error_mu, l.ErrorLogger)
+    if l.merged {
+        l.flushMerged()
+        return
+    }
+    l.flushBuffer(&l.bufferError, &l.error_mu, &l.error_flush_mu, l.ErrorLogger)
 }
 
-func (l *Logger) flushBufferPeriodically() {
-    ticker := time.NewTicker(l.flushInterval)
-    defer ticker.Stop()
-    for range ticker.C {
-        // log.Println("定时刷新缓冲区")
+// Flush 把调用之前产生的所有记录写入文件后返回：先等待两个通道中排在前面的记录都进入缓冲区，再刷新全部缓冲区
+func (l *Logger) Flush() {
+    l = l.pipeline()
+    info, errs := make(chan struct{}), make(chan struct{})
+    l.send_mu.RLock()
+    if l.closed {
+        // Close已经刷新过全部缓冲区
+        l.send_mu.RUnlock()
+        return
+    }
+    l.logChannel <- logMessage{barrier: info}
+    l.errorChannel <- logMessage{barrier: errs}
+    l.send_mu.RUnlock()
+    <-info
+    <-errs
+    l.flushAll()
+}
+
+// 刷新全部缓冲区
+func (l *Logger) flushAll() {
+    if l.merged {
+        l.flushMerged()
+    } else {
         l.flushInfoBuffer()
         l.flushDebugBuffer()
         l.flushErrorBuffer()
     }
+    if l.spool != nil {
+        l.spool.compact()
+    }
+}
+
+// 合并刷新：同时取出三个级别的缓冲区，按事件时间（相同时按序号）合并成一条时间线后依次写入，
+// 不同级别的记录不再因为各自独立刷新而交错得无法预测
+func (l *Logger) flushMerged() {
+    // 固定按 info -> debug -> error 的顺序加锁，避免死锁
+    l.info_flush_mu.Lock()
+    l.debug_flush_mu.Lock()
+    l.error_flush_mu.Lock()
+    defer l.error_flush_mu.Unlock()
+    defer l.debug_flush_mu.Unlock()
+    defer l.info_flush_mu.Unlock()
+
+    var tmp []logMessage
+    take := func(buffer *[]logMessage, mu *sync.Mutex) {
+        mu.Lock()
+        tmp = append(tmp, *buffer...)
+        *buffer = (*buffer)[:0]
+        mu.Unlock()
+    }
+    take(&l.bufferInfo, &l.info_mu)
+    take(&l.bufferDebug, &l.debug_mu)
+    take(&l.bufferError, &l.error_mu)
+
+    sort.Slice(tmp, func(i, j int) bool {
+        if !tmp[i].timestamp.Equal(tmp[j].timestamp) {
+            return tmp[i].timestamp.Before(tmp[j].timestamp)
+        }
+        return tmp[i].seq < tmp[j].seq
+    })
+
+    for _, msg := range tmp {
+        switch msg.level {
+        case "INFO":
+            l.InfoLogger.Println(l.encode(msg))
+        case "DEBUG":
+            l.DebugLogger.Println(l.encode(msg))
+        case "ERROR":
+            l.ErrorLogger.Println(l.encode(msg))
+        }
+    }
+    l.observeWrites(tmp)
+    l.releaseMemory(tmp)
+    l.spoolAck(tmp)
+    l.deliver(tmp)
+}
+
+// 有序模式下在记录进入管道时重新分配序号并登记为在途，分配和登记在同一把锁内，水位不会越过还没登记的序号
+func (l *Logger) orderAssign(msg *logMessage) {
+    l.order_mu.Lock()
+    msg.seq = atomic.AddUint64(&l.seq, 1)
+    if l.inflight == nil {
+        l.inflight = make(map[uint64]struct{})
+    }
+    l.inflight[msg.seq] = struct{}{}
+    l.order_mu.Unlock()
+}
+
+// 记录已经放入缓冲区（或被放弃），不再阻挡水位
+func (l *Logger) orderArrived(seq uint64) {
+    l.order_mu.Lock()
+    delete(l.inflight, seq)
+    l.order_mu.Unlock()
+}
+
+// 返回最小的仍在途的序号，没有在途记录时为已分配序号加1；序号小于它的记录可以写出
+func (l *Logger) orderWatermark() uint64 {
+    l.order_mu.Lock()
+    defer l.order_mu.Unlock()
+    last := atomic.LoadUint64(&l.seq)
+    for l.orderLow <= last {
+        if _, ok := l.inflight[l.orderLow]; ok {
+            break
+        }
+        l.orderLow++
+    }
+    return l.orderLow
+}
+
+// 严格有序模式下，通道已满时不直接写文件，而是由调用方协程直接放入对应级别的缓冲区，和其他记录一起排序后写入
+func (l *Logger) appendOrdered(msg logMessage) {
+    var buffer *[]logMessage
+    var mu *sync.Mutex
+    var flush func()
+    switch msg.level {
+    case "INFO":
+        buffer, mu, flush = &l.bufferInfo, &l.info_mu, l.flushInfoBuffer
+    case "DEBUG":
+        buffer, mu, flush = &l.bufferDebug, &l.debug_mu, l.flushDebugBuffer
+    case "ERROR":
+        buffer, mu, flush = &l.bufferError, &l.error_mu, l.flushErrorBuffer
+    default:
+        return
+    }
+
+    mu.Lock()
+    *buffer = append(*buffer, msg)
+    needFlush := len(*buffer) >= l.bufferSize
+    mu.Unlock()
+    l.orderArrived(msg.seq)
+
+    if !needFlush && msg.level == "ERROR" {
+        needFlush = l.scheduleErrorFlush()
+    }
+    if needFlush {
+        flush()
+    }
+}
+
+func (l *Logger) flushBufferPeriodically() {
+    interval := l.flushInterval
+    if l.adaptiveFlush {
+        interval = l.nextFlushInterval(interval, false, false)
+    }
+    timer := time.NewTimer(interval)
+    defer timer.Stop()
+    for {
+        select {
+        case <-l.done:
+            return
+        case <-timer.C:
+        }
+        // log.Println("定时刷新缓冲区")
+        busy, idle := l.bufferPressure()
+        l.flushAll()
+        l.reportDrops()
+        if l.adaptiveFlush {
+            interval = l.nextFlushInterval(interval, busy, idle)
+        }
+        timer.Reset(interval)
+    }
 }
 
 // 老版本的写法，不使用buffer缓冲区
@@ -204,54 +611,171 @@ func (l *Logger) flushBufferPeriodically() {
 // 自动根据日志等级，记录日志：DEBUG时，Info、Debug、Error方法都能写入日志；INFO只有Info和Error方法可以写入日志，ERROR时，只有Error方法可以写入日志
 // 通过config中的LOG_LEVEL设置日志级别
 func (l *Logger) Info(v ...interface{}) {
-    if l.log_level == "INFO" || l.log_level == "DEBUG" {
+    if l.escalated("INFO", v, nil) {
+        return
+    }
+    if l.enabled("INFO") && l.sampled("INFO", v) {
         // 立即捕获当前时间
-        eventTime := time.Now()
+        eventTime := l.now()
 
-        select {
-        case l.logChannel <- logMessage{level: "INFO", msg: v, timestamp: eventTime}:
-        default:
-            // 通道已满，丢弃日志或处理备用方案
-            l.InfoLogger.Println("日志通道已满，进入主线程写入日志:", v)
-        }
+        l.pipeline().enqueue(l.InfoLogger, l.newMessage("INFO", eventTime, v))
+    } else {
+        l.remember("INFO", v)
     }
 }
 
 func (l *Logger) Debug(v ...interface{}) {
-    if l.log_level == "DEBUG" {
+    if !DebugEnabled || l.escalated("DEBUG", v, nil) {
+        return
+    }
+    if l.enabled("DEBUG") && l.sampled("DEBUG", v) {
         // 立即捕获当前时间
-        eventTime := time.Now()
+        eventTime := l.now()
 
-        select {
-        case l.logChannel <- logMessage{level: "DEBUG", msg: v, timestamp: eventTime}:
-        default:
-            // 通道已满，丢弃日志或处理备用方案
-            l.DebugLogger.Println("日志通道已满，进入主线程写入日志", v)
-        }
+        l.pipeline().enqueue(l.DebugLogger, l.newMessage("DEBUG", eventTime, v))
+    } else {
+        l.remember("DEBUG", v)
     }
 }
 
 func (l *Logger) Error(v ...interface{}) {
     // 立即捕获当前时间
-    eventTime := time.Now()
+    eventTime := l.now()
+
+    l.pipeline().enqueue(l.ErrorLogger, l.newMessage("ERROR", eventTime, v))
+}
+
+// InfoFields 结构化写法：固定的消息文本加类型化字段，字段切片不经过interface装箱
+func (l *Logger) InfoFields(msg string, fields ...Field) {
+    if l.escalated("INFO", []interface{}{msg}, fields) {
+        return
+    }
+    if l.enabled("INFO") && l.sampled("INFO", []interface{}{msg}) {
+        eventTime := l.now()
+        m := l.newMessage("INFO", eventTime, []interface{}{msg}, fields...)
+        l.pipeline().enqueue(l.InfoLogger, m)
+    } else {
+        l.remember("INFO", []interface{}{msg}, fields...)
+    }
+}
+
+func (l *Logger) DebugFields(msg string, fields ...Field) {
+    if !DebugEnabled || l.escalated("DEBUG", []interface{}{msg}, fields) {
+        return
+    }
+    if l.enabled("DEBUG") && l.sampled("DEBUG", []interface{}{msg}) {
+        eventTime := l.now()
+        m := l.newMessage("DEBUG", eventTime, []interface{}{msg}, fields...)
+        l.pipeline().enqueue(l.DebugLogger, m)
+    } else {
+        l.remember("DEBUG", []interface{}{msg}, fields...)
+    }
+}
+
+func (l *Logger) ErrorFields(msg string, fields ...Field) {
+    eventTime := l.now()
+    m := l.newMessage("ERROR", eventTime, []interface{}{msg}, fields...)
+    l.pipeline().enqueue(l.ErrorLogger, m)
+}
+
+// 生成一条记录：序号由共享管道统一分配，前缀等属于当前Logger句柄的信息在这里附加
+func (l *Logger) newMessage(level string, eventTime time.Time, v []interface{}, fields ...Field) logMessage {
+    if len(l.fields) > 0 {
+        fields = append(append(make([]Field, 0, len(l.fields)+len(fields)), l.fields...), fields...)
+    }
+    if l.pipeline().validateSchema {
+        fields = l.pipeline().checkSchema(v, fields)
+    }
+    if l.prefix != "" {
+        v = append([]interface{}{l.prefix}, v...)
+    }
+    msg := logMessage{level: level, msg: v, timestamp: eventTime, seq: atomic.AddUint64(&l.pipeline().seq, 1), module: l.module, tag: l.tag, fields: fields}
+    if gen := l.pipeline().newID; gen != nil {
+        msg.id = gen()
+    }
+    if (level == "ERROR" && l.pipeline().errorFingerprint) || l.pipeline().needsCaller(level) {
+        msg.callers = captureCallers()
+    }
+    return msg
+}
+
+// 把记录送入通道，Info/Debug通道已满时由调用方协程同步写入
+func (l *Logger) enqueue(logger *log.Logger, msg logMessage) {
+    l.send_mu.RLock()
+    defer l.send_mu.RUnlock()
+    if l.closed {
+        l.writeAfterClose(logger, msg)
+        return
+    }
+    if l.ordered {
+        l.orderAssign(&msg)
+    }
+    if l.recent != nil {
+        l.recent.add(msg)
+    }
+    if l.memoryLimit > 0 && !l.reserveMemory(logger, &msg) {
+        if l.ordered {
+            l.orderArrived(msg.seq)
+        }
+        return
+    }
+    if l.spool != nil {
+        l.spool.append(msg, l.formatArgs(msg.msg, msg.fields...))
+    }
+
+    if msg.level == "ERROR" {
+        if l.budget != nil && !msg.internal {
+            l.budget.add(msg.timestamp)
+        }
+        // Error不允许被丢弃，通道写满时阻塞等待处理协程
+        l.errorChannel <- msg
+        return
+    }
 
     select {
-    case l.logChannel <- logMessage{level: "ERROR", msg: v, timestamp: eventTime}:
+    case l.logChannel <- msg:
     default:
         // 通道已满，丢弃日志或处理备用方案
-        l.ErrorLogger.Println("日志通道已满，进入主线程写入日志", v)
+        l.recordDrop(msg.level)
+        l.releaseMemory([]logMessage{msg})
+        msg.size = 0
+        l.writeFallback(logger, msg)
     }
 }
 
 // 添加 Close 方法
+// 克隆出来的Logger不拥有管道，Close不做任何事，由根Logger负责关闭
 func (l *Logger) Close() {
+    l.closeWith("close")
+}
+
+// reason写入STOP标记，区分正常关闭和Fatal等异常退出
+func (l *Logger) closeWith(reason string) {
+    if l.shared != nil {
+        return
+    }
     l.once.Do(func() {
+        close(l.done)
+        l.emitters.Wait()
+        if l.markers {
+            l.writeStopMarker(reason)
+        }
+        l.send_mu.Lock()
+        l.closed = true
         close(l.logChannel)
+        close(l.errorChannel)
+        l.send_mu.Unlock()
         l.wg.Wait()      // 等待消息处理完成
         // 最终刷新所有缓冲区
-        l.flushInfoBuffer()
-        l.flushDebugBuffer()
-        l.flushErrorBuffer()
+        l.flushAll()
+        if l.spool != nil {
+            l.spool.close()
+        }
+        l.reportDrops()
+        l.closeSinks()
+        l.runCloseHooks()
+        l.closeAccessFile()
+        l.releaseFiles()
     })
 }
 
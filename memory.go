@@ -0,0 +1,81 @@
+package jLogger
+
+import (
+    "log"
+    "sync/atomic"
+)
+
+// MemoryPolicy 排队中的记录超出内存上限时的处理方式
+type MemoryPolicy int
+
+const (
+    // MemoryFallback 不进入通道，在调用方协程中直接写入文件，和通道写满时一样
+    MemoryFallback MemoryPolicy = iota
+    // MemoryDrop 丢弃Info/Debug记录，计入Dropped；Error记录从不丢弃，仍按MemoryFallback直接写入
+    MemoryDrop
+)
+
+// 每条记录除参数内容外的固定开销估计：logMessage结构体、接口和切片头
+const messageOverhead = 160
+
+// 估算一条记录排队期间占用的内存，只统计字符串内容和固定开销，不做格式化
+func messageSize(msg logMessage) int64 {
+    n := messageOverhead + len(msg.module) + len(msg.tag)
+    for _, v := range msg.msg {
+        switch x := v.(type) {
+        case string:
+            n += len(x) + 16
+        case []byte:
+            n += len(x) + 24
+        case Field:
+            n += len(x.Key) + len(x.str) + 48
+        default:
+            n += 32
+        }
+    }
+    for _, f := range msg.fields {
+        n += len(f.Key) + len(f.str) + 48
+    }
+    return int64(n)
+}
+
+// 进入通道前占用内存配额，超出上限时按策略处理并返回false
+func (l *Logger) reserveMemory(logger *log.Logger, msg *logMessage) bool {
+    size := messageSize(*msg)
+    if atomic.AddInt64(&l.memUsed, size) <= l.memoryLimit {
+        msg.size = size
+        return true
+    }
+    atomic.AddInt64(&l.memUsed, -size)
+    atomic.AddUint64(&l.memRejected, 1)
+    if atomic.CompareAndSwapUint32(&l.memOver, 0, 1) {
+        l.internalError("排队中的日志超出内存上限:", l.memoryLimit, "字节，处理方式:", memoryPolicyName(l.memoryPolicy))
+    }
+    l.recordDrop(msg.level)
+    if msg.level != "ERROR" && l.memoryPolicy == MemoryDrop {
+        return false
+    }
+    l.writeFallback(logger, *msg)
+    return false
+}
+
+// 记录写入文件后归还占用的配额，降到上限的一半以下时允许再次报告超限
+func (l *Logger) releaseMemory(msgs []logMessage) {
+    var size int64
+    for _, msg := range msgs {
+        size += msg.size
+    }
+    if size == 0 {
+        return
+    }
+    if used := atomic.AddInt64(&l.memUsed, -size); used < l.memoryLimit/2 {
+        atomic.StoreUint32(&l.memOver, 0)
+    }
+}
+
+func memoryPolicyName(p MemoryPolicy) string {
+    if p == MemoryDrop {
+        return "丢弃Info/Debug"
+    }
+    return "改为同步写入"
+}
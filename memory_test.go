@@ -0,0 +1,65 @@
+package jLogger
+
+import (
+    "strings"
+    "sync"
+    "testing"
+    "time"
+)
+
+func TestMemoryLimitConcurrent(t *testing.T) {
+    payload := strings.Repeat("x", 200)
+    for name, policy := range map[string]MemoryPolicy{"fallback": MemoryFallback, "drop": MemoryDrop} {
+        t.Run(name, func(t *testing.T) {
+            dir := t.TempDir()
+            l, err := NewLogger(dir, "app", 1024, time.Millisecond, "INFO", WithMemoryLimit(4096, policy))
+            if err != nil {
+                t.Fatal(err)
+            }
+            const workers, n = 8, 500
+            var wg sync.WaitGroup
+            for w := 0; w < workers; w++ {
+                wg.Add(1)
+                go func(w int) {
+                    defer wg.Done()
+                    for i := 0; i < n; i++ {
+                        if i%10 == 0 {
+                            l.Error("mem-error", payload)
+                        } else {
+                            l.Info("mem-info", payload)
+                        }
+                    }
+                }(w)
+            }
+            wg.Wait()
+            l.Close()
+
+            st := l.Stats()
+            if st.MemoryRejected == 0 {
+                t.Fatal("MemoryRejected为0, 期望超出4096字节上限")
+            }
+            if st.MemoryUsed != 0 {
+                t.Fatalf("Close后MemoryUsed为%d, 期望配额全部归还", st.MemoryUsed)
+            }
+            var infos, errs int
+            for _, line := range readAllLines(t, dir, "app") {
+                switch {
+                case strings.Contains(line, "mem-info"):
+                    infos++
+                case strings.Contains(line, "mem-error"):
+                    errs++
+                }
+            }
+            if errs != workers*n/10 {
+                t.Fatalf("写入%d条Error, 期望%d条, Error不应丢弃", errs, workers*n/10)
+            }
+            want := workers * n * 9 / 10
+            if policy == MemoryFallback && infos != want {
+                t.Fatalf("MemoryFallback写入%d条Info, 期望%d条", infos, want)
+            }
+            if policy == MemoryDrop && uint64(want-infos) != st.Dropped["INFO"] {
+                t.Fatalf("丢失%d条Info, Dropped记为%d", want-infos, st.Dropped["INFO"])
+            }
+        })
+    }
+}
@@ -0,0 +1,19 @@
+package jLogger
+
+// Count 写一条计数器类型的指标记录，例如 log.Count("cache.miss", 1)，
+// 输出到Info文件：metric name=cache.miss type=counter value=1（JSON格式下为对应字段），
+// 没有指标系统的环境可以直接从日志中解析汇总。指标记录不受日志级别过滤
+func (l *Logger) Count(name string, delta int64, fields ...Field) {
+    l.emitMetric(append([]Field{String("name", name), String("type", "counter"), Int64("value", delta)}, fields...))
+}
+
+// Gauge 写一条仪表盘类型的指标记录，例如 log.Gauge("queue.depth", float64(n))
+func (l *Logger) Gauge(name string, value float64, fields ...Field) {
+    l.emitMetric(append([]Field{String("name", name), String("type", "gauge"), Float64("value", value)}, fields...))
+}
+
+func (l *Logger) emitMetric(fields []Field) {
+    eventTime := l.now()
+    m := l.newMessage("INFO", eventTime, []interface{}{"metric"}, fields...)
+    l.pipeline().enqueue(l.InfoLogger, m)
+}
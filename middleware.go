@@ -0,0 +1,127 @@
+package jLogger
+
+import (
+    "context"
+    "net/http"
+)
+
+// 默认的请求ID请求头
+const defaultRequestIDHeader = "X-Request-ID"
+
+type loggerKey struct{}
+
+// NewContext 返回携带logger的context，之后通过FromContext取出
+func NewContext(ctx context.Context, l Interface) context.Context {
+    return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// FromContext 取出NewContext或Middleware放入的logger，调用栈深处的代码不需要层层传递logger。
+// context中没有logger时返回一个丢弃所有日志的实现，调用方不需要判空
+func FromContext(ctx context.Context) Interface {
+    if ctx != nil {
+        if l, ok := ctx.Value(loggerKey{}).(Interface); ok {
+            return l
+        }
+    }
+    return nopLogger{}
+}
+
+// With 给任意Interface实现附加固定字段：*Logger使用l.With，其他实现（用户包装的Logger、jloggermock）用一层包装
+func With(l Interface, fields ...Field) Interface {
+    if lg, ok := l.(*Logger); ok {
+        return lg.With(fields...)
+    }
+    return &fieldLogger{Interface: l, fields: fields}
+}
+
+// MiddlewareOptions Middleware的配置
+type MiddlewareOptions struct {
+    RequestIDHeader string                        // 读取和回写请求ID的请求头，默认X-Request-ID；请求中没有时自动生成
+    User            func(r *http.Request) string  // 取当前用户，返回空时不附加user字段
+    Fields          func(r *http.Request) []Field // 附加的其他字段
+    ClientInfo      bool                          // 附加client_ip、user_agent、referer字段，见RequestFields
+}
+
+// Middleware 为每个请求创建带 request_id、method、route、user 字段的子logger并放入请求的context，
+// handler中通过 jLogger.FromContext(r.Context()) 取用。请求ID同时写入响应头，方便和调用方对账
+func Middleware(l Interface, opts MiddlewareOptions) func(http.Handler) http.Handler {
+    header := opts.RequestIDHeader
+    if header == "" {
+        header = defaultRequestIDHeader
+    }
+    return func(next http.Handler) http.Handler {
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            id := r.Header.Get(header)
+            if id == "" {
+                id = newSpanID()
+            }
+            w.Header().Set(header, id)
+
+            fields := []Field{String("request_id", id), String("method", r.Method), String("route", r.URL.Path)}
+            if opts.User != nil {
+                if user := opts.User(r); user != "" {
+                    fields = append(fields, String("user", user))
+                }
+            }
+            if opts.ClientInfo {
+                fields = append(fields, RequestFields(r)...)
+            }
+            if opts.Fields != nil {
+                fields = append(fields, opts.Fields(r)...)
+            }
+            ctx := NewContext(r.Context(), With(l, fields...))
+            next.ServeHTTP(w, r.WithContext(ctx))
+        })
+    }
+}
+
+// 给其他Interface实现附加字段的包装
+type fieldLogger struct {
+    Interface
+    fields []Field
+}
+
+// 把固定字段和调用时的参数合并，Field参数保持在后面
+func (f *fieldLogger) args(v []interface{}) []interface{} {
+    out := make([]interface{}, 0, len(v)+len(f.fields))
+    out = append(out, v...)
+    for _, field := range f.fields {
+        out = append(out, field)
+    }
+    return out
+}
+
+func (f *fieldLogger) merge(fields []Field) []Field {
+    return append(append(make([]Field, 0, len(f.fields)+len(fields)), f.fields...), fields...)
+}
+
+func (f *fieldLogger) Info(v ...interface{})  { f.Interface.Info(f.args(v)...) }
+func (f *fieldLogger) Debug(v ...interface{}) { f.Interface.Debug(f.args(v)...) }
+func (f *fieldLogger) Error(v ...interface{}) { f.Interface.Error(f.args(v)...) }
+
+func (f *fieldLogger) InfoFields(msg string, fields ...Field) {
+    f.Interface.InfoFields(msg, f.merge(fields)...)
+}
+
+func (f *fieldLogger) DebugFields(msg string, fields ...Field) {
+    f.Interface.DebugFields(msg, f.merge(fields)...)
+}
+
+func (f *fieldLogger) ErrorFields(msg string, fields ...Field) {
+    f.Interface.ErrorFields(msg, f.merge(fields)...)
+}
+
+// 丢弃所有日志的实现
+type nopLogger struct{}
+
+func (nopLogger) Info(v ...interface{})                             {}
+func (nopLogger) Debug(v ...interface{})                            {}
+func (nopLogger) Error(v ...interface{})                            {}
+func (nopLogger) InfoFields(msg string, fields ...Field)            {}
+func (nopLogger) DebugFields(msg string, fields ...Field)           {}
+func (nopLogger) ErrorFields(msg string, fields ...Field)           {}
+func (nopLogger) Count(name string, delta int64, fields ...Field)   {}
+func (nopLogger) Gauge(name string, value float64, fields ...Field) {}
+func (nopLogger) SetLevel(level string)                             {}
+func (nopLogger) Flush()                                            {}
+func (nopLogger) Close()                                            {}
@@ -0,0 +1,108 @@
+package jLogger
+
+import (
+    "fmt"
+    "strings"
+)
+
+// Named 创建一个模块Logger（共享管道，同CloneWith），模块名按点分层级拼接：
+// l.Named("server").Named("http") 的模块名为 server.http，每条记录带有module字段
+func (l *Logger) Named(name string, opts ...Option) *Logger {
+    clone := l.CloneWith(opts...)
+    if clone.module != "" {
+        clone.module += "." + name
+    } else {
+        clone.module = name
+    }
+    return clone
+}
+
+// SetModuleLevel 设置模块的日志级别，对该模块及其所有子模块生效，除非子模块自己也设置了级别（类似log4j的层级配置）。
+// 例如设置 server=DEBUG、server.http=ERROR 后，server.db 为DEBUG，server.http.handlers 为ERROR。
+// 级别无效时返回错误，不做修改
+func (l *Logger) SetModuleLevel(module, level string) error {
+    normalized, ok := normalizeLevel(level)
+    if !ok {
+        return fmt.Errorf("无效的日志级别: %s=%s", module, level)
+    }
+    root := l.pipeline()
+    root.levels_mu.Lock()
+    if root.moduleLevels == nil {
+        root.moduleLevels = make(map[string]string)
+    }
+    root.disownModule(module)
+    root.moduleLevels[module] = normalized
+    root.levels_mu.Unlock()
+    return nil
+}
+
+// 模块的实际级别：从自身开始逐级向上查找设置过的级别，都没有设置时使用句柄自己设置的级别，
+// 句柄也没有设置时依次使用 "*" 和根Logger的级别。根Logger的级别在NewLogger时设置，"*" 对它不生效
+func (l *Logger) effectiveLevel() string {
+    root := l.pipeline()
+    root.levels_mu.RLock()
+    defer root.levels_mu.RUnlock()
+    name := l.module
+    for name != "" {
+        if level, ok := root.moduleLevels[name]; ok {
+            return level
+        }
+        i := strings.LastIndexByte(name, '.')
+        if i < 0 {
+            break
+        }
+        name = name[:i]
+    }
+    if l.log_level != "" {
+        return l.log_level
+    }
+    // "*" 只是没有自己级别的模块和句柄的默认级别，不覆盖句柄上设置的级别
+    if level, ok := root.moduleLevels["*"]; ok {
+        return level
+    }
+    return root.log_level
+}
+
+// SetLevel 运行时修改当前Logger句柄的日志级别，并发安全。在根Logger上调用时，
+// 所有没有自己设置级别的克隆和模块Logger一起生效。级别名不区分大小写，WARN按ERROR处理；
+// 无效的级别名被忽略，保持原来的级别，并记一条内部错误
+func (l *Logger) SetLevel(level string) {
+    root := l.pipeline()
+    normalized, ok := normalizeLevel(level)
+    if !ok {
+        root.internalError("SetLevel忽略无效的日志级别:", level)
+        return
+    }
+    level = normalized
+    root.levels_mu.Lock()
+    l.log_level = level
+    root.levels_mu.Unlock()
+}
+
+// DEBUG时Info、Debug、Error都能写入；INFO时只有Info和Error；ERROR时只有Error
+func (l *Logger) enabled(level string) bool {
+    current := l.effectiveLevel()
+    switch level {
+    case "DEBUG":
+        return current == "DEBUG"
+    case "INFO":
+        return current == "INFO" || current == "DEBUG"
+    }
+    return true
+}
+
+// With 创建一个带固定字段的Logger句柄（共享管道，同CloneWith），每条记录都带上这些字段，
+// 例如按请求创建 l.With(jLogger.String("request_id", id))。多次调用时字段累加
+func (l *Logger) With(fields ...Field) *Logger {
+    clone := l.CloneWith()
+    clone.fields = append(append(make([]Field, 0, len(l.fields)+len(fields)), l.fields...), fields...)
+    return clone
+}
+
+// Tagged 创建一个带标签的Logger句柄（共享管道，同CloneWith），每条记录带有tag字段，
+// 用于区分并发的worker或goroutine的输出，例如 l.Tagged("worker-7")。再次调用Tagged会替换标签
+func (l *Logger) Tagged(tag string, opts ...Option) *Logger {
+    clone := l.CloneWith(opts...)
+    clone.tag = tag
+    return clone
+}
@@ -0,0 +1,119 @@
+package jLogger
+
+import (
+    "testing"
+    "time"
+)
+
+func newTestLogger(t *testing.T, level string, opts ...Option) *Logger {
+    t.Helper()
+    l, err := NewLogger(t.TempDir(), "app", 16, 10*time.Millisecond, level, opts...)
+    if err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(l.Close)
+    return l
+}
+
+func TestCloneFollowsRootLevel(t *testing.T) {
+    l := newTestLogger(t, "INFO")
+    child := l.Named("server").With(String("k", "v"))
+    pinned := l.CloneWith(WithLevel("ERROR"))
+    pinnedChild := pinned.Tagged("w1")
+
+    l.SetLevel("DEBUG")
+    if got := child.effectiveLevel(); got != "DEBUG" {
+        t.Fatalf("根Logger SetLevel后克隆的级别为%s, 期望DEBUG", got)
+    }
+    if got := pinned.effectiveLevel(); got != "ERROR" {
+        t.Fatalf("WithLevel设置的级别被覆盖为%s", got)
+    }
+    if got := pinnedChild.effectiveLevel(); got != "ERROR" {
+        t.Fatalf("从设置过级别的克隆再克隆, 级别为%s, 期望ERROR", got)
+    }
+
+    if err := l.ApplyRemoteConfig(RemoteConfig{Level: "error"}); err != nil {
+        t.Fatal(err)
+    }
+    if got := child.effectiveLevel(); got != "ERROR" {
+        t.Fatalf("远程配置的级别没有作用到克隆: %s", got)
+    }
+}
+
+func TestSetModuleLevelValidates(t *testing.T) {
+    l := newTestLogger(t, "INFO")
+    if err := l.SetModuleLevel("server", "verbose"); err == nil {
+        t.Fatal("无效的级别应返回错误")
+    }
+    if err := l.SetModuleLevel("server", "warn"); err != nil {
+        t.Fatal(err)
+    }
+    if got := l.Named("server").Named("db").effectiveLevel(); got != "ERROR" {
+        t.Fatalf("server.db的级别为%s, 期望ERROR", got)
+    }
+}
+
+func TestStarIsOnlyDefault(t *testing.T) {
+    l := newTestLogger(t, "INFO")
+    pinned := l.CloneWith(WithLevel("DEBUG"))
+    if err := l.SetModuleLevel("*", "ERROR"); err != nil {
+        t.Fatal(err)
+    }
+    if err := l.SetModuleLevel("db", "DEBUG"); err != nil {
+        t.Fatal(err)
+    }
+    cases := []struct {
+        l    *Logger
+        want string
+    }{
+        {l, "INFO"},
+        {pinned, "DEBUG"},
+        {l.Named("server"), "ERROR"},
+        {l.Named("db"), "DEBUG"},
+        {pinned.Named("server"), "DEBUG"},
+    }
+    for _, c := range cases {
+        if got := c.l.effectiveLevel(); got != c.want {
+            t.Errorf("module=%q 的级别为%s, 期望%s", c.l.module, got, c.want)
+        }
+    }
+}
+
+func TestSetLevelNormalizes(t *testing.T) {
+    l := newTestLogger(t, "INFO")
+    l.SetLevel(" debug ")
+    if got := l.effectiveLevel(); got != "DEBUG" {
+        t.Fatalf("SetLevel(\" debug \")后级别为%s, 期望DEBUG", got)
+    }
+    l.SetLevel("warn")
+    if got := l.effectiveLevel(); got != "ERROR" {
+        t.Fatalf("SetLevel(\"warn\")后级别为%s, 期望ERROR", got)
+    }
+    l.SetLevel("verbose")
+    if got := l.effectiveLevel(); got != "ERROR" {
+        t.Fatalf("无效的级别改变了级别: %s", got)
+    }
+    if errs := l.Stats().RecentErrors; len(errs) != 1 {
+        t.Fatalf("无效的级别记录了%d条内部错误, 期望1条", len(errs))
+    }
+}
+
+func TestReloadEnvKeepsRuntimeLevels(t *testing.T) {
+    t.Setenv(EnvLevels, "db=DEBUG,cache=DEBUG")
+    l := newTestLogger(t, "INFO")
+    db, cache := l.Named("db"), l.Named("cache")
+    if err := l.SetModuleLevel("db", "ERROR"); err != nil {
+        t.Fatal(err)
+    }
+
+    t.Setenv(EnvLevels, "")
+    if err := l.ReloadEnvLevels(); err != nil {
+        t.Fatal(err)
+    }
+    if got := db.effectiveLevel(); got != "ERROR" {
+        t.Fatalf("运行时设置的db级别被重新加载环境变量移除: %s", got)
+    }
+    if got := cache.effectiveLevel(); got != "INFO" {
+        t.Fatalf("环境变量设置的cache级别没有被移除: %s", got)
+    }
+}
@@ -0,0 +1,91 @@
+package jLogger
+
+import (
+    "io"
+    "log"
+    "os"
+    "path/filepath"
+    "sync"
+
+    "github.com/natefinch/lumberjack"
+)
+
+// 多进程模式下的日志文件写入：每次写入前对锁文件加排他锁，写入和轮转在进程之间串行进行。
+// 锁内先检查磁盘上的文件，被其他进程写入或轮转过时关闭重开，lumberjack按文件的实际大小继续计数，
+// 不会把整行拆开，也不会有两个进程各自轮转同一个文件
+type lockedWriter struct {
+    mu   sync.Mutex // 同一进程内由flock之外的锁保证互斥，flock在部分系统上是按进程计的
+    lj   *lumberjack.Logger
+    lock *os.File
+    last os.FileInfo // 本进程上次写入后文件的状态
+}
+
+// 锁文件放在日志目录下，以点开头，不匹配日志文件的命名，不会被保留策略和查询当作日志处理
+func newLockedWriter(lj *lumberjack.Logger) (*lockedWriter, error) {
+    dir, name := filepath.Split(lj.Filename)
+    lock, err := os.OpenFile(filepath.Join(dir, "."+name+".lock"), os.O_CREATE|os.O_RDWR, 0644)
+    if err != nil {
+        return nil, err
+    }
+    return &lockedWriter{lj: lj, lock: lock}, nil
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    if err := lockFile(w.lock); err != nil {
+        return 0, err
+    }
+    defer unlockFile(w.lock)
+
+    w.sync()
+    n, err := w.lj.Write(p)
+    w.last, _ = os.Stat(w.lj.Filename)
+    return n, err
+}
+
+// Rotate 持锁轮转，供Purge等调用
+func (w *lockedWriter) Rotate() error {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    if err := lockFile(w.lock); err != nil {
+        return err
+    }
+    defer unlockFile(w.lock)
+
+    w.sync()
+    err := w.lj.Rotate()
+    w.last, _ = os.Stat(w.lj.Filename)
+    return err
+}
+
+func (w *lockedWriter) Unwrap() io.Writer {
+    return w.lj
+}
+
+// 文件已不是上次写入后的样子（其他进程追加了内容或轮转了文件），关闭后由lumberjack在下次写入时重新打开
+func (w *lockedWriter) sync() {
+    if w.last == nil {
+        return
+    }
+    fi, err := os.Stat(w.lj.Filename)
+    if err != nil || !os.SameFile(fi, w.last) || fi.Size() != w.last.Size() {
+        w.lj.Close()
+    }
+}
+
+// 把三个级别的文件输出换成加锁写入，在NewLogger中、处理协程启动之前调用
+func (l *Logger) enableMultiProcess() error {
+    for _, logger := range []*log.Logger{l.InfoLogger, l.DebugLogger, l.ErrorLogger} {
+        lj, ok := logger.Writer().(*lumberjack.Logger)
+        if !ok {
+            continue
+        }
+        w, err := newLockedWriter(lj)
+        if err != nil {
+            return err
+        }
+        logger.SetOutput(w)
+    }
+    return nil
+}
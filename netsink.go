@@ -0,0 +1,287 @@
+package jLogger
+
+import (
+    "bytes"
+    "compress/gzip"
+    "crypto/tls"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net"
+    "net/http"
+    "sync"
+    "time"
+)
+
+// 网络Sink的默认超时
+const defaultSinkTimeout = 10 * time.Second
+
+// Compressor 创建压缩流，写入的数据压缩后写到w。Close时写完剩余数据；流式的TCP Sink在每批之后调用Flush（如果实现了）
+type Compressor func(w io.Writer) io.WriteCloser
+
+var (
+    compressors = map[string]Compressor{
+        "gzip": func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+    }
+    compressors_mu sync.RWMutex
+)
+
+// RegisterCompressor 注册压缩算法，name同时作为HTTP的Content-Encoding。内置gzip；
+// zstd、snappy等需要第三方库的算法由使用方注册，例如：
+// jLogger.RegisterCompressor("zstd", func(w io.Writer) io.WriteCloser { e, _ := zstd.NewWriter(w); return e })
+func RegisterCompressor(name string, c Compressor) {
+    compressors_mu.Lock()
+    compressors[name] = c
+    compressors_mu.Unlock()
+}
+
+func compressor(name string) (Compressor, error) {
+    if name == "" {
+        return nil, nil
+    }
+    compressors_mu.RLock()
+    c, ok := compressors[name]
+    compressors_mu.RUnlock()
+    if !ok {
+        return nil, fmt.Errorf("未注册的压缩算法: %s", name)
+    }
+    return c, nil
+}
+
+// 一批记录编码为JSON Lines
+func encodeBatch(w io.Writer, batch []Record) error {
+    enc := json.NewEncoder(w)
+    enc.SetEscapeHTML(false)
+    for _, r := range batch {
+        if err := enc.Encode(r); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// HTTPSinkOptions HTTP Sink的配置
+type HTTPSinkOptions struct {
+    Compression string        // 压缩算法，空表示不压缩
+    Header      http.Header   // 附加的请求头，如鉴权
+    Timeout     time.Duration // 单次请求超时，默认10秒
+    TLS         *SinkTLS      // https的证书配置，为nil时使用默认配置
+    Client      *http.Client  // 不为nil时使用该Client，Timeout和TLS不生效
+}
+
+// HTTPSink 每批记录以JSON Lines作为body POST到url。
+// 开启压缩时带Content-Encoding，服务端返回415（不支持该编码）时改为不压缩重发，之后该Sink不再压缩
+type HTTPSink struct {
+    url      string
+    opts     HTTPSinkOptions
+    client   *http.Client
+    compress Compressor
+    mu       sync.Mutex
+    identity bool // 服务端不接受压缩
+}
+
+// NewHTTPSink 创建HTTP Sink，通过AddSink挂载
+func NewHTTPSink(url string, opts HTTPSinkOptions) (*HTTPSink, error) {
+    c, err := compressor(opts.Compression)
+    if err != nil {
+        return nil, err
+    }
+    client := opts.Client
+    if client == nil {
+        timeout := opts.Timeout
+        if timeout <= 0 {
+            timeout = defaultSinkTimeout
+        }
+        client = &http.Client{Timeout: timeout}
+        if opts.TLS != nil {
+            cfg, err := opts.TLS.config()
+            if err != nil {
+                return nil, err
+            }
+            transport := http.DefaultTransport.(*http.Transport).Clone()
+            transport.TLSClientConfig = cfg
+            client.Transport = transport
+        }
+    }
+    return &HTTPSink{url: url, opts: opts, client: client, compress: c}, nil
+}
+
+func (s *HTTPSink) WriteBatch(batch []Record) error {
+    var body bytes.Buffer
+    if err := encodeBatch(&body, batch); err != nil {
+        return err
+    }
+
+    s.mu.Lock()
+    identity := s.identity || s.compress == nil
+    s.mu.Unlock()
+    if !identity {
+        var zbody bytes.Buffer
+        zw := s.compress(&zbody)
+        if _, err := zw.Write(body.Bytes()); err != nil {
+            return err
+        }
+        if err := zw.Close(); err != nil {
+            return err
+        }
+        status, err := s.post(&zbody, s.opts.Compression)
+        if err != nil || status != http.StatusUnsupportedMediaType {
+            return err
+        }
+        s.mu.Lock()
+        s.identity = true
+        s.mu.Unlock()
+    }
+    _, err := s.post(&body, "")
+    return err
+}
+
+// 发送一次请求，返回状态码；415单独返回给调用方处理，其余非2xx作为错误
+func (s *HTTPSink) post(body *bytes.Buffer, encoding string) (int, error) {
+    req, err := http.NewRequest(http.MethodPost, s.url, body)
+    if err != nil {
+        return 0, err
+    }
+    for k, v := range s.opts.Header {
+        req.Header[k] = v
+    }
+    req.Header.Set("Content-Type", "application/x-ndjson")
+    if encoding != "" {
+        req.Header.Set("Content-Encoding", encoding)
+    }
+    resp, err := s.client.Do(req)
+    if err != nil {
+        return 0, err
+    }
+    io.Copy(io.Discard, resp.Body)
+    resp.Body.Close()
+    if resp.StatusCode == http.StatusUnsupportedMediaType && encoding != "" {
+        return resp.StatusCode, nil
+    }
+    if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+        return resp.StatusCode, fmt.Errorf("HTTP Sink返回 %s", resp.Status)
+    }
+    return resp.StatusCode, nil
+}
+
+func (s *HTTPSink) Close() error {
+    s.client.CloseIdleConnections()
+    return nil
+}
+
+// TCPSinkOptions TCP Sink的配置
+type TCPSinkOptions struct {
+    Compression string        // 压缩算法，空表示不压缩；开启后整个连接是一个压缩流，每批之后Flush
+    Timeout     time.Duration // 连接和写入超时，默认10秒
+    TLS         *SinkTLS      // 不为nil时通过TLS连接
+}
+
+// TCPSink 把记录以JSON Lines写到TCP连接上。连接在第一次写入时建立，写入失败时断开，下一批重新连接
+type TCPSink struct {
+    addr     string
+    opts     TCPSinkOptions
+    compress Compressor
+    encode   func(w io.Writer, batch []Record) error // 一批记录的编码，默认JSON Lines
+    tls      *tls.Config
+    mu       sync.Mutex
+    conn     net.Conn
+    zw       io.WriteCloser
+}
+
+// NewTCPSink 创建TCP Sink，通过AddSink挂载
+func NewTCPSink(addr string, opts TCPSinkOptions) (*TCPSink, error) {
+    c, err := compressor(opts.Compression)
+    if err != nil {
+        return nil, err
+    }
+    if opts.Timeout <= 0 {
+        opts.Timeout = defaultSinkTimeout
+    }
+    s := &TCPSink{addr: addr, opts: opts, compress: c, encode: encodeBatch}
+    if opts.TLS != nil {
+        if s.tls, err = opts.TLS.config(); err != nil {
+            return nil, err
+        }
+    }
+    return s, nil
+}
+
+func (s *TCPSink) dial() (net.Conn, error) {
+    dialer := &net.Dialer{Timeout: s.opts.Timeout}
+    if s.tls != nil {
+        return tls.DialWithDialer(dialer, "tcp", s.addr, s.tls)
+    }
+    return dialer.Dial("tcp", s.addr)
+}
+
+func (s *TCPSink) WriteBatch(batch []Record) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if s.conn == nil {
+        conn, err := s.dial()
+        if err != nil {
+            return err
+        }
+        s.conn = conn
+        if s.compress != nil {
+            s.zw = s.compress(conn)
+        }
+    }
+
+    s.conn.SetWriteDeadline(time.Now().Add(s.opts.Timeout))
+    var w io.Writer = s.conn
+    if s.zw != nil {
+        w = s.zw
+    }
+    bw := newBufferedBatch(w)
+    err := s.encode(bw, batch)
+    if err == nil {
+        err = bw.flush()
+    }
+    if err == nil && s.zw != nil {
+        if f, ok := s.zw.(interface{ Flush() error }); ok {
+            err = f.Flush()
+        }
+    }
+    if err != nil {
+        s.reset()
+    }
+    return err
+}
+
+// 断开连接，丢弃压缩流的状态，下一批重新连接
+func (s *TCPSink) reset() {
+    if s.conn != nil {
+        s.conn.Close()
+    }
+    s.conn, s.zw = nil, nil
+}
+
+func (s *TCPSink) Close() error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if s.conn == nil {
+        return nil
+    }
+    if s.zw != nil {
+        s.zw.Close()
+    }
+    err := s.conn.Close()
+    s.conn, s.zw = nil, nil
+    return err
+}
+
+// 先把整批编码到内存再一次写出，减少小包
+type bufferedBatch struct {
+    bytes.Buffer
+    w io.Writer
+}
+
+func newBufferedBatch(w io.Writer) *bufferedBatch {
+    return &bufferedBatch{w: w}
+}
+
+func (b *bufferedBatch) flush() error {
+    _, err := b.w.Write(b.Bytes())
+    return err
+}
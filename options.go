@@ -0,0 +1,421 @@
+package jLogger
+
+import "time"
+
+// Option 用于在NewLogger时调整Logger的可选配置，不传则保持原有默认行为
+type Option func(*Logger)
+
+// WithEscape 开启后，消息内容中的换行、回车以及ANSI/控制字符会被转义成可见的 \n、\x1b 等形式，
+// 防止用户输入伪造出额外的日志行，或者在终端查看日志时被控制序列篡改显示
+func WithEscape(enable bool) Option {
+    return func(l *Logger) {
+        l.escape = enable
+    }
+}
+
+
+// WithMaxMessageBytes 设置单条消息的最大字节数，超出部分被截断并追加 "...(truncated, N bytes)"，
+// 避免一次误打印的超大payload撑爆日志文件和下游采集的单行限制。n <= 0 表示不限制。
+// JSON格式下限制的是整条记录编码后的字节数：放不下的字段整个丢弃，msg被截断，并附加truncated字段（截断前的字节数）
+func WithMaxMessageBytes(n int) Option {
+    return func(l *Logger) {
+        l.maxMessageBytes = n
+    }
+}
+
+// WithJSON 以JSON Lines格式输出，每行一个对象：{"time":...,"level":...,"msg":...,字段...}，
+// 和time、level、msg、v同名的字段输出为 fields.msg 这样的key
+func WithJSON() Option {
+    return func(l *Logger) {
+        l.json = true
+    }
+}
+
+// WithFieldOrder 指定结构化字段的固定输出顺序，列出的key排在最前，未列出的按插入顺序跟在后面
+func WithFieldOrder(keys ...string) Option {
+    return func(l *Logger) {
+        l.fieldOrder = keys
+    }
+}
+
+
+// WithSequence 在每条记录上输出seq字段，序号在生产者侧原子递增分配，
+// 消费方可以据此发现异步管道中的丢失和乱序；跨进程汇总时配合进程标识使用
+func WithSequence(enable bool) Option {
+    return func(l *Logger) {
+        l.emitSeq = enable
+    }
+}
+
+
+// WithOrdered 开启严格有序模式：序号在记录进入管道时分配，刷新时只写出序号更小的记录都已经到达缓冲区的部分，
+// 仍在通道中的记录之后的记录留到下一次刷新；通道已满时记录也进入缓冲区而不是直接同步写文件。
+// 保证每个级别的文件内记录按序号（进入管道的顺序）排列。代价是每条记录多一次加锁，通道已满时调用方需要短暂等待缓冲区的锁
+func WithOrdered() Option {
+    return func(l *Logger) {
+        l.ordered = true
+    }
+}
+
+
+// WithMergedFlush 三个级别不再各自独立刷新，而是合并为一条按时间排序的流统一写入，
+// 适合需要把多个级别的输出按真实先后关系对照查看的场景
+func WithMergedFlush() Option {
+    return func(l *Logger) {
+        l.merged = true
+    }
+}
+
+
+// WithSpool 启用磁盘预写spool（logDir/<prefix>.spool），已进入通道、缓冲区但还没写入日志文件的记录在进程崩溃后不会丢失。
+// 每条记录在调用方协程中多一次文件追加写，换取崩溃安全
+func WithSpool() Option {
+    return func(l *Logger) {
+        l.useSpool = true
+    }
+}
+
+
+// WithOnRecover 启用spool时，NewLogger回放上次异常退出遗留的记录后调用fn，n为恢复的记录数（没有遗留时为0）
+func WithOnRecover(fn func(n int)) Option {
+    return func(l *Logger) {
+        l.onRecover = fn
+    }
+}
+
+
+// WithOnDrop 日志通道已满导致记录被丢弃或转为同步写入时回调fn，count为该级别自上次回调以来新增的条数。
+// 回调在定时刷新时汇总执行（每个刷新间隔最多一次），不会阻塞写日志的协程，可用于上报指标或触发降级告警
+func WithOnDrop(fn func(level string, count int)) Option {
+    return func(l *Logger) {
+        l.onDrop = fn
+    }
+}
+
+
+// WithAdaptiveFlush 根据负载在[min, max]之间自动调整刷新间隔：缓冲区持续接近写满时缩短，持续空闲时延长，
+// 让日志可见的延迟有上限，同时空闲时不会频繁地做小批量写入。初始间隔仍为NewLogger传入的flushInterval
+func WithAdaptiveFlush(min, max time.Duration) Option {
+    return func(l *Logger) {
+        if min <= 0 || max < min {
+            return
+        }
+        l.adaptiveFlush = true
+        l.minFlushInterval = min
+        l.maxFlushInterval = max
+    }
+}
+
+
+// WithErrorFlushDelay 设置Error记录在缓冲区中的最长停留时间（默认100ms），不受bufferSize限制。
+// d为0时每条Error记录都立即刷新；d小于0时关闭该快速通道，Error和其他级别一样批量刷新
+func WithErrorFlushDelay(d time.Duration) Option {
+    return func(l *Logger) {
+        l.errorFlushDelay = d
+    }
+}
+
+
+// WithCloseTimeout 设置Close时执行RegisterOnClose回调的总超时
+func WithCloseTimeout(d time.Duration) Option {
+    return func(l *Logger) {
+        if d > 0 {
+            l.closeTimeout = d
+        }
+    }
+}
+
+
+// WithLevel 覆盖日志级别（DEBUG、INFO、ERROR），常用于CloneWith
+func WithLevel(level string) Option {
+    return func(l *Logger) {
+        l.log_level = level
+    }
+}
+
+// WithPrefix 在每条消息最前面加上前缀，常用于CloneWith为请求或任务打标记，例如 WithPrefix("[job-42]")
+func WithPrefix(prefix string) Option {
+    return func(l *Logger) {
+        l.prefix = prefix
+    }
+}
+
+
+// WithErrorFingerprint 为每条Error记录输出fingerprint字段（错误类型 + 归一化消息 + 调用函数的哈希），
+// 下游可以据此把相同的故障归为一组。开启后Error调用会多一次调用栈捕获
+func WithErrorFingerprint() Option {
+    return func(l *Logger) {
+        l.errorFingerprint = true
+    }
+}
+
+
+// WithSampleRate 设置初始的Info/Debug采样比例，见SetSampleRate
+func WithSampleRate(rate float64) Option {
+    return func(l *Logger) {
+        l.sampleThreshold = sampleThreshold(rate)
+    }
+}
+
+
+// WithRetention 启用跨文件的保留策略：总大小上限、按级别的归档保留时间，定期在后台执行
+func WithRetention(policy RetentionPolicy) Option {
+    return func(l *Logger) {
+        l.retention = &policy
+    }
+}
+
+
+// WithIndex 在后台为轮转出的归档维护时间范围索引（<文件>.idx），every为偏移标记的间隔（条），<=0时使用默认值1000
+func WithIndex(every int) Option {
+    return func(l *Logger) {
+        if every <= 0 {
+            every = defaultIndexEvery
+        }
+        l.indexEvery = every
+    }
+}
+
+
+// WithSchemaValidation 开启字段约定校验：记录的字段不符合RegisterSchema登记的约定时，附加schema_violation字段，
+// 计入Stats，并在该消息第一次违反时记一条内部错误。校验在调用方协程中进行，有额外开销，适合在测试和预发环境开启
+func WithSchemaValidation() Option {
+    return func(l *Logger) {
+        l.validateSchema = true
+    }
+}
+
+
+// WithLevelLabels 替换写入文件的级别文字（文本格式的行首前缀和JSON的level字段），例如
+// WithLevelLabels(map[string]string{"ERROR": "WARNING", "INFO": "信息"})，内部的级别和级别设置不受影响。
+// 无效的级别名被忽略
+func WithLevelLabels(labels map[string]string) Option {
+    return func(l *Logger) {
+        l.levelLabels = make(map[string]string, len(labels))
+        for level, label := range labels {
+            if level, ok := normalizeLevel(level); ok {
+                l.levelLabels[level] = label
+            }
+        }
+    }
+}
+
+
+// WithRecent 在内存中保留最近的n条记录（所有级别，不受级别过滤影响），通过Recent读取
+func WithRecent(n int) Option {
+    return func(l *Logger) {
+        if n > 0 {
+            l.recent = newRing(n)
+        }
+    }
+}
+
+
+// WithRuntimeMetrics 每隔interval（<=0时为1分钟）以level级别写一条runtime记录：goroutine数、堆内存、
+// GC次数和停顿、打开的文件描述符数，没有监控系统的环境也能从日志中看到基本的健康状况
+func WithRuntimeMetrics(interval time.Duration, level string) Option {
+    return func(l *Logger) {
+        if interval <= 0 {
+            interval = defaultRuntimeMetricsInterval
+        }
+        l.runtimeInterval = interval
+        l.runtimeLevel, _ = normalizeLevel(level)
+    }
+}
+
+
+// WithHeartbeat 每隔interval（<=0时为5分钟）向每个日志文件写一条心跳记录（alive uptime=... dropped=...），
+// 不受级别过滤影响，日志新鲜度监控可以据此区分"服务空闲"和"日志写入故障"
+func WithHeartbeat(interval time.Duration) Option {
+    return func(l *Logger) {
+        if interval <= 0 {
+            interval = defaultHeartbeatInterval
+        }
+        l.heartbeatInterval = interval
+    }
+}
+
+
+// WithErrorBudget 开启Error条数的预算告警，例如每小时超过100条时通知值班，回落后再通知恢复。
+// 回调在后台协程中调用，最长延迟 Window/60；当前状态可以通过Stats查看
+func WithErrorBudget(budget ErrorBudget) Option {
+    return func(l *Logger) {
+        l.budget = newErrorBudget(budget)
+    }
+}
+
+
+// WithEscalation 设置级别提升规则，例如包含"deadlock"的Debug记录、或带有critical=true字段的记录提升为Error，
+// 写入对应级别的文件并触发Error的及时刷新。规则在级别过滤之前执行，没有配置规则时没有额外开销
+func WithEscalation(rules ...EscalationRule) Option {
+    return func(l *Logger) {
+        l.escalation = nil
+        for _, r := range rules {
+            level, ok := normalizeLevel(r.Level)
+            if !ok {
+                level = "ERROR"
+            }
+            r.Level = level
+            l.escalation = append(l.escalation, r)
+        }
+    }
+}
+
+
+// WithLifecycleMarkers 在NewLogger时向每个日志文件写一条START记录（版本、配置hash、pid），
+// Close时写一条STOP记录（原因、运行时长、丢弃条数），运维和工具可以据此划分每次进程运行的日志范围
+func WithLifecycleMarkers(version string) Option {
+    return func(l *Logger) {
+        l.markers = true
+        l.appVersion = version
+    }
+}
+
+
+// WithConfigDump 在NewLogger、ReloadEnvLevels和ApplyRemoteConfig之后，把生效的配置（见EffectiveConfig）
+// 作为一条"config"记录写入Info文件，不受级别过滤影响
+func WithConfigDump() Option {
+    return func(l *Logger) {
+        l.configDump = true
+    }
+}
+
+
+// WithClock 替换事件时间的时钟，默认time.Now。用于测试中固定时间，或者使用单调校准过的时间源
+func WithClock(now func() time.Time) Option {
+    return func(l *Logger) {
+        l.clock = now
+    }
+}
+
+// WithWriteTime 每条记录额外带一个write_time字段，为写入文件（刷新缓冲区）时的时间，
+// time字段仍是调用日志方法时的事件时间，两者的差值即异步管道引入的延迟
+func WithWriteTime() Option {
+    return func(l *Logger) {
+        l.writeTime = true
+    }
+}
+
+
+// WithMemoryLimit 限制通道和缓冲区中排队记录占用的内存（按内容长度估算，单位字节），
+// 超出时按policy处理：MemoryFallback在调用方协程中直接写文件，MemoryDrop丢弃Info/Debug，
+// Error记录从不丢弃。超限计入Stats的Dropped和MemoryRejected，每次进入超限状态时写一条内部错误。
+// 适合内存很小的容器；bytes<=0表示不限
+func WithMemoryLimit(bytes int64, policy MemoryPolicy) Option {
+    return func(l *Logger) {
+        if bytes < 0 {
+            bytes = 0
+        }
+        l.memoryLimit = bytes
+        l.memoryPolicy = policy
+    }
+}
+
+
+// WithDegradation 启用持续过载时的自动降级，见Degradation
+func WithDegradation(d Degradation) Option {
+    return func(l *Logger) {
+        l.degrade = newDegrader(d)
+    }
+}
+
+
+// WithMultiProcess 多个进程以同一个前缀写同一个目录时使用：每次写入和轮转都对日志目录下的锁文件加排他锁，
+// 不会出现半行交错，轮转也只由一个进程执行。每次写入多两次stat，单进程时不需要开启
+func WithMultiProcess() Option {
+    return func(l *Logger) {
+        l.multiProcess = true
+    }
+}
+
+
+// WithAfterClose 设置Close之后仍有日志写入时的处理方式，默认AfterCloseWrite
+func WithAfterClose(policy AfterClosePolicy) Option {
+    return func(l *Logger) {
+        l.afterClose = policy
+    }
+}
+
+
+// WithSharedFiles 同一进程中已有Logger以相同的目录和前缀打开了日志文件时，共享它的文件输出，
+// 不设置时NewLogger返回错误。共享的Logger各自缓冲和刷新，文件的写入和轮转由同一个lumberjack.Logger完成
+func WithSharedFiles() Option {
+    return func(l *Logger) {
+        l.shareFiles = true
+    }
+}
+
+
+// WithCRLF 日志文件的行尾使用\r\n，便于Windows上的记事本等工具查看。查询、搜索等读取工具同时兼容两种行尾
+func WithCRLF() Option {
+    return func(l *Logger) {
+        l.crlf = true
+    }
+}
+
+// WithPortableNames 在所有平台上都按Windows的规则处理日志文件前缀（见SanitizePrefix），
+// 同一份配置在各平台上得到同样的文件名。Windows上总是处理，不需要设置
+func WithPortableNames() Option {
+    return func(l *Logger) {
+        l.portableNames = true
+    }
+}
+
+// WithDeadLetter Sink长时间不可用、积压超限或Close时仍不可用而放弃投递的记录，连同原因追加到path（JSON Lines），
+// 不会无声丢失。Sink恢复后用 l.ReprocessDeadLetters 重新投递
+func WithDeadLetter(path string) Option {
+    return func(l *Logger) {
+        l.deadLetters = &deadLetterFile{path: path}
+    }
+}
+
+// WithStdFlags 按级别改用标准库log的标志格式化文本格式的行首，例如
+// WithStdFlags(map[string]int{"ERROR": log.LstdFlags | log.Lmicroseconds | log.Lshortfile, "DEBUG": log.Ltime})，
+// 支持Ldate、Ltime、Lmicroseconds、LUTC、Lshortfile和Llongfile，为0时该级别不输出时间。
+// 未配置的级别保持jLogger自己的时间格式；JSON格式不受影响。查询和索引只识别jLogger的时间格式，
+// 改用标准库格式的级别在查询时没有时间。无效的级别名被忽略
+func WithStdFlags(flags map[string]int) Option {
+    return func(l *Logger) {
+        l.stdFlags = make(map[string]int, len(flags))
+        for level, f := range flags {
+            if level, ok := normalizeLevel(level); ok {
+                l.stdFlags[level] = f & stdFlagsMask
+            }
+        }
+    }
+}
+
+// WithNestedGroups JSON格式中Group字段（包括slog的分组）输出为嵌套的对象，例如 {"http":{"method":"GET"}}，
+// 默认展开为带点的key {"http.method":"GET"}。文本格式总是展开
+func WithNestedGroups() Option {
+    return func(l *Logger) {
+        l.nestedGroups = true
+    }
+}
+
+// WithRecordID 在调用日志方法时为每条记录生成唯一ID，输出为log_id字段，Sink和Subscribe收到的Record中为LogID。
+// 便于在工单中引用某一行日志，以及在可能重复投递的下游按ID去重。gen为nil时使用NewULID，也可以传NewUUIDv7或自定义的生成函数
+func WithRecordID(gen IDGenerator) Option {
+    return func(l *Logger) {
+        if gen == nil {
+            gen = NewULID
+        }
+        l.newID = gen
+    }
+}
+
+// WithDailyRotation 每天在loc时区的hour点（0为零点）轮转所有级别的当前文件，和按大小轮转同时生效，
+// 日志的日界线按业务所在地区而不是主机时区划分。loc为nil时使用主机本地时区，hour超出0-23时按0处理。
+// 轮转时间每次按loc的日历计算，夏令时切换的日子也在当地整点轮转。没有内容的文件不轮转；
+// 归档文件名中的时间仍由lumberjack按主机本地时间生成
+func WithDailyRotation(loc *time.Location, hour int) Option {
+    return func(l *Logger) {
+        if loc == nil {
+            loc = time.Local
+        }
+        if hour < 0 || hour > 23 {
+            hour = 0
+        }
+        l.daily = &dailyRotation{loc: loc, hour: hour}
+    }
+}
@@ -0,0 +1,71 @@
+package jLogger
+
+import (
+    "bufio"
+    "encoding/json"
+    "os"
+    "sync"
+    "testing"
+    "time"
+)
+
+func TestOrderedStrictPerFile(t *testing.T) {
+    dir := t.TempDir()
+    // 缓冲区为1时每条记录到达都会触发刷新，序号更小的记录晚到一步就会乱序
+    l, err := NewLogger(dir, "app", 1, 10*time.Millisecond, "DEBUG", WithJSON(), WithSequence(true), WithOrdered())
+    if err != nil {
+        t.Fatal(err)
+    }
+    // 总数超过通道容量，一部分记录会走通道已满时的缓冲区路径
+    const workers, n = 8, 3000
+    var wg sync.WaitGroup
+    for w := 0; w < workers; w++ {
+        wg.Add(1)
+        go func(w int) {
+            defer wg.Done()
+            for i := 0; i < n; i++ {
+                switch i % 3 {
+                case 0:
+                    l.Info("info", w, i)
+                case 1:
+                    l.Debug("debug", w, i)
+                default:
+                    l.Error("error", w, i)
+                }
+            }
+        }(w)
+    }
+    wg.Wait()
+    l.Close()
+
+    files, err := LogFiles(dir, "app")
+    if err != nil {
+        t.Fatal(err)
+    }
+    total := 0
+    for _, file := range files {
+        f, err := os.Open(file)
+        if err != nil {
+            t.Fatal(err)
+        }
+        var last uint64
+        sc := bufio.NewScanner(f)
+        for sc.Scan() {
+            var rec struct {
+                Seq uint64 `json:"seq"`
+            }
+            if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+                t.Fatalf("%s: %v", file, err)
+            }
+            if rec.Seq <= last {
+                t.Fatalf("%s: seq %d 出现在 %d 之后", file, rec.Seq, last)
+            }
+            last = rec.Seq
+            total++
+        }
+        f.Close()
+    }
+    if total != workers*n {
+        t.Fatalf("写入%d条记录, 期望%d条", total, workers*n)
+    }
+}
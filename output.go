@@ -0,0 +1,126 @@
+package jLogger
+
+import (
+    "fmt"
+    "io"
+    "log"
+    "sync"
+)
+
+// SetOutput 运行时替换某个级别的输出目标，例如磁盘故障时改写到os.Stderr，并发安全。
+// 替换时持有该级别的刷新锁，正在写入的一批记录全部写完才切换，不会一半写到旧目标、一半写到新目标。
+// 返回原来的输出目标，恢复时再传回来即可；原目标不会被关闭。通过Tee挂载的副本不受影响
+func (l *Logger) SetOutput(level string, w io.Writer) (io.Writer, error) {
+    logger, flushMu, err := l.pipeline().levelOutput(level)
+    if err != nil {
+        return nil, err
+    }
+    flushMu.Lock()
+    defer flushMu.Unlock()
+    if tw, ok := logger.Writer().(*teeWriter); ok {
+        return tw.setPrimary(w), nil
+    }
+    old := logger.Writer()
+    logger.SetOutput(w)
+    return old, nil
+}
+
+// Tee 给某个级别挂载一个副本输出，写入文件的每一行同时写给w，例如把本次请求期间的Error同时收集到内存中。
+// 副本写入失败不影响主输出。返回的remove用于卸载，卸载后w不会再被写入
+func (l *Logger) Tee(level string, w io.Writer) (remove func(), err error) {
+    logger, flushMu, err := l.pipeline().levelOutput(level)
+    if err != nil {
+        return nil, err
+    }
+    flushMu.Lock()
+    tw, ok := logger.Writer().(*teeWriter)
+    if !ok {
+        tw = &teeWriter{primary: logger.Writer()}
+        logger.SetOutput(tw)
+    }
+    flushMu.Unlock()
+
+    target := &teeTarget{w: w}
+    tw.add(target)
+    var once sync.Once
+    return func() {
+        once.Do(func() { tw.remove(target) })
+    }, nil
+}
+
+// TeeError 同Tee，挂载到Error级别
+func (l *Logger) TeeError(w io.Writer) (remove func()) {
+    remove, _ = l.Tee("ERROR", w)
+    return remove
+}
+
+// 级别对应的log.Logger和刷新锁
+func (l *Logger) levelOutput(level string) (*log.Logger, *sync.Mutex, error) {
+    name, ok := normalizeLevel(level)
+    if !ok {
+        return nil, nil, fmt.Errorf("无效的日志级别: %q", level)
+    }
+    switch name {
+    case "INFO":
+        return l.InfoLogger, &l.info_flush_mu, nil
+    case "DEBUG":
+        return l.DebugLogger, &l.debug_flush_mu, nil
+    }
+    return l.ErrorLogger, &l.error_flush_mu, nil
+}
+
+type teeTarget struct {
+    w io.Writer
+}
+
+// 主输出加副本输出。log.Logger已经保证每次只有一个Write，这里的锁只用于挂载和卸载副本
+type teeWriter struct {
+    mu        sync.RWMutex
+    primary   io.Writer
+    secondary []*teeTarget
+}
+
+func (t *teeWriter) Write(p []byte) (int, error) {
+    t.mu.RLock()
+    defer t.mu.RUnlock()
+    n, err := t.primary.Write(p)
+    for _, s := range t.secondary {
+        s.w.Write(p)
+    }
+    return n, err
+}
+
+// 主输出是lumberjack时转发轮转，Purge等依赖轮转的功能照常工作
+func (t *teeWriter) Rotate() error {
+    t.mu.RLock()
+    defer t.mu.RUnlock()
+    if r, ok := t.primary.(interface{ Rotate() error }); ok {
+        return r.Rotate()
+    }
+    return nil
+}
+
+func (t *teeWriter) setPrimary(w io.Writer) io.Writer {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    old := t.primary
+    t.primary = w
+    return old
+}
+
+func (t *teeWriter) add(target *teeTarget) {
+    t.mu.Lock()
+    t.secondary = append(t.secondary, target)
+    t.mu.Unlock()
+}
+
+func (t *teeWriter) remove(target *teeTarget) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    for i, s := range t.secondary {
+        if s == target {
+            t.secondary = append(t.secondary[:i:i], t.secondary[i+1:]...)
+            return
+        }
+    }
+}
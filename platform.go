@@ -0,0 +1,100 @@
+package jLogger
+
+import (
+    "bytes"
+    "io"
+    "log"
+    "path/filepath"
+    "runtime"
+    "strings"
+
+    "github.com/natefinch/lumberjack"
+)
+
+// Windows文件名中不允许的字符
+const reservedNameChars = `<>:"/\|?*`
+
+// Windows的保留设备名，不区分大小写，带扩展名也不行（如 CON.log）
+var reservedNames = map[string]bool{
+    "CON": true, "PRN": true, "AUX": true, "NUL": true,
+    "COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+    "LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// SanitizePrefix 把日志文件前缀转换为在Windows和其他系统上都合法的文件名：保留字符和控制字符替换为下划线，
+// 去掉结尾的点和空格，保留设备名（CON、NUL、COM1等）后加下划线。合法的前缀原样返回
+func SanitizePrefix(prefix string) string {
+    s := strings.Map(func(r rune) rune {
+        if r < 0x20 || strings.ContainsRune(reservedNameChars, r) {
+            return '_'
+        }
+        return r
+    }, prefix)
+    s = strings.TrimRight(s, ". ")
+    if reservedNames[strings.ToUpper(s)] {
+        s += "_"
+    }
+    if s == "" {
+        s = "_"
+    }
+    return s
+}
+
+// Windows上总是处理前缀，其他系统在开启WithPortableNames时处理，保证同一份配置在各平台得到同样的文件名。
+// NewLogger中、文件打开之前调用（lumberjack在第一次写入时才打开文件）
+func (l *Logger) sanitizeFileNames() {
+    if runtime.GOOS != "windows" && !l.portableNames {
+        return
+    }
+    prefix := SanitizePrefix(l.logPrefix)
+    if prefix == l.logPrefix {
+        return
+    }
+    for _, logger := range []*log.Logger{l.InfoLogger, l.DebugLogger, l.ErrorLogger} {
+        if lj, ok := logger.Writer().(*lumberjack.Logger); ok {
+            suffix := strings.TrimPrefix(lj.Filename, filepath.Join(l.logDir, l.logPrefix))
+            lj.Filename = filepath.Join(l.logDir, prefix+suffix)
+        }
+    }
+    l.logPrefix = prefix
+}
+
+// 把每行结尾的\n换成\r\n，消息中（未开启转义时）的换行同样处理。log.Logger每条记录调用一次Write
+type crlfWriter struct {
+    w io.Writer
+}
+
+func (c *crlfWriter) Write(p []byte) (int, error) {
+    // 已经是\r\n的先还原，避免变成\r\r\n
+    out := bytes.ReplaceAll(bytes.ReplaceAll(p, []byte("\r\n"), []byte("\n")), []byte("\n"), []byte("\r\n"))
+    if _, err := c.w.Write(out); err != nil {
+        return 0, err
+    }
+    return len(p), nil
+}
+
+func (c *crlfWriter) Rotate() error {
+    if r, ok := c.w.(interface{ Rotate() error }); ok {
+        return r.Rotate()
+    }
+    return nil
+}
+
+func (c *crlfWriter) Unwrap() io.Writer {
+    return c.w
+}
+
+// 按平台和选项包装三个级别的文件输出，在文件锁和共享文件处理之后调用
+func (l *Logger) wrapFileOutputs() {
+    for _, logger := range []*log.Logger{l.InfoLogger, l.DebugLogger, l.ErrorLogger} {
+        logger.SetOutput(l.wrapOutput(logger.Writer()))
+    }
+}
+
+func (l *Logger) wrapOutput(w io.Writer) io.Writer {
+    w = wrapRotation(w)
+    if l.crlf {
+        w = &crlfWriter{w: w}
+    }
+    return w
+}
@@ -0,0 +1,77 @@
+package jLogger
+
+import (
+    "bytes"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestSanitizePrefix(t *testing.T) {
+    cases := map[string]string{
+        "app":       "app",
+        "CON":       "CON_",
+        "nul":       "nul_",
+        "com1":      "com1_",
+        "COM10":     "COM10",
+        "a:b/c*d":   "a_b_c_d",
+        "app. ":     "app",
+        "tab\there": "tab_here",
+        "...":       "_",
+    }
+    for in, want := range cases {
+        if got := SanitizePrefix(in); got != want {
+            t.Errorf("SanitizePrefix(%q) = %q, 期望 %q", in, got, want)
+        }
+    }
+}
+
+func TestPortableNames(t *testing.T) {
+    dir := t.TempDir()
+    l, err := NewLogger(dir, "CON", 16, 10*time.Millisecond, "INFO", WithPortableNames())
+    if err != nil {
+        t.Fatal(err)
+    }
+    l.Info("hello")
+    l.Close()
+    if _, err := os.Stat(filepath.Join(dir, "CON__info.log")); err != nil {
+        t.Fatalf("保留设备名前缀没有处理: %v", err)
+    }
+}
+
+func TestCRLFWriter(t *testing.T) {
+    var buf bytes.Buffer
+    w := &crlfWriter{w: &buf}
+    in := "a\nb\r\nc\n"
+    n, err := w.Write([]byte(in))
+    if err != nil || n != len(in) {
+        t.Fatalf("Write = %d, %v", n, err)
+    }
+    if got := buf.String(); got != "a\r\nb\r\nc\r\n" {
+        t.Fatalf("输出为 %q", got)
+    }
+}
+
+func TestCRLFOutput(t *testing.T) {
+    dir := t.TempDir()
+    l, err := NewLogger(dir, "app", 16, 10*time.Millisecond, "INFO", WithCRLF())
+    if err != nil {
+        t.Fatal(err)
+    }
+    l.Info("one")
+    l.Info("two")
+    l.Close()
+    data, err := os.ReadFile(filepath.Join(dir, "app_info.log"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    s := string(data)
+    if strings.Count(s, "\r\n") != strings.Count(s, "\n") || strings.Count(s, "\r\n") < 2 {
+        t.Fatalf("行尾不是\\r\\n: %q", s)
+    }
+    if strings.Contains(s, "\r\r\n") {
+        t.Fatalf("出现了重复的\\r: %q", s)
+    }
+}
@@ -0,0 +1,27 @@
+package jLogger
+
+import (
+    "sync/atomic"
+    "time"
+)
+
+// Error记录默认最多在缓冲区停留100ms，运维希望错误尽快落盘，Info/Debug仍然按bufferSize和flushInterval批量写入
+const defaultErrorFlushDelay = 100 * time.Millisecond
+
+// Error记录进入缓冲区后调用：需要立即刷新时返回true；否则在errorFlushDelay之后安排一次刷新，
+// 同一时间只保留一个待执行的刷新，延迟期间到达的Error记录会被同一批写入
+func (l *Logger) scheduleErrorFlush() bool {
+    if l.errorFlushDelay < 0 {
+        return false
+    }
+    if l.errorFlushDelay == 0 {
+        return true
+    }
+    if atomic.CompareAndSwapInt32(&l.errorFlushPending, 0, 1) {
+        time.AfterFunc(l.errorFlushDelay, func() {
+            atomic.StoreInt32(&l.errorFlushPending, 0)
+            l.flushErrorBuffer()
+        })
+    }
+    return false
+}
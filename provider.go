@@ -0,0 +1,45 @@
+package jLogger
+
+import "sync/atomic"
+
+// FieldProvider 在写入时计算字段，返回的字段附加到每条记录上
+type FieldProvider func() []Field
+
+// AddFieldProvider 注册一个在写入时计算字段的函数，例如当前goroutine数、功能开关的快照、
+// 从环境变量读取的k8s pod名：
+//
+//    log.AddFieldProvider(func() []jLogger.Field {
+//        return []jLogger.Field{jLogger.Int("goroutines", runtime.NumGoroutine())}
+//    })
+//
+// 函数在编码记录时（处理协程或刷新时）对每条记录调用一次，不占用业务协程，必须足够快且并发安全。
+// 字段只出现在日志文件中，Sink和订阅收到的Record不包含。返回的remove用于注销
+func (l *Logger) AddFieldProvider(fn FieldProvider) (remove func()) {
+    l = l.pipeline()
+    p := &fn
+    l.providers_mu.Lock()
+    l.providers = append(l.providers, p)
+    atomic.StoreInt32(&l.providerCount, int32(len(l.providers)))
+    l.providers_mu.Unlock()
+    return func() {
+        l.providers_mu.Lock()
+        defer l.providers_mu.Unlock()
+        for i, q := range l.providers {
+            if q == p {
+                l.providers = append(l.providers[:i:i], l.providers[i+1:]...)
+                atomic.StoreInt32(&l.providerCount, int32(len(l.providers)))
+                return
+            }
+        }
+    }
+}
+
+func (l *Logger) providedFields() []Field {
+    l.providers_mu.RLock()
+    defer l.providers_mu.RUnlock()
+    var fields []Field
+    for _, p := range l.providers {
+        fields = append(fields, (*p)()...)
+    }
+    return fields
+}
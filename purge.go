@@ -0,0 +1,277 @@
+package jLogger
+
+import (
+    "bufio"
+    "bytes"
+    "compress/gzip"
+    "encoding/json"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "regexp"
+    "strconv"
+    "strings"
+)
+
+// 脱敏替换值
+const redacted = "[REDACTED]"
+
+// PurgeOptions 定向清除的条件：删除（或脱敏）所有 Field 字段值等于 Value 的日志行
+type PurgeOptions struct {
+    Field  string
+    Value  string
+    Redact bool // true时只把该字段的值替换为 [REDACTED]，保留这一行；默认整行删除
+}
+
+// PurgeResult 清除结果
+type PurgeResult struct {
+    Files   int // 扫描的文件数
+    Changed int // 被改写的文件数
+    Lines   int // 被删除或脱敏的行数
+}
+
+// 前缀之后的部分：_<level>.log 为当前文件，_<level>-<时间>.log[.gz] 为lumberjack轮转出的归档。
+// 精确匹配级别和时间格式，前缀为app时不会匹配到app_worker的文件
+var logFileSuffix = regexp.MustCompile(`^_(info|debug|error)(-\d{4}-\d{2}-\d{2}T\d{2}-\d{2}-\d{2}\.\d{3})?\.log(\.gz)?$`)
+
+// LogFiles 返回logDir下属于logPrefix的所有日志文件：当前文件和lumberjack轮转出的归档（含.gz），按文件名排序
+func LogFiles(logDir, logPrefix string) ([]string, error) {
+    entries, err := os.ReadDir(logDir)
+    if err != nil {
+        return nil, err
+    }
+    var files []string
+    for _, e := range entries {
+        if e.IsDir() {
+            continue
+        }
+        if level, _ := parseLogFileName(e.Name(), logPrefix); level != "" {
+            files = append(files, filepath.Join(logDir, e.Name()))
+        }
+    }
+    return files, nil
+}
+
+// 只返回归档，不含正在写入的当前文件
+func archiveFiles(logDir, logPrefix string) ([]string, error) {
+    files, err := LogFiles(logDir, logPrefix)
+    if err != nil {
+        return nil, err
+    }
+    archives := files[:0]
+    for _, file := range files {
+        if _, current := parseLogFileName(filepath.Base(file), logPrefix); !current {
+            archives = append(archives, file)
+        }
+    }
+    return archives, nil
+}
+
+// PurgeFiles 扫描logDir下logPrefix的当前文件和归档，改写掉所有匹配的行，用于满足GDPR等删除请求。
+// 文件先写入同目录的临时文件再替换，.gz归档保持压缩。不要对正在被其他进程写入的当前文件调用，
+// 运行中的Logger请使用 l.Purge，它会先轮转当前文件
+func PurgeFiles(logDir, logPrefix string, opts PurgeOptions) (PurgeResult, error) {
+    var res PurgeResult
+    if opts.Field == "" {
+        return res, fmt.Errorf("PurgeOptions.Field不能为空")
+    }
+    files, err := LogFiles(logDir, logPrefix)
+    if err != nil {
+        return res, err
+    }
+    return purgeFiles(files, opts)
+}
+
+func purgeFiles(files []string, opts PurgeOptions) (PurgeResult, error) {
+    var res PurgeResult
+    m := newPurgeMatcher(opts)
+    for _, file := range files {
+        res.Files++
+        n, err := purgeFile(file, m)
+        if err != nil {
+            return res, fmt.Errorf("%s: %v", file, err)
+        }
+        if n > 0 {
+            res.Changed++
+            res.Lines += n
+        }
+    }
+    return res, nil
+}
+
+// Purge 刷新缓冲区并轮转当前日志文件，然后对全部归档执行PurgeFiles。轮转后的当前文件仍由lumberjack打开写入，
+// 不做改写，运行中的Logger也不会和改写冲突；Purge开始之后新写入的记录不在处理范围内
+func (l *Logger) Purge(opts PurgeOptions) (PurgeResult, error) {
+    l = l.pipeline()
+    if opts.Field == "" {
+        return PurgeResult{}, fmt.Errorf("PurgeOptions.Field不能为空")
+    }
+    if err := l.rotateAll(); err != nil {
+        return PurgeResult{}, err
+    }
+    files, err := archiveFiles(l.logDir, l.logPrefix)
+    if err != nil {
+        return PurgeResult{}, err
+    }
+    return purgeFiles(files, opts)
+}
+
+type purgeMatcher struct {
+    opts PurgeOptions
+    text *regexp.Regexp // 文本格式中的 key=value 或 key="value"
+}
+
+func newPurgeMatcher(opts PurgeOptions) *purgeMatcher {
+    value := regexp.QuoteMeta(opts.Value)
+    quoted := regexp.QuoteMeta(strconv.Quote(opts.Value))
+    pattern := `(^|\s)` + regexp.QuoteMeta(opts.Field) + `=(` + quoted + `|` + value + `)(\s|$)`
+    return &purgeMatcher{opts: opts, text: regexp.MustCompile(pattern)}
+}
+
+// 处理一行：返回处理后的行、是否匹配、是否保留
+func (m *purgeMatcher) apply(line string) (string, bool, bool) {
+    if strings.HasPrefix(line, "{") {
+        if out, matched, ok := m.applyJSON(line); ok {
+            return out, matched, !matched || m.opts.Redact
+        }
+    }
+    if !m.text.MatchString(line) {
+        return line, false, true
+    }
+    if !m.opts.Redact {
+        return "", true, false
+    }
+    return m.text.ReplaceAllString(line, "${1}"+m.opts.Field+"="+redacted+"${3}"), true, true
+}
+
+// JSON行逐个key读取，值保留原始文本：数字不经过float64，大整数ID也能精确匹配；
+// 脱敏时按原来的key顺序重新拼出这一行，只替换匹配字段的值。不是合法的JSON对象时ok为false
+func (m *purgeMatcher) applyJSON(line string) (out string, matched, ok bool) {
+    dec := json.NewDecoder(strings.NewReader(line))
+    if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+        return "", false, false
+    }
+    type member struct {
+        key   string
+        value json.RawMessage
+    }
+    var members []member
+    for dec.More() {
+        t, err := dec.Token()
+        if err != nil {
+            return "", false, false
+        }
+        key, _ := t.(string)
+        var value json.RawMessage
+        if err := dec.Decode(&value); err != nil {
+            return "", false, false
+        }
+        members = append(members, member{key, value})
+        if key == m.opts.Field && rawText(value) == m.opts.Value {
+            matched = true
+        }
+    }
+    if !matched || !m.opts.Redact {
+        return line, matched, true
+    }
+
+    var b bytes.Buffer
+    b.WriteByte('{')
+    for i, mb := range members {
+        if i > 0 {
+            b.WriteByte(',')
+        }
+        writeJSONString(&b, mb.key)
+        b.WriteByte(':')
+        if mb.key == m.opts.Field {
+            writeJSONString(&b, redacted)
+        } else {
+            b.Write(mb.value)
+        }
+    }
+    b.WriteByte('}')
+    return b.String(), true, true
+}
+
+// JSON值的文本形式：字符串取内容，数字、布尔等取原始字面量
+func rawText(value json.RawMessage) string {
+    var s string
+    if json.Unmarshal(value, &s) == nil {
+        return s
+    }
+    return string(value)
+}
+
+func purgeFile(path string, m *purgeMatcher) (int, error) {
+    in, err := os.Open(path)
+    if err != nil {
+        return 0, err
+    }
+    defer in.Close()
+
+    gz := strings.HasSuffix(path, ".gz")
+    var r io.Reader = in
+    if gz {
+        zr, err := gzip.NewReader(in)
+        if err != nil {
+            return 0, err
+        }
+        defer zr.Close()
+        r = zr
+    }
+
+    tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".purge-*")
+    if err != nil {
+        return 0, err
+    }
+    defer os.Remove(tmp.Name())
+    var w io.Writer = tmp
+    var zw *gzip.Writer
+    if gz {
+        zw = gzip.NewWriter(tmp)
+        w = zw
+    }
+    bw := bufio.NewWriter(w)
+
+    n := 0
+    scanner := bufio.NewScanner(r)
+    scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+    for scanner.Scan() {
+        line, matched, keep := m.apply(scanner.Text())
+        if matched {
+            n++
+        }
+        if keep {
+            bw.WriteString(line)
+            bw.WriteByte('\n')
+        }
+    }
+    if err := scanner.Err(); err != nil {
+        tmp.Close()
+        return 0, err
+    }
+    if n == 0 {
+        tmp.Close()
+        return 0, nil
+    }
+
+    if err := bw.Flush(); err != nil {
+        tmp.Close()
+        return 0, err
+    }
+    if zw != nil {
+        if err := zw.Close(); err != nil {
+            tmp.Close()
+            return 0, err
+        }
+    }
+    if err := tmp.Close(); err != nil {
+        return 0, err
+    }
+    if info, err := os.Stat(path); err == nil {
+        os.Chmod(tmp.Name(), info.Mode())
+    }
+    in.Close()
+    return n, os.Rename(tmp.Name(), path)
+}
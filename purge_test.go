@@ -0,0 +1,127 @@
+package jLogger
+
+import (
+    "bufio"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+)
+
+// 读出目录下全部日志文件（当前文件和归档）的所有行
+func readAllLines(t *testing.T, dir, prefix string) []string {
+    t.Helper()
+    files, err := LogFiles(dir, prefix)
+    if err != nil {
+        t.Fatal(err)
+    }
+    var lines []string
+    for _, file := range files {
+        f, err := os.Open(file)
+        if err != nil {
+            t.Fatal(err)
+        }
+        sc := bufio.NewScanner(f)
+        for sc.Scan() {
+            lines = append(lines, sc.Text())
+        }
+        f.Close()
+    }
+    return lines
+}
+
+func TestLogFilesExactPrefix(t *testing.T) {
+    dir := t.TempDir()
+    names := []string{
+        "app_info.log",
+        "app_error-2024-01-02T03-04-05.000.log",
+        "app_debug-2024-01-02T03-04-05.000.log.gz",
+        "app_v2_info.log",
+        "app_info.log.bak",
+        "other_info.log",
+    }
+    for _, name := range names {
+        if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+            t.Fatal(err)
+        }
+    }
+    files, err := LogFiles(dir, "app")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(files) != 3 {
+        t.Fatalf("LogFiles(app) = %v, 期望只包含前3个文件", files)
+    }
+    archives, err := archiveFiles(dir, "app")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(archives) != 2 {
+        t.Fatalf("archiveFiles(app) = %v, 当前文件不应包含在内", archives)
+    }
+}
+
+func TestPurgeMatcherJSON(t *testing.T) {
+    m := newPurgeMatcher(PurgeOptions{Field: "user_id", Value: "12345678901234567890"})
+    line := `{"time":"t","level":"INFO","msg":"x","user_id":12345678901234567890}`
+    if _, matched, keep := m.apply(line); !matched || keep {
+        t.Fatalf("大整数ID应精确匹配并删除: matched=%v keep=%v", matched, keep)
+    }
+    if _, matched, _ := m.apply(`{"user_id":12345678901234567891}`); matched {
+        t.Fatal("相邻的大整数不应匹配")
+    }
+
+    m = newPurgeMatcher(PurgeOptions{Field: "email", Value: "<a&b>@x.com", Redact: true})
+    out, matched, keep := m.apply(`{"msg":"x","email":"<a&b>@x.com","n":1}`)
+    if !matched || !keep {
+        t.Fatalf("含HTML字符的值应匹配并保留: matched=%v keep=%v", matched, keep)
+    }
+    if want := `{"msg":"x","email":"` + redacted + `","n":1}`; out != want {
+        t.Fatalf("脱敏结果 = %s, 期望 %s", out, want)
+    }
+}
+
+func TestPurgeWhileLogging(t *testing.T) {
+    dir := t.TempDir()
+    l, err := NewLogger(dir, "app", 16, 10*time.Millisecond, "INFO", WithJSON())
+    if err != nil {
+        t.Fatal(err)
+    }
+    for i := 0; i < 50; i++ {
+        l.InfoFields("secret", String("user", "alice"))
+    }
+
+    const n = 2000
+    var wg sync.WaitGroup
+    wg.Add(1)
+    go func() {
+        defer wg.Done()
+        for i := 0; i < n; i++ {
+            l.InfoFields("keep", String("user", "bob"))
+        }
+    }()
+    res, err := l.Purge(PurgeOptions{Field: "user", Value: "alice"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    wg.Wait()
+    l.Close()
+
+    if res.Lines != 50 {
+        t.Fatalf("Purge删除了%d行, 期望50", res.Lines)
+    }
+    keep := 0
+    for _, line := range readAllLines(t, dir, "app") {
+        if strings.Contains(line, `"alice"`) {
+            t.Fatalf("被清除的记录仍然存在: %s", line)
+        }
+        if strings.Contains(line, `"keep"`) {
+            keep++
+        }
+    }
+    if keep != n {
+        t.Fatalf("Purge期间写入的记录有%d条, 期望%d条", keep, n)
+    }
+}
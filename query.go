@@ -0,0 +1,393 @@
+package jLogger
+
+import (
+    "bufio"
+    "bytes"
+    "encoding/json"
+    "io"
+    "net/http"
+    "path/filepath"
+    "regexp"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// 文本格式下通道已满时写入的标记，见writeFallback
+const fallbackMarker = "日志通道已满，进入主线程写入日志: "
+
+// QueryOptions 历史日志的查询条件，零值表示不限
+type QueryOptions struct {
+    From     time.Time
+    To       time.Time
+    Level    string            // 最低级别
+    Contains string            // 原始行中包含的子串，区分大小写；索引的布隆过滤器只用来跳过一定不包含该子串的文件
+    Fields   map[string]string // 字段值必须相等，module和tag也可以作为字段查询
+}
+
+// RecordIterator 查询结果的迭代器，用法同bufio.Scanner：
+//
+//    it := log.Query(jLogger.QueryOptions{Level: "ERROR", From: start})
+//    defer it.Close()
+//    for it.Next() {
+//        r := it.Record()
+//    }
+//    if err := it.Err(); err != nil { ... }
+type RecordIterator struct {
+    opts    QueryOptions
+    min     int
+    files   []queryFile
+    labels  map[string]string // 级别文字 -> 级别，见WithLevelLabels
+    scanner *bufio.Scanner
+    closer  io.Closer
+    rec     Record
+    err     error
+}
+
+type queryFile struct {
+    path   string
+    first  time.Time
+    offset int64
+}
+
+// Query 查询当前文件和轮转归档中的历史记录。先刷新缓冲区，刚写的日志也能查到。
+// 文件按时间先后依次读取，借助已有的归档索引（见WithIndex）跳过时间范围或关键字不相关的文件
+func (l *Logger) Query(opts QueryOptions) *RecordIterator {
+    l = l.pipeline()
+    l.Flush()
+    it := QueryFiles(l.logDir, l.logPrefix, opts)
+    it.labels = l.labelLevels()
+    return it
+}
+
+// QueryFiles 同Query，直接查询logDir下logPrefix的日志文件，不需要运行中的Logger
+func QueryFiles(logDir, logPrefix string, opts QueryOptions) *RecordIterator {
+    it := &RecordIterator{opts: opts, min: levelRank(strings.ToUpper(opts.Level))}
+    paths, err := LogFiles(logDir, logPrefix)
+    if err != nil {
+        it.err = err
+        return it
+    }
+    for _, path := range paths {
+        level, current := parseLogFileName(filepath.Base(path), logPrefix)
+        if rank := levelRank(level); it.min > 0 && rank >= 0 && rank < it.min {
+            continue
+        }
+        f := queryFile{path: path}
+        // 当前文件仍在写入，索引总是过期的，直接扫描
+        idx, ok := (*FileIndex)(nil), false
+        if !current {
+            idx, ok = cachedIndex(path)
+        }
+        if ok {
+            if !idx.Overlaps(opts.From, opts.To) || (opts.Contains != "" && !idx.Bloom.MayContain(opts.Contains)) {
+                continue
+            }
+            f.first = idx.First
+            f.offset = idx.Seek(opts.From)
+        } else {
+            f.first = firstRecordTime(path)
+        }
+        it.files = append(it.files, f)
+    }
+    sort.SliceStable(it.files, func(i, j int) bool { return it.files[i].first.Before(it.files[j].first) })
+    return it
+}
+
+// 没有可用的索引时，读取文件开头第一条带时间戳的记录的时间，用于按时间先后排列文件
+func firstRecordTime(path string) time.Time {
+    sc, closer, err := openLines(path, 0)
+    if err != nil {
+        return time.Time{}
+    }
+    defer closer.Close()
+    for i := 0; i < 16 && sc.Scan(); i++ {
+        if t, ok := parseLineTime(sc.Text()); ok {
+            return t
+        }
+    }
+    return time.Time{}
+}
+
+// Next 读取下一条匹配的记录，没有更多记录或出错时返回false
+func (it *RecordIterator) Next() bool {
+    for it.err == nil {
+        if it.scanner == nil {
+            if len(it.files) == 0 {
+                return false
+            }
+            f := it.files[0]
+            it.files = it.files[1:]
+            it.scanner, it.closer, it.err = openLines(f.path, f.offset)
+            continue
+        }
+        if !it.scanner.Scan() {
+            it.err = it.scanner.Err()
+            it.closer.Close()
+            it.scanner, it.closer = nil, nil
+            continue
+        }
+        line := it.scanner.Text()
+        if it.opts.Contains != "" && !strings.Contains(line, it.opts.Contains) {
+            continue
+        }
+        r, ok := ParseRecord(line)
+        if level, found := it.labels[r.Level]; found {
+            r.Level = level
+        }
+        if ok && it.match(r) {
+            it.rec = r
+            return true
+        }
+    }
+    return false
+}
+
+// Record 返回Next读到的记录
+func (it *RecordIterator) Record() Record {
+    return it.rec
+}
+
+// Err 返回迭代过程中遇到的第一个错误
+func (it *RecordIterator) Err() error {
+    return it.err
+}
+
+// Close 关闭正在读取的文件，提前结束迭代时必须调用
+func (it *RecordIterator) Close() error {
+    if it.closer != nil {
+        it.closer.Close()
+        it.scanner, it.closer = nil, nil
+    }
+    it.files = nil
+    return nil
+}
+
+func (it *RecordIterator) match(r Record) bool {
+    if it.min > 0 && levelRank(r.Level) < it.min {
+        return false
+    }
+    // 没有时间戳的记录无法判断，有时间条件时排除
+    if !it.opts.From.IsZero() && (r.Time.IsZero() || r.Time.Before(it.opts.From)) {
+        return false
+    }
+    if !it.opts.To.IsZero() && (r.Time.IsZero() || r.Time.After(it.opts.To)) {
+        return false
+    }
+    for key, want := range it.opts.Fields {
+        if got, ok := r.field(key); !ok || got != want {
+            return false
+        }
+    }
+    return true
+}
+
+// 按key取字段的文本值，module、tag、seq和log_id也可以按字段取
+func (r Record) field(key string) (string, bool) {
+    switch key {
+    case "module":
+        return r.Module, r.Module != ""
+    case "tag":
+        return r.Tag, r.Tag != ""
+    case "seq":
+        return strconv.FormatUint(r.Seq, 10), r.Seq != 0
+    case "log_id":
+        return r.LogID, r.LogID != ""
+    }
+    for _, f := range r.Fields {
+        if f.Key == key {
+            return f.text(), true
+        }
+    }
+    return "", false
+}
+
+// ParseRecord 把日志文件中的一行解析为Record，支持文本格式（"INFO: 时间 消息 key=value"）和各版本的JSON格式
+func ParseRecord(line string) (Record, bool) {
+    if strings.HasPrefix(line, "{") {
+        return parseJSONRecord(line)
+    }
+    return parseTextRecord(line)
+}
+
+func parseTextRecord(line string) (Record, bool) {
+    var r Record
+    i := strings.Index(line, ": ")
+    if i <= 0 || i >= 16 {
+        return r, false
+    }
+    r.Level, line = line[:i], line[i+2:]
+    if len(line) >= len(timeFormat) {
+        if t, err := time.ParseInLocation(timeFormat, line[:len(timeFormat)], time.Local); err == nil {
+            r.Time = t
+            line = strings.TrimPrefix(line[len(timeFormat):], " ")
+        }
+    }
+    if r.Time.IsZero() && strings.HasPrefix(line, fallbackMarker) {
+        line = line[len(fallbackMarker):]
+        r.Fields = append(r.Fields, Any("fallback", true))
+    }
+
+    // 从行尾向前取出 key=value 形式的字段，剩下的是消息
+    var fields []Field
+    for {
+        m := trailingField.FindStringSubmatchIndex(line)
+        if m == nil {
+            break
+        }
+        key, value := line[m[2]:m[3]], line[m[4]:m[5]]
+        if strings.HasPrefix(value, `"`) {
+            if v, err := strconv.Unquote(value); err == nil {
+                value = v
+            }
+        }
+        fields = append(fields, String(key, value))
+        line = line[:m[0]]
+    }
+    r.Message = line
+    for i := len(fields) - 1; i >= 0; i-- {
+        r.addField(fields[i])
+    }
+    return r, true
+}
+
+// 行尾的一个字段，值按quoteValue的规则：含空格、等号或引号时带引号
+var trailingField = regexp.MustCompile(`(?:^| )([A-Za-z_][A-Za-z0-9_.\-]*)=("(?:[^"\\]|\\.)*"|[^ ="]+)$`)
+
+func parseJSONRecord(line string) (Record, bool) {
+    var r Record
+    dec := json.NewDecoder(strings.NewReader(line))
+    dec.UseNumber()
+    if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+        return r, false
+    }
+    // 先按顺序读出所有key，读到版本号后再按该版本的格式解释，同时保留字段在行中的顺序
+    var fields []Field
+    for dec.More() {
+        t, err := dec.Token()
+        if err != nil {
+            return r, false
+        }
+        key, _ := t.(string)
+        var value interface{}
+        if err := dec.Decode(&value); err != nil {
+            return r, false
+        }
+        if n, ok := value.(json.Number); ok {
+            if i, err := n.Int64(); err == nil {
+                value = i
+            } else if f, err := n.Float64(); err == nil {
+                value = f
+            }
+        }
+        if key == schemaKey {
+            if v, ok := value.(int64); ok {
+                r.Version = int(v)
+                continue
+            }
+        }
+        fields = append(fields, Any(key, value))
+    }
+
+    schema := schemaFor(r.Version)
+    for _, f := range fields {
+        switch f.Key {
+        case schema.time:
+            s, _ := f.Value.(string)
+            r.Time, _ = time.ParseInLocation(schema.timeLayout, s, time.Local)
+        case schema.level:
+            r.Level, _ = f.Value.(string)
+        case schema.msg:
+            r.Message, _ = f.Value.(string)
+        default:
+            r.addField(f)
+        }
+    }
+    return r, r.Level != ""
+}
+
+// module、tag、seq和log_id放到Record对应的字段上，和Subscribe推送的记录保持一致
+func (r *Record) addField(f Field) {
+    switch f.Key {
+    case "module":
+        r.Module = f.text()
+        return
+    case "tag":
+        r.Tag = f.text()
+        return
+    case "log_id":
+        r.LogID = f.text()
+        return
+    case "seq":
+        if seq, err := strconv.ParseUint(f.text(), 10, 64); err == nil {
+            r.Seq = seq
+            return
+        }
+    }
+    r.Fields = append(r.Fields, f)
+}
+
+// QueryHandler 返回查询历史日志的http.Handler，结果为每行一个JSON记录。
+// 参数：from、to（"2006-01-02 15:04:05"或RFC3339）、level、contains、limit（默认1000），
+// 以及任意个 field=key:value。需要自行挂载在有访问控制的路由下
+func (l *Logger) QueryHandler() http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        q := r.URL.Query()
+        opts := QueryOptions{Level: q.Get("level"), Contains: q.Get("contains")}
+        var err error
+        if opts.From, err = ParseQueryTime(q.Get("from")); err != nil {
+            http.Error(w, "from: "+err.Error(), http.StatusBadRequest)
+            return
+        }
+        if opts.To, err = ParseQueryTime(q.Get("to")); err != nil {
+            http.Error(w, "to: "+err.Error(), http.StatusBadRequest)
+            return
+        }
+        for _, kv := range q["field"] {
+            key, value, ok := strings.Cut(kv, ":")
+            if !ok {
+                http.Error(w, "field必须是 key:value 的形式", http.StatusBadRequest)
+                return
+            }
+            if opts.Fields == nil {
+                opts.Fields = make(map[string]string)
+            }
+            opts.Fields[key] = value
+        }
+        limit := 1000
+        if s := q.Get("limit"); s != "" {
+            if limit, err = strconv.Atoi(s); err != nil || limit <= 0 {
+                http.Error(w, "limit必须是正整数", http.StatusBadRequest)
+                return
+            }
+        }
+
+        it := l.Query(opts)
+        defer it.Close()
+        w.Header().Set("Content-Type", "application/x-ndjson")
+        var b bytes.Buffer
+        enc := json.NewEncoder(&b)
+        for n := 0; n < limit && it.Next(); n++ {
+            b.Reset()
+            enc.Encode(it.Record())
+            w.Write(b.Bytes())
+        }
+        if err := it.Err(); err != nil {
+            l.internalError("查询历史日志失败:", err)
+        }
+    })
+}
+
+// ParseQueryTime 解析查询条件中的时间，支持日志的时间格式（可省略毫秒或时间部分，按本地时区）和RFC3339，空字符串表示不限
+func ParseQueryTime(s string) (time.Time, error) {
+    if s == "" {
+        return time.Time{}, nil
+    }
+    for _, layout := range []string{timeFormat, "2006-01-02 15:04:05", "2006-01-02"} {
+        if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+            return t, nil
+        }
+    }
+    return time.Parse(time.RFC3339Nano, s)
+}
@@ -0,0 +1,66 @@
+package jLogger
+
+import (
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+func TestQueryContainsPartialWord(t *testing.T) {
+    dir := t.TempDir()
+    path := filepath.Join(dir, "app_error-2024-01-02T03-04-05.000.log")
+    if err := os.WriteFile(path, []byte("ERROR: 2024/01/02 03:04:05 Connection timeout on db1\n"), 0644); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := BuildIndex(path, 0); err != nil {
+        t.Fatal(err)
+    }
+    for _, contains := range []string{"onnection time", "Connection", "timeout on db"} {
+        it := QueryFiles(dir, "app", QueryOptions{Contains: contains})
+        n := 0
+        for it.Next() {
+            n++
+        }
+        if err := it.Err(); err != nil {
+            t.Fatal(err)
+        }
+        it.Close()
+        if n != 1 {
+            t.Errorf("Contains=%q 查到%d条, 期望1条", contains, n)
+        }
+    }
+}
+
+func TestQueryDoesNotWriteIndex(t *testing.T) {
+    dir := t.TempDir()
+    archive := filepath.Join(dir, "app_info-2024-01-02T03-04-05.000.log")
+    current := filepath.Join(dir, "app_info.log")
+    if err := os.WriteFile(archive, []byte("INFO: 2024-01-02 03:04:05.000 old\n"), 0644); err != nil {
+        t.Fatal(err)
+    }
+    if err := os.WriteFile(current, []byte("INFO: 2024-01-03 03:04:05.000 new\n"), 0644); err != nil {
+        t.Fatal(err)
+    }
+
+    it := QueryFiles(dir, "app", QueryOptions{})
+    var msgs []string
+    for it.Next() {
+        msgs = append(msgs, it.Record().Message)
+    }
+    if err := it.Err(); err != nil {
+        t.Fatal(err)
+    }
+    it.Close()
+    if got := strings.Join(msgs, ","); got != "old,new" {
+        t.Fatalf("查询结果为%q, 期望按时间先后 \"old,new\"", got)
+    }
+    if err := Search(dir, "app", "new", func(file, line string) bool { return true }); err != nil {
+        t.Fatal(err)
+    }
+    for _, path := range []string{archive, current} {
+        if _, err := os.Stat(path + indexSuffix); !os.IsNotExist(err) {
+            t.Fatalf("查询为%s写入了索引文件", filepath.Base(path))
+        }
+    }
+}
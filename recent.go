@@ -0,0 +1,74 @@
+package jLogger
+
+import "sync"
+
+// 固定容量的环形缓冲区，写满后覆盖最旧的记录
+type ring struct {
+    mu   sync.Mutex
+    buf  []logMessage
+    next int
+    full bool
+}
+
+func newRing(size int) *ring {
+    return &ring{buf: make([]logMessage, size)}
+}
+
+func (r *ring) add(msg logMessage) {
+    r.mu.Lock()
+    r.buf[r.next] = msg
+    r.next++
+    if r.next == len(r.buf) {
+        r.next = 0
+        r.full = true
+    }
+    r.mu.Unlock()
+}
+
+// 最近的n条记录，按时间先后排列，n<=0时返回全部
+func (r *ring) last(n int) []logMessage {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    size := r.next
+    if r.full {
+        size = len(r.buf)
+    }
+    if n <= 0 || n > size {
+        n = size
+    }
+    out := make([]logMessage, 0, n)
+    for i := r.next - n; i < r.next; i++ {
+        out = append(out, r.buf[(i+len(r.buf))%len(r.buf)])
+    }
+    return out
+}
+
+// Recent 返回最近的n条记录（n<=0时返回全部），需要通过WithRecent开启。
+// 包括被级别和采样过滤掉、没有写入文件的记录（这些记录的Seq为0），用于在错误报告和崩溃处理中附带上下文
+func (l *Logger) Recent(n int) []Record {
+    l = l.pipeline()
+    if l.recent == nil {
+        return nil
+    }
+    msgs := l.recent.last(n)
+    records := make([]Record, len(msgs))
+    for i, msg := range msgs {
+        records[i] = l.toRecord(msg)
+    }
+    return records
+}
+
+// 被级别或采样过滤掉的记录只进入最近记录环，不分配序号，不影响序号的连续性
+func (l *Logger) remember(level string, v []interface{}, fields ...Field) {
+    r := l.pipeline().recent
+    if r == nil {
+        return
+    }
+    if len(l.fields) > 0 {
+        fields = append(append(make([]Field, 0, len(l.fields)+len(fields)), l.fields...), fields...)
+    }
+    if l.prefix != "" {
+        v = append([]interface{}{l.prefix}, v...)
+    }
+    r.add(logMessage{level: level, msg: v, timestamp: l.now(), module: l.module, tag: l.tag, fields: fields})
+}
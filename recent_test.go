@@ -0,0 +1,20 @@
+package jLogger
+
+import (
+    "testing"
+    "time"
+)
+
+func TestRecentFilteredUsesClock(t *testing.T) {
+    fixed := time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local)
+    l := newTestLogger(t, "INFO", WithRecent(4), WithClock(func() time.Time { return fixed }))
+    // Debug被级别过滤，只进入最近记录环
+    l.Debug("filtered")
+    recs := l.Recent(1)
+    if len(recs) != 1 || recs[0].Message != "filtered" {
+        t.Fatalf("Recent(1) = %+v", recs)
+    }
+    if !recs[0].Time.Equal(fixed) {
+        t.Fatalf("被过滤记录的时间为%v, 期望WithClock的%v", recs[0].Time, fixed)
+    }
+}
@@ -0,0 +1,97 @@
+package jLogger
+
+import (
+    "fmt"
+    "log"
+    "path/filepath"
+    "sync"
+
+    "github.com/natefinch/lumberjack"
+)
+
+// 进程内已打开的日志文件，key为日志目录的绝对路径加前缀。
+// 同一组文件被两个lumberjack.Logger各自写入和轮转时，会把对方刚轮转出的文件当成自己的继续写，互相破坏
+var (
+    openFiles    = make(map[string]*fileSet)
+    openFiles_mu sync.Mutex
+)
+
+type fileSet struct {
+    writers [3]*lumberjack.Logger // 下标同levelNames
+    access  *lumberjack.Logger    // 访问日志，第一次使用AccessLog时创建
+    refs    int
+}
+
+func filesKey(logDir, logPrefix string) string {
+    if abs, err := filepath.Abs(logDir); err == nil {
+        logDir = abs
+    }
+    return filepath.Join(logDir, logPrefix)
+}
+
+// 登记本Logger使用的文件。已被其他Logger使用时，开启了WithSharedFiles则改用对方的文件输出，
+// 由同一个lumberjack.Logger串行写入和轮转，否则返回错误
+func (l *Logger) claimFiles() error {
+    key := filesKey(l.logDir, l.logPrefix)
+    loggers := []*log.Logger{l.InfoLogger, l.DebugLogger, l.ErrorLogger}
+
+    openFiles_mu.Lock()
+    defer openFiles_mu.Unlock()
+    if set, ok := openFiles[key]; ok {
+        if !l.shareFiles {
+            return fmt.Errorf("日志文件 %s_*.log 已被同一进程中的另一个Logger使用，需要共享时使用WithSharedFiles", key)
+        }
+        if l.useSpool {
+            return fmt.Errorf("共享日志文件 %s_*.log 时不能开启WithSpool", key)
+        }
+        for i, logger := range loggers {
+            if set.writers[i] != nil {
+                logger.SetOutput(set.writers[i])
+            }
+        }
+        set.refs++
+        l.filesKey = key
+        return nil
+    }
+
+    set := &fileSet{refs: 1}
+    for i, logger := range loggers {
+        set.writers[i], _ = logger.Writer().(*lumberjack.Logger)
+    }
+    openFiles[key] = set
+    l.filesKey = key
+    return nil
+}
+
+// 返回登记的文件组中的访问日志文件，还没有时创建；本Logger没有登记文件（多进程模式或已经Close）时返回nil
+func (l *Logger) claimAccessFile() *lumberjack.Logger {
+    openFiles_mu.Lock()
+    defer openFiles_mu.Unlock()
+    set, ok := openFiles[l.filesKey]
+    if l.filesKey == "" || !ok {
+        return nil
+    }
+    if set.access == nil {
+        set.access = newAccessFile(l.logDir, l.logPrefix)
+    }
+    return set.access
+}
+
+// Close时调用，最后一个使用者关闭后同样的前缀可以重新创建
+func (l *Logger) releaseFiles() {
+    if l.filesKey == "" {
+        return
+    }
+    openFiles_mu.Lock()
+    defer openFiles_mu.Unlock()
+    if set, ok := openFiles[l.filesKey]; ok {
+        set.refs--
+        if set.refs <= 0 {
+            delete(openFiles, l.filesKey)
+            if set.access != nil {
+                set.access.Close()
+            }
+        }
+    }
+    l.filesKey = ""
+}
@@ -0,0 +1,136 @@
+package jLogger
+
+import (
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "sync"
+    "time"
+)
+
+// WatchRemoteConfig的默认拉取间隔
+const defaultRemoteInterval = 30 * time.Second
+
+// RemoteConfig 远程下发的日志配置，字段为空表示不修改
+type RemoteConfig struct {
+    Level   string            `json:"level"`   // 根Logger的级别
+    Modules map[string]string `json:"modules"` // 模块级别，整体替换上一次远程下发的模块级别
+    SampleRate *float64       `json:"sample_rate"` // Info/Debug的采样比例，见SetSampleRate
+    SinkFields map[string]FieldFilter `json:"sink_fields"` // 各Sink的字段过滤规则，整体替换，见SetSinkFields
+}
+
+// WatchRemoteConfig 每隔interval从url拉取一次JSON格式的RemoteConfig并实时生效，用于全局统一调整日志级别和采样。
+// 任何返回JSON的HTTP接口都可以，例如Consul KV（/v1/kv/<key>?raw）或etcd的HTTP网关；
+// 支持ETag，配置没有变化时服务端可返回304。拉取失败记录为内部错误，保持当前配置不变。
+// interval <= 0 时使用默认的30秒。返回的stop用于停止拉取，Logger关闭后也会自动停止
+func (l *Logger) WatchRemoteConfig(url string, interval time.Duration) (stop func()) {
+    l = l.pipeline()
+    if interval <= 0 {
+        interval = defaultRemoteInterval
+    }
+    client := &http.Client{Timeout: 10 * time.Second}
+    done := make(chan struct{})
+
+    go func() {
+        var etag string
+        ticker := time.NewTicker(interval)
+        defer ticker.Stop()
+        for {
+            newTag, err := l.pollRemoteConfig(client, url, etag)
+            if err != nil {
+                l.internalError("拉取远程日志配置失败:", url, err)
+            } else {
+                etag = newTag
+            }
+            select {
+            case <-done:
+                return
+            case <-l.done:
+                return
+            case <-ticker.C:
+            }
+        }
+    }()
+
+    var once sync.Once
+    return func() {
+        once.Do(func() { close(done) })
+    }
+}
+
+func (l *Logger) pollRemoteConfig(client *http.Client, url, etag string) (string, error) {
+    req, err := http.NewRequest("GET", url, nil)
+    if err != nil {
+        return etag, err
+    }
+    if etag != "" {
+        req.Header.Set("If-None-Match", etag)
+    }
+    resp, err := client.Do(req)
+    if err != nil {
+        return etag, err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode == http.StatusNotModified {
+        return etag, nil
+    }
+    if resp.StatusCode != http.StatusOK {
+        return etag, fmt.Errorf("unexpected status %s", resp.Status)
+    }
+    body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+    if err != nil {
+        return etag, err
+    }
+    var cfg RemoteConfig
+    if err := json.Unmarshal(body, &cfg); err != nil {
+        return etag, err
+    }
+    if err := l.ApplyRemoteConfig(cfg); err != nil {
+        return etag, err
+    }
+    return resp.Header.Get("ETag"), nil
+}
+
+// ApplyRemoteConfig 应用一份远程配置，级别无效时整份配置都不生效
+func (l *Logger) ApplyRemoteConfig(cfg RemoteConfig) error {
+    l = l.pipeline()
+    var level string
+    if cfg.Level != "" {
+        var ok bool
+        if level, ok = normalizeLevel(cfg.Level); !ok {
+            return fmt.Errorf("无效的日志级别: %s", cfg.Level)
+        }
+    }
+    modules := make(map[string]string, len(cfg.Modules))
+    for module, lv := range cfg.Modules {
+        normalized, ok := normalizeLevel(lv)
+        if !ok {
+            return fmt.Errorf("无效的日志级别: %s=%s", module, lv)
+        }
+        modules[module] = normalized
+    }
+    var sinkFields map[string]*fieldFilter
+    if cfg.SinkFields != nil {
+        var err error
+        if sinkFields, err = compileSinkFields(cfg.SinkFields); err != nil {
+            return err
+        }
+    }
+
+    if level != "" {
+        l.SetLevel(level)
+    }
+    if cfg.Modules != nil {
+        l.replaceModuleLevels(&l.remoteModules, modules)
+    }
+    if cfg.SampleRate != nil {
+        l.SetSampleRate(*cfg.SampleRate)
+    }
+    if cfg.SinkFields != nil {
+        l.replaceSinkFields(cfg.SinkFields, sinkFields)
+    }
+    l.logConfig("remote")
+    return nil
+}
@@ -0,0 +1,39 @@
+package jLogger
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "sync/atomic"
+    "testing"
+    "time"
+)
+
+func TestWatchRemoteConfigStopsOnClose(t *testing.T) {
+    var polls int32
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        atomic.AddInt32(&polls, 1)
+        w.Write([]byte(`{"level":"debug"}`))
+    }))
+    defer srv.Close()
+
+    l, err := NewLogger(t.TempDir(), "app", 16, 10*time.Millisecond, "INFO")
+    if err != nil {
+        t.Fatal(err)
+    }
+    // interval为0时使用默认间隔，不会panic
+    stop := l.WatchRemoteConfig(srv.URL, 0)
+    stop()
+
+    l.WatchRemoteConfig(srv.URL, 5*time.Millisecond)
+    time.Sleep(50 * time.Millisecond)
+    if got := l.effectiveLevel(); got != "DEBUG" {
+        t.Fatalf("远程配置没有生效, 级别为%s", got)
+    }
+    l.Close()
+    time.Sleep(20 * time.Millisecond)
+    n := atomic.LoadInt32(&polls)
+    time.Sleep(50 * time.Millisecond)
+    if got := atomic.LoadInt32(&polls); got != n {
+        t.Fatalf("Close之后仍在拉取: %d -> %d", n, got)
+    }
+}
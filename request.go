@@ -0,0 +1,69 @@
+package jLogger
+
+import (
+    "net"
+    "net/http"
+    "net/netip"
+    "strings"
+)
+
+// ClientIP 取请求的客户端IP。直连地址是内网或回环地址（即请求经过反向代理）时，
+// 从右向左查找X-Forwarded-For中第一个非内网地址，其次是X-Real-IP；直连地址是公网地址时不信任这些头，防止伪造。
+// IPv4映射的IPv6地址还原为IPv4，结果为规范格式，无法解析时返回原始值
+func ClientIP(r *http.Request) string {
+    remote := r.RemoteAddr
+    if host, _, err := net.SplitHostPort(remote); err == nil {
+        remote = host
+    }
+    addr, err := netip.ParseAddr(remote)
+    if err != nil {
+        return remote
+    }
+    addr = addr.Unmap().WithZone("")
+    if !internalAddr(addr) {
+        return addr.String()
+    }
+
+    if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+        hops := strings.Split(strings.Join(xff, ","), ",")
+        var first netip.Addr
+        for i := len(hops) - 1; i >= 0; i-- {
+            hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+            if err != nil {
+                continue
+            }
+            hop = hop.Unmap().WithZone("")
+            if !internalAddr(hop) {
+                return hop.String()
+            }
+            first = hop
+        }
+        // 整条链路都在内网中，最左边的是最初的客户端
+        if first.IsValid() {
+            return first.String()
+        }
+    }
+    if real, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+        return real.Unmap().WithZone("").String()
+    }
+    return addr.String()
+}
+
+func internalAddr(addr netip.Addr) bool {
+    return addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsUnspecified()
+}
+
+// RequestFields 返回请求的client_ip、user_agent、referer字段，值为空的字段不返回。
+// 由Middleware（MiddlewareOptions.ClientInfo）使用，也可以在手动记录时附加：
+//
+//    log.InfoFields("login", append(jLogger.RequestFields(r), jLogger.String("user", name))...)
+func RequestFields(r *http.Request) []Field {
+    fields := []Field{String("client_ip", ClientIP(r))}
+    if ua := r.UserAgent(); ua != "" {
+        fields = append(fields, String("user_agent", ua))
+    }
+    if ref := r.Referer(); ref != "" {
+        fields = append(fields, String("referer", ref))
+    }
+    return fields
+}
@@ -0,0 +1,126 @@
+package jLogger
+
+import (
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "time"
+)
+
+// RetentionPolicy lumberjack只能按单个文件设置MaxAge/MaxBackups，这里补充跨文件的保留策略
+type RetentionPolicy struct {
+    MaxTotalBytes int64                    // logDir中属于该前缀的所有文件（含当前文件）的总大小上限，超出时从最旧的归档开始删除，0表示不限制
+    MaxAge        map[string]time.Duration // 按级别（INFO、DEBUG、ERROR）设置归档的最长保留时间
+    Interval      time.Duration            // 检查间隔，默认10分钟
+}
+
+type logFile struct {
+    path    string
+    level   string
+    size    int64
+    modTime time.Time
+    current bool // 正在写入的当前文件，永远不删除
+}
+
+// 周期性执行保留策略，Close后退出
+func (l *Logger) runRetention() {
+    interval := l.retention.Interval
+    if interval <= 0 {
+        interval = 10 * time.Minute
+    }
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    for {
+        if err := l.applyRetention(); err != nil {
+            l.internalError("执行日志保留策略失败:", err)
+        }
+        select {
+        case <-l.done:
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+// 先按级别删除过期的归档，再在总大小超限时从最旧的归档开始删除
+func (l *Logger) applyRetention() error {
+    files, err := l.listLogFiles()
+    if err != nil {
+        return err
+    }
+
+    now := time.Now()
+    kept := files[:0]
+    for _, f := range files {
+        maxAge := l.retention.MaxAge[f.level]
+        if !f.current && maxAge > 0 && now.Sub(f.modTime) > maxAge {
+            if err := removeLogFile(f.path); err != nil {
+                return err
+            }
+            continue
+        }
+        kept = append(kept, f)
+    }
+
+    if l.retention.MaxTotalBytes <= 0 {
+        return nil
+    }
+    var total int64
+    for _, f := range kept {
+        total += f.size
+    }
+    sort.Slice(kept, func(i, j int) bool { return kept[i].modTime.Before(kept[j].modTime) })
+    for _, f := range kept {
+        if total <= l.retention.MaxTotalBytes {
+            break
+        }
+        if f.current {
+            continue
+        }
+        if err := removeLogFile(f.path); err != nil {
+            return err
+        }
+        total -= f.size
+    }
+    return nil
+}
+
+// 删除日志文件及其索引
+func removeLogFile(path string) error {
+    if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+        return err
+    }
+    os.Remove(path + indexSuffix)
+    return nil
+}
+
+func (l *Logger) listLogFiles() ([]logFile, error) {
+    paths, err := LogFiles(l.logDir, l.logPrefix)
+    if err != nil {
+        return nil, err
+    }
+    files := make([]logFile, 0, len(paths))
+    for _, path := range paths {
+        info, err := os.Stat(path)
+        if err != nil {
+            continue
+        }
+        level, current := parseLogFileName(filepath.Base(path), l.logPrefix)
+        files = append(files, logFile{path: path, level: level, size: info.Size(), modTime: info.ModTime(), current: current})
+    }
+    return files, nil
+}
+
+// 从文件名解析级别：<prefix>_info.log 为当前文件，<prefix>_info-<时间>.log[.gz] 为轮转出的归档，
+// 不属于该前缀的文件返回空级别
+func parseLogFileName(name, prefix string) (level string, current bool) {
+    if !strings.HasPrefix(name, prefix) {
+        return "", false
+    }
+    m := logFileSuffix.FindStringSubmatch(name[len(prefix):])
+    if m == nil {
+        return "", false
+    }
+    return strings.ToUpper(m[1]), m[2] == "" && m[3] == ""
+}
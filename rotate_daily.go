@@ -0,0 +1,90 @@
+package jLogger
+
+import (
+    "fmt"
+    "log"
+    "os"
+    "time"
+)
+
+// 等待下一次按时间轮转时，最长隔这么久重新检查一次墙上时间，主机休眠或校时之后不会错过轮转点
+const dailyRotationCheck = time.Minute
+
+// 按时间轮转的配置，见WithDailyRotation
+type dailyRotation struct {
+    loc  *time.Location
+    hour int
+}
+
+// 下一次轮转的时间：loc时区中下一个hour点整。每次按日历计算而不是加24小时，
+// 夏令时切换的日子同样落在当地的整点
+func (r dailyRotation) next(now time.Time) time.Time {
+    t := now.In(r.loc)
+    next := r.at(t.Year(), t.Month(), t.Day())
+    if !next.After(t) {
+        next = r.at(t.Year(), t.Month(), t.Day()+1)
+    }
+    return next
+}
+
+// 某一天的轮转时刻。该整点因夏令时跳过而不存在时，取跳变之后的第一个整点
+func (r dailyRotation) at(year int, month time.Month, day int) time.Time {
+    t := time.Date(year, month, day, r.hour, 0, 0, 0, r.loc)
+    if t.Hour() != r.hour {
+        t = time.Date(year, month, day, r.hour+1, 0, 0, 0, r.loc)
+    }
+    return t
+}
+
+// 用于Stats，未配置时为空
+func (r *dailyRotation) String() string {
+    if r == nil {
+        return ""
+    }
+    return fmt.Sprintf("%s %02d:00", r.loc, r.hour)
+}
+
+func (l *Logger) runDailyRotation() {
+    defer l.emitters.Done()
+    next := l.daily.next(time.Now())
+    for {
+        wait := time.Until(next)
+        if wait > dailyRotationCheck {
+            wait = dailyRotationCheck
+        }
+        timer := time.NewTimer(wait)
+        select {
+        case <-l.done:
+            timer.Stop()
+            return
+        case <-timer.C:
+        }
+        if time.Now().Before(next) {
+            continue
+        }
+        if err := l.rotateNonEmpty(); err != nil {
+            l.internalError("按时间轮转日志文件失败:", err)
+        }
+        next = l.daily.next(time.Now())
+    }
+}
+
+// 刷新后轮转有内容的当前文件，空文件不产生空的归档
+func (l *Logger) rotateNonEmpty() error {
+    l.Flush()
+    for _, logger := range []*log.Logger{l.InfoLogger, l.DebugLogger, l.ErrorLogger} {
+        lj := rotatingFile(logger.Writer())
+        if lj == nil {
+            continue
+        }
+        if info, err := os.Stat(lj.Filename); err != nil || info.Size() == 0 {
+            continue
+        }
+        if r, ok := logger.Writer().(interface{ Rotate() error }); ok {
+            if err := r.Rotate(); err != nil {
+                return err
+            }
+        }
+    }
+    return nil
+}
@@ -0,0 +1,84 @@
+package jLogger
+
+import (
+    "testing"
+    "time"
+)
+
+func TestDailyRotationDST(t *testing.T) {
+    ny, err := time.LoadLocation("America/New_York")
+    if err != nil {
+        t.Skip("没有时区数据:", err)
+    }
+    utc := func(s string) time.Time {
+        t.Helper()
+        v, err := time.Parse(time.RFC3339, s)
+        if err != nil {
+            t.Fatal(err)
+        }
+        return v
+    }
+    // 2024-03-10 02:00 EST跳到03:00 EDT；2024-11-03 02:00 EDT回到01:00 EST，01:00-02:00出现两次
+    tests := []struct {
+        name string
+        hour int
+        now  string
+        want string
+    }{
+        {"普通的一天", 2, "2024-03-05T05:00:00Z", "2024-03-05T07:00:00Z"},
+        {"轮转点已过取第二天", 2, "2024-03-05T08:00:00Z", "2024-03-06T07:00:00Z"},
+        {"跳过的整点取03:00 EDT", 2, "2024-03-10T05:30:00Z", "2024-03-10T07:00:00Z"},
+        {"跳变之后取第二天的02:00 EDT", 2, "2024-03-10T07:30:00Z", "2024-03-11T06:00:00Z"},
+        {"重复的整点取第一次01:00 EDT", 1, "2024-11-03T04:30:00Z", "2024-11-03T05:00:00Z"},
+        {"第一次01:00之后取第二天", 1, "2024-11-03T05:00:00Z", "2024-11-04T06:00:00Z"},
+        {"重复的一小时内不再轮转", 1, "2024-11-03T06:30:00Z", "2024-11-04T06:00:00Z"},
+        {"23点在只有23小时的一天", 23, "2024-03-10T04:30:00Z", "2024-03-11T03:00:00Z"},
+        {"23点在有25小时的一天", 23, "2024-11-03T03:30:00Z", "2024-11-04T04:00:00Z"},
+        {"23点跨到下一天", 23, "2024-11-04T04:00:00Z", "2024-11-05T04:00:00Z"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            r := dailyRotation{loc: ny, hour: tt.hour}
+            got := r.next(utc(tt.now))
+            if want := utc(tt.want); !got.Equal(want) {
+                t.Fatalf("next(%s) = %s, 期望 %s", utc(tt.now).In(ny), got.In(ny), want.In(ny))
+            }
+            if got.Location() != ny {
+                t.Fatalf("返回的时间不在配置的时区: %s", got.Location())
+            }
+        })
+    }
+}
+
+func TestDailyRotationAt(t *testing.T) {
+    ny, err := time.LoadLocation("America/New_York")
+    if err != nil {
+        t.Skip("没有时区数据:", err)
+    }
+    tests := []struct {
+        name  string
+        hour  int
+        month time.Month
+        day   int
+        want  string
+    }{
+        {"跳过的02:00", 2, time.March, 10, "2024-03-10T03:00:00-04:00"},
+        {"跳变之前的01:00", 1, time.March, 10, "2024-03-10T01:00:00-05:00"},
+        {"跳变之后的03:00", 3, time.March, 10, "2024-03-10T03:00:00-04:00"},
+        {"重复的01:00", 1, time.November, 3, "2024-11-03T01:00:00-04:00"},
+        {"回拨之后的02:00", 2, time.November, 3, "2024-11-03T02:00:00-05:00"},
+        {"23:00", 23, time.November, 3, "2024-11-03T23:00:00-05:00"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got := dailyRotation{loc: ny, hour: tt.hour}.at(2024, tt.month, tt.day)
+            want, err := time.Parse(time.RFC3339, tt.want)
+            if err != nil {
+                t.Fatal(err)
+            }
+            if !got.Equal(want) {
+                t.Fatalf("at(2024-%02d-%02d %02d:00) = %s, 期望 %s", tt.month, tt.day, tt.hour, got, want.In(ny))
+            }
+        })
+    }
+}
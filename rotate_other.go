@@ -0,0 +1,10 @@
+//go:build !windows
+
+package jLogger
+
+import "io"
+
+// 其他系统上重命名打开中的文件不会失败，不需要包装，见rotate_windows.go
+func wrapRotation(w io.Writer) io.Writer {
+    return w
+}
@@ -0,0 +1,50 @@
+//go:build windows
+
+package jLogger
+
+import (
+    "io"
+    "time"
+)
+
+// 轮转失败时的重试次数和间隔
+const (
+    rotateRetries    = 3
+    rotateRetryDelay = 100 * time.Millisecond
+)
+
+// Windows上被其他进程（日志采集、杀毒软件、编辑器）打开的文件不能重命名，lumberjack轮转失败时
+// 这一次写入会返回错误并丢失。这里稍等后重试，通常对方很快会释放文件
+type retryWriter struct {
+    w io.Writer
+}
+
+func wrapRotation(w io.Writer) io.Writer {
+    return &retryWriter{w: w}
+}
+
+func (r *retryWriter) Write(p []byte) (int, error) {
+    n, err := r.w.Write(p)
+    for i := 1; err != nil && n == 0 && i <= rotateRetries; i++ {
+        time.Sleep(time.Duration(i) * rotateRetryDelay)
+        n, err = r.w.Write(p)
+    }
+    return n, err
+}
+
+func (r *retryWriter) Rotate() error {
+    rot, ok := r.w.(interface{ Rotate() error })
+    if !ok {
+        return nil
+    }
+    err := rot.Rotate()
+    for i := 1; err != nil && i <= rotateRetries; i++ {
+        time.Sleep(time.Duration(i) * rotateRetryDelay)
+        err = rot.Rotate()
+    }
+    return err
+}
+
+func (r *retryWriter) Unwrap() io.Writer {
+    return r.w
+}
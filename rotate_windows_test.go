@@ -0,0 +1,78 @@
+//go:build windows
+
+package jLogger
+
+import (
+    "errors"
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+
+    "github.com/natefinch/lumberjack"
+)
+
+// 前failures次写入失败的Writer，模拟文件被其他进程占用时轮转失败
+type flakyWriter struct {
+    failures int
+    writes   int
+}
+
+func (f *flakyWriter) Write(p []byte) (int, error) {
+    f.writes++
+    if f.writes <= f.failures {
+        return 0, errors.New("the process cannot access the file because it is being used by another process")
+    }
+    return len(p), nil
+}
+
+func TestRetryWriterWrite(t *testing.T) {
+    f := &flakyWriter{failures: 2}
+    w := wrapRotation(f)
+    if n, err := w.Write([]byte("x\n")); err != nil || n != 2 {
+        t.Fatalf("重试后Write = %d, %v", n, err)
+    }
+    if f.writes != 3 {
+        t.Fatalf("写入了%d次, 期望3次", f.writes)
+    }
+
+    f = &flakyWriter{failures: rotateRetries + 1}
+    if _, err := wrapRotation(f).Write([]byte("x\n")); err == nil {
+        t.Fatal("超过重试次数后应返回错误")
+    }
+}
+
+func TestRetryWriterRotateLockedFile(t *testing.T) {
+    dir := t.TempDir()
+    path := filepath.Join(dir, "app_info.log")
+    lj := &lumberjack.Logger{Filename: path}
+    w := wrapRotation(lj)
+    if _, err := w.Write([]byte("before\n")); err != nil {
+        t.Fatal(err)
+    }
+
+    // 其他进程打开文件时（不带FILE_SHARE_DELETE）文件不能重命名，稍后释放
+    locked, err := os.Open(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    go func() {
+        time.Sleep(rotateRetryDelay + rotateRetryDelay/2)
+        locked.Close()
+    }()
+
+    if err := w.(*retryWriter).Rotate(); err != nil {
+        t.Fatalf("文件释放后轮转仍然失败: %v", err)
+    }
+    if _, err := w.Write([]byte("after\n")); err != nil {
+        t.Fatal(err)
+    }
+    lj.Close()
+    files, err := LogFiles(dir, "app")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(files) != 2 {
+        t.Fatalf("轮转后的文件为 %v, 期望当前文件和一个归档", files)
+    }
+}
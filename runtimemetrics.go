@@ -0,0 +1,74 @@
+package jLogger
+
+import (
+    "os"
+    "runtime"
+    "time"
+)
+
+// 默认的运行时指标输出间隔
+const defaultRuntimeMetricsInterval = time.Minute
+
+// 定期写一条runtime记录：goroutine数、堆内存、距上次输出以来的GC次数和停顿、打开的文件描述符数
+func (l *Logger) runRuntimeMetrics() {
+    defer l.emitters.Done()
+    ticker := time.NewTicker(l.runtimeInterval)
+    defer ticker.Stop()
+    var lastGC uint32
+    for {
+        select {
+        case <-l.done:
+            return
+        case <-ticker.C:
+        }
+        var ms runtime.MemStats
+        runtime.ReadMemStats(&ms)
+        fields := []Field{
+            Int("goroutines", runtime.NumGoroutine()),
+            Int64("heap_alloc", int64(ms.HeapAlloc)),
+            Int64("heap_inuse", int64(ms.HeapInuse)),
+            Int64("heap_sys", int64(ms.HeapSys)),
+        }
+
+        // PauseNs是最近256次GC的环形缓冲区
+        n := ms.NumGC - lastGC
+        if n > uint32(len(ms.PauseNs)) {
+            n = uint32(len(ms.PauseNs))
+        }
+        var maxPause, totalPause uint64
+        for i := uint32(0); i < n; i++ {
+            pause := ms.PauseNs[(ms.NumGC-i+255)%256]
+            totalPause += pause
+            if pause > maxPause {
+                maxPause = pause
+            }
+        }
+        lastGC = ms.NumGC
+        fields = append(fields,
+            Int64("gc_count", int64(n)),
+            Duration("gc_pause_max", time.Duration(maxPause)),
+            Duration("gc_pause_total", time.Duration(totalPause)),
+        )
+        if fds, ok := openFDs(); ok {
+            fields = append(fields, Int("open_fds", fds))
+        }
+
+        switch l.runtimeLevel {
+        case "DEBUG":
+            l.DebugFields("runtime", fields...)
+        case "ERROR":
+            l.ErrorFields("runtime", fields...)
+        default:
+            l.InfoFields("runtime", fields...)
+        }
+    }
+}
+
+// 当前进程打开的文件描述符数，只在有/proc的系统上可用
+func openFDs() (int, bool) {
+    entries, err := os.ReadDir("/proc/self/fd")
+    if err != nil {
+        return 0, false
+    }
+    return len(entries), true
+}
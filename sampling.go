@@ -0,0 +1,50 @@
+package jLogger
+
+import (
+    "hash/fnv"
+    "sync/atomic"
+)
+
+// 采样比例的精度
+const sampleScale = 10000
+
+// SetSampleRate 设置Info/Debug的采样比例（0~1），1表示全部保留。Error从不采样。
+// 采样按 (模块, 消息模板) 的哈希决定，同一条日志语句在所有实例上要么都保留、要么都丢弃，采样后的数据在集群范围内可以互相比较
+func (l *Logger) SetSampleRate(rate float64) {
+    atomic.StoreUint64(&l.pipeline().sampleThreshold, sampleThreshold(rate))
+}
+
+func sampleThreshold(rate float64) uint64 {
+    if rate < 0 {
+        rate = 0
+    }
+    if rate > 1 {
+        rate = 1
+    }
+    return uint64(rate * sampleScale)
+}
+
+// 判断一条Info/Debug记录是否被采样保留。消息模板取第一个参数（字符串时），即日志语句中固定的那部分文本
+func (l *Logger) sampled(level string, v []interface{}) bool {
+    root := l.pipeline()
+    if root.degrade != nil && root.degraded(level, v) {
+        return false
+    }
+    threshold := atomic.LoadUint64(&root.sampleThreshold)
+    if threshold >= sampleScale || level == "ERROR" {
+        return true
+    }
+    var template string
+    if len(v) > 0 {
+        template, _ = v[0].(string)
+    }
+    h := fnv.New64a()
+    h.Write([]byte(l.module))
+    h.Write([]byte{0})
+    h.Write([]byte(template))
+    if h.Sum64()%sampleScale < threshold {
+        return true
+    }
+    atomic.AddUint64(&root.sampledOut, 1)
+    return false
+}
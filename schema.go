@@ -0,0 +1,30 @@
+package jLogger
+
+// SchemaVersion JSON记录的格式版本，写在每条记录的 "v" 字段中。
+// 修改固定key或字段含义时递增，并在jsonSchemas中登记新版本，读取端按记录自带的版本解析，旧文件仍然可读
+const SchemaVersion = 1
+
+// 版本字段的key，任何版本都不能改
+const schemaKey = "v"
+
+// 某个版本的JSON记录中固定字段的key和时间格式
+type jsonSchema struct {
+    time       string
+    level      string
+    msg        string
+    timeLayout string
+}
+
+// 各版本的格式，0表示加入版本字段之前写入的记录
+var jsonSchemas = map[int]jsonSchema{
+    0: {time: "time", level: "level", msg: "msg", timeLayout: timeFormat},
+    1: {time: "time", level: "level", msg: "msg", timeLayout: timeFormat},
+}
+
+// 返回版本对应的格式，比当前程序更新的版本按当前版本尽量解析
+func schemaFor(version int) jsonSchema {
+    if s, ok := jsonSchemas[version]; ok {
+        return s
+    }
+    return jsonSchemas[SchemaVersion]
+}
@@ -0,0 +1,104 @@
+package jLogger
+
+import (
+    "bufio"
+    "compress/gzip"
+    "io"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+)
+
+// Search 在logDir下logPrefix的所有日志文件（含归档）中查找包含term的行，fn返回false时停止。
+// 借助已有归档索引（见WithIndex）中的布隆过滤器跳过一定不包含该词的文件，排查问题时不必逐个解压扫描全部归档
+func Search(logDir, logPrefix, term string, fn func(file, line string) bool) error {
+    files, err := LogFiles(logDir, logPrefix)
+    if err != nil {
+        return err
+    }
+    sort.Strings(files)
+    for _, file := range files {
+        // 当前文件仍在写入，不使用索引
+        if _, current := parseLogFileName(filepath.Base(file), logPrefix); !current {
+            if idx, ok := cachedIndex(file); ok && !idx.Bloom.MayContain(term) {
+                continue
+            }
+        }
+        more, err := scanFile(file, 0, func(line string) bool {
+            if strings.Contains(line, term) {
+                return fn(file, line)
+            }
+            return true
+        })
+        if err != nil {
+            return err
+        }
+        if !more {
+            return nil
+        }
+    }
+    return nil
+}
+
+// 从offset开始逐行读取文件（.gz自动解压，偏移为解压后的偏移），fn返回false时停止，返回值表示是否读完
+func scanFile(path string, offset int64, fn func(line string) bool) (bool, error) {
+    scanner, closer, err := openLines(path, offset)
+    if err != nil {
+        return false, err
+    }
+    defer closer.Close()
+    for scanner.Scan() {
+        if !fn(scanner.Text()) {
+            return false, nil
+        }
+    }
+    return true, scanner.Err()
+}
+
+// 打开日志文件并定位到offset，返回逐行读取的Scanner，用完后关闭closer
+func openLines(path string, offset int64) (*bufio.Scanner, io.Closer, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, nil, err
+    }
+    closer := &fileCloser{f: f}
+
+    var r io.Reader = f
+    if strings.HasSuffix(path, ".gz") {
+        zr, err := gzip.NewReader(f)
+        if err != nil {
+            f.Close()
+            return nil, nil, err
+        }
+        closer.zr = zr
+        r = zr
+        if offset > 0 {
+            if _, err := io.CopyN(io.Discard, zr, offset); err != nil {
+                closer.Close()
+                return nil, nil, err
+            }
+        }
+    } else if offset > 0 {
+        if _, err := f.Seek(offset, io.SeekStart); err != nil {
+            f.Close()
+            return nil, nil, err
+        }
+    }
+
+    scanner := bufio.NewScanner(r)
+    scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+    return scanner, closer, nil
+}
+
+type fileCloser struct {
+    f  *os.File
+    zr *gzip.Reader
+}
+
+func (c *fileCloser) Close() error {
+    if c.zr != nil {
+        c.zr.Close()
+    }
+    return c.f.Close()
+}
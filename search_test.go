@@ -0,0 +1,48 @@
+package jLogger
+
+import (
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+func TestBloomMayContainSubstring(t *testing.T) {
+    b := newBloom(16)
+    for _, token := range tokenize("INFO: 2024/01/02 Connection timeout on 数据库连接 host=db1") {
+        b.add(token)
+    }
+    for _, term := range []string{"Connection", "onnect", "Connection time", "tion timeout on", "据库", "host=db", "out on 数据"} {
+        if !b.MayContain(term) {
+            t.Errorf("MayContain(%q) = false, 行中包含该子串", term)
+        }
+    }
+    for _, term := range []string{"x refused y", "超时"} {
+        if b.MayContain(term) {
+            t.Errorf("MayContain(%q) = true, 期望跳过", term)
+        }
+    }
+}
+
+func TestSearchPartialWord(t *testing.T) {
+    dir := t.TempDir()
+    path := filepath.Join(dir, "app_info-2024-01-02T03-04-05.000.log")
+    if err := os.WriteFile(path, []byte("INFO: 2024/01/02 03:04:05 Connection timeout on db1\n"), 0644); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := BuildIndex(path, 0); err != nil {
+        t.Fatal(err)
+    }
+    for _, term := range []string{"onnection time", "Connection", "db"} {
+        found := 0
+        err := Search(dir, "app", term, func(file, line string) bool {
+            found++
+            return true
+        })
+        if err != nil {
+            t.Fatal(err)
+        }
+        if found != 1 {
+            t.Errorf("Search(%q) 找到%d行, 期望1行", term, found)
+        }
+    }
+}
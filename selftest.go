@@ -0,0 +1,109 @@
+package jLogger
+
+import (
+    "bufio"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "strings"
+    "time"
+)
+
+// SelfTest等待标记记录出现在文件中的最长时间
+const selfTestTimeout = 5 * time.Second
+
+// SelfTestResult SelfTest的结果
+type SelfTestResult struct {
+    Token   string                   `json:"token"`             // 标记记录的selftest字段值
+    Latency map[string]time.Duration `json:"latency"`           // 各级别从写入到在文件中读到的耗时
+    Skipped []string                 `json:"skipped,omitempty"` // 输出被SetOutput替换成非文件、无法回读的级别
+}
+
+// SelfTest 端到端检查日志管道：向每个级别写一条带随机selftest字段的标记记录（经过通道、缓冲和编码，
+// 不受级别、采样和降级的过滤），刷新后从文件中读回，检查内容完整并统计耗时。
+// 用于服务开始接收流量之前的就绪检查，任何一个级别失败都返回错误。标记记录会留在日志中
+func (l *Logger) SelfTest() (SelfTestResult, error) {
+    l = l.pipeline()
+    res := SelfTestResult{Token: newSpanID(), Latency: make(map[string]time.Duration, len(levelNames))}
+
+    type check struct {
+        path   string
+        offset int64
+    }
+    checks := make(map[string]check, len(levelNames))
+    start := l.now()
+    for _, level := range levelNames {
+        logger, _, _ := l.levelOutput(level)
+        lj := rotatingFile(logger.Writer())
+        if lj == nil {
+            res.Skipped = append(res.Skipped, level)
+            continue
+        }
+        // 只读取标记写入之后的部分，文件很大时不必从头扫描
+        c := check{path: lj.Filename}
+        if info, err := os.Stat(lj.Filename); err == nil {
+            c.offset = info.Size()
+        }
+        checks[level] = c
+        msg := l.newMessage(level, start, []interface{}{"selftest"}, String("selftest", res.Token))
+        msg.internal = true
+        l.enqueue(logger, msg)
+    }
+    l.Flush()
+
+    var errs []error
+    deadline := time.Now().Add(selfTestTimeout)
+    for _, level := range levelNames {
+        c, ok := checks[level]
+        if !ok {
+            continue
+        }
+        for {
+            found, err := findSelfTest(c.path, c.offset, res.Token)
+            if found {
+                res.Latency[level] = time.Since(start)
+                break
+            }
+            if err != nil || time.Now().After(deadline) {
+                if err == nil {
+                    err = errors.New("超时未读到标记记录")
+                }
+                errs = append(errs, fmt.Errorf("%s %s: %v", level, c.path, err))
+                break
+            }
+            time.Sleep(10 * time.Millisecond)
+        }
+    }
+    return res, errors.Join(errs...)
+}
+
+// 在文件offset之后查找标记记录，找到时检查消息和字段是否完整。
+// 期间发生了轮转、文件比offset短时从头查找
+func findSelfTest(path string, offset int64, token string) (bool, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return false, err
+    }
+    defer f.Close()
+    if info, err := f.Stat(); err == nil && info.Size() >= offset {
+        f.Seek(offset, io.SeekStart)
+    }
+    scanner := bufio.NewScanner(f)
+    scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+    for scanner.Scan() {
+        r, ok := ParseRecord(scanner.Text())
+        if !ok {
+            continue
+        }
+        if got, _ := r.field("selftest"); got != token {
+            continue
+        }
+        // 消息前可能有SetPrefix设置的前缀
+        if !strings.HasSuffix(r.Message, "selftest") {
+            return false, fmt.Errorf("标记记录不完整: %q", scanner.Text())
+        }
+        return true, nil
+    }
+    return false, scanner.Err()
+}
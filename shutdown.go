@@ -0,0 +1,39 @@
+package jLogger
+
+import (
+    "context"
+    "sync"
+)
+
+// FlushOnDone ctx被取消时刷新所有缓冲区，closeLogger为true时直接Close（同样会刷新）。
+// 适合和errgroup、http.Server的关闭流程配合：把服务的根context传进来，退出时日志不会留在缓冲区里。
+// 返回的stop用于取消监听；Logger先被关闭时监听自动结束
+func (l *Logger) FlushOnDone(ctx context.Context, closeLogger bool) (stop func()) {
+    root := l.pipeline()
+    done := make(chan struct{})
+
+    go func() {
+        select {
+        case <-ctx.Done():
+            select {
+            case <-root.done:
+                return
+            default:
+            }
+            if closeLogger {
+                root.Close()
+            } else {
+                root.Flush()
+            }
+        case <-root.done:
+        case <-done:
+        }
+    }()
+
+    var once sync.Once
+    return func() {
+        once.Do(func() {
+            close(done)
+        })
+    }
+}
@@ -0,0 +1,58 @@
+package jLogger
+
+import (
+    "os"
+    "os/signal"
+    "sync"
+    "syscall"
+)
+
+// HandleSignals 安装信号处理：收到SIGINT/SIGTERM（或传入的sigs）时Close刷新所有缓冲区，
+// 然后恢复该信号的默认处理并重新发给自己，进程按原本的方式退出（退出码与未安装处理时一致）。
+// 程序需要自己处理退出流程时使用HandleSignalsNotify。返回的stop用于取消监听
+func (l *Logger) HandleSignals(sigs ...os.Signal) (stop func()) {
+    return l.handleSignals(nil, sigs)
+}
+
+// HandleSignalsNotify 同HandleSignals，但Close之后不退出进程，而是把收到的信号发送到ch，
+// 由程序继续自己的退出流程。ch应当有缓冲或有协程在接收
+func (l *Logger) HandleSignalsNotify(ch chan<- os.Signal, sigs ...os.Signal) (stop func()) {
+    return l.handleSignals(ch, sigs)
+}
+
+func (l *Logger) handleSignals(notify chan<- os.Signal, sigs []os.Signal) (stop func()) {
+    if len(sigs) == 0 {
+        sigs = []os.Signal{os.Interrupt, syscall.SIGTERM}
+    }
+    ch := make(chan os.Signal, 1)
+    done := make(chan struct{})
+    signal.Notify(ch, sigs...)
+
+    var once sync.Once
+    stop = func() {
+        once.Do(func() {
+            signal.Stop(ch)
+            close(done)
+        })
+    }
+
+    go func() {
+        select {
+        case sig := <-ch:
+            l.pipeline().Close()
+            stop()
+            if notify != nil {
+                notify <- sig
+                return
+            }
+            signal.Reset(sig)
+            if p, err := os.FindProcess(os.Getpid()); err == nil && p.Signal(sig) == nil {
+                return
+            }
+            // 不支持向自己发信号的平台（如Windows），直接退出
+            os.Exit(1)
+        case <-done:
+        }
+    }()
+    return stop
+}
@@ -0,0 +1,74 @@
+//go:build !windows
+
+package jLogger
+
+import (
+    "errors"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "strings"
+    "syscall"
+    "testing"
+    "time"
+)
+
+func TestHandleSignalsNotify(t *testing.T) {
+    l, err := NewLogger(t.TempDir(), "app", 16, time.Hour, "INFO")
+    if err != nil {
+        t.Fatal(err)
+    }
+    app := make(chan os.Signal, 1)
+    stop := l.HandleSignalsNotify(app, syscall.SIGUSR1)
+    defer stop()
+    if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+        t.Fatal(err)
+    }
+    select {
+    case sig := <-app:
+        if sig != syscall.SIGUSR1 {
+            t.Fatalf("收到的信号为%v, 期望SIGUSR1", sig)
+        }
+    case <-time.After(time.Second):
+        t.Fatal("程序没有收到转交的信号")
+    }
+    select {
+    case <-l.done:
+    default:
+        t.Fatal("信号转交给程序时Logger还没有关闭")
+    }
+}
+
+func TestHandleSignalsReraises(t *testing.T) {
+    if dir := os.Getenv("JLOGGER_SIGNAL_CHILD"); dir != "" {
+        l, err := NewLogger(dir, "app", 16, time.Hour, "INFO")
+        if err != nil {
+            t.Fatal(err)
+        }
+        // Go程序对SIGUSR1的默认处理是忽略，这里用默认会退出进程的SIGTERM
+        l.HandleSignals(syscall.SIGTERM)
+        l.Info("before signal")
+        syscall.Kill(os.Getpid(), syscall.SIGTERM)
+        time.Sleep(5 * time.Second)
+        os.Exit(0)
+    }
+
+    dir := t.TempDir()
+    cmd := exec.Command(os.Args[0], "-test.run=^TestHandleSignalsReraises$")
+    cmd.Env = append(os.Environ(), "JLOGGER_SIGNAL_CHILD="+dir)
+    err := cmd.Run()
+    var exitErr *exec.ExitError
+    if !errors.As(err, &exitErr) {
+        t.Fatalf("子进程没有因信号退出: %v", err)
+    }
+    if ws, ok := exitErr.Sys().(syscall.WaitStatus); !ok || !ws.Signaled() || ws.Signal() != syscall.SIGTERM {
+        t.Fatalf("子进程的退出状态为%v, 期望被SIGTERM终止", err)
+    }
+    data, err := os.ReadFile(filepath.Join(dir, "app_info.log"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !strings.Contains(string(data), "before signal") {
+        t.Fatal("退出前缓冲区中的记录没有写入文件")
+    }
+}
@@ -0,0 +1,316 @@
+package jLogger
+
+import (
+    "errors"
+    "sort"
+    "sync"
+    "sync/atomic"
+    "time"
+)
+
+// 每个Sink最多积压的记录数（不含正在重试的一批），超过时丢弃最旧的批次。
+// 按记录数而不是批次数限制：通道已满时的同步写入每条记录单独成一批，按批次计数会挤掉积压中完整的大批次
+const sinkBacklogLimit = 100000
+
+// Sink写入失败后的重试间隔，每次失败翻倍，直到上限
+const (
+    sinkRetryMin = 100 * time.Millisecond
+    sinkRetryMax = 30 * time.Second
+)
+
+// Sink 日志文件之外的输出目标，每次刷新缓冲区后收到这一批已写入文件的记录。
+// batch由所有Sink共享，不能修改；WriteBatch只在该Sink自己的投递协程中调用，不会并发
+type Sink interface {
+    WriteBatch(batch []Record) error
+    Close() error
+}
+
+// ErrSinkPermanent WriteBatch返回的错误包装了它时（fmt.Errorf("%w: ...", ErrSinkPermanent)），
+// 这一批不再重试，直接丢弃或写入死信文件，用于重试也不会成功的错误，例如数据本身无法编码
+var ErrSinkPermanent = errors.New("不可重试的Sink错误")
+
+// SinkHealth 单个Sink的投递状态
+type SinkHealth struct {
+    Name          string    `json:"name"`
+    Healthy       bool      `json:"healthy"`   // 最近一次写入是否成功
+    Backlog       int       `json:"backlog"`   // 等待投递的批次数，含正在重试的一批
+    Delivered     uint64    `json:"delivered"` // 已投递的记录数
+    Failures      uint64    `json:"failures"`  // 写入失败的次数，重试也计入
+    Dropped       uint64    `json:"dropped"`   // 积压超限、不可重试或关闭时仍未投递而丢弃的记录数，配置了WithDeadLetter时写入死信文件
+    LastError     string    `json:"last_error,omitempty"`
+    LastErrorTime time.Time `json:"last_error_time,omitempty"`
+}
+
+// 每个Sink有自己的投递协程和积压队列：一个Sink变慢或不可用时只在自己的队列中积压并重试，
+// 不会阻塞刷新缓冲区，也不影响其他Sink。恢复后按顺序补发积压的批次
+type sinkWorker struct {
+    l      *Logger
+    name   string
+    sink   Sink
+    notify chan struct{}
+    stop   chan struct{}
+    exited chan struct{}
+
+    mu     sync.Mutex
+    queue  [][]Record
+    queued int      // queue中的记录数
+    retry  []Record // 写入失败、等待重试的一批
+    health SinkHealth
+}
+
+func newSinkWorker(l *Logger, name string, s Sink) *sinkWorker {
+    w := &sinkWorker{
+        l:      l,
+        name:   name,
+        sink:   s,
+        notify: make(chan struct{}, 1),
+        stop:   make(chan struct{}),
+        exited: make(chan struct{}),
+        health: SinkHealth{Name: name, Healthy: true},
+    }
+    go w.run()
+    return w
+}
+
+func (w *sinkWorker) push(batch []Record) {
+    w.mu.Lock()
+    w.queue = append(w.queue, batch)
+    w.queued += len(batch)
+    var dropped [][]Record
+    for w.queued > sinkBacklogLimit && len(w.queue) > 1 {
+        oldest := w.queue[0]
+        w.queue[0] = nil
+        w.queue = w.queue[1:]
+        w.queued -= len(oldest)
+        w.health.Dropped += uint64(len(oldest))
+        dropped = append(dropped, oldest)
+    }
+    lastError := w.health.LastError
+    w.mu.Unlock()
+    if dropped != nil {
+        w.l.deadLetter(w.name, "积压超限: "+lastError, dropped...)
+    }
+    select {
+    case w.notify <- struct{}{}:
+    default:
+    }
+}
+
+// 取下一批：优先重试失败的一批
+func (w *sinkWorker) next() ([]Record, bool) {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    if w.retry != nil {
+        return w.retry, true
+    }
+    if len(w.queue) == 0 {
+        return nil, false
+    }
+    batch := w.queue[0]
+    w.queue[0] = nil
+    w.queue = w.queue[1:]
+    w.queued -= len(batch)
+    w.retry = batch
+    return batch, true
+}
+
+func (w *sinkWorker) run() {
+    defer close(w.exited)
+    backoff := sinkRetryMin
+    for {
+        batch, ok := w.next()
+        if !ok {
+            select {
+            case <-w.notify:
+                continue
+            case <-w.stop:
+                return
+            }
+        }
+        err := w.sink.WriteBatch(batch)
+        if err == nil {
+            w.delivered(len(batch))
+            backoff = sinkRetryMin
+            continue
+        }
+        w.failed(err)
+        if errors.Is(err, ErrSinkPermanent) {
+            w.giveUp(batch, err)
+            continue
+        }
+        // 关闭时不再等待重试，积压的批次计为丢弃
+        select {
+        case <-time.After(backoff):
+        case <-w.stop:
+            w.discard()
+            return
+        }
+        if backoff *= 2; backoff > sinkRetryMax {
+            backoff = sinkRetryMax
+        }
+    }
+}
+
+func (w *sinkWorker) delivered(n int) {
+    w.mu.Lock()
+    recovered := !w.health.Healthy
+    w.retry = nil
+    w.health.Healthy = true
+    w.health.Delivered += uint64(n)
+    backlog := len(w.queue)
+    w.mu.Unlock()
+    if recovered {
+        w.l.writeDirect("INFO", "Sink恢复:", w.name, "积压批次:", backlog)
+    }
+}
+
+// 只在由正常变为失败时记录内部错误，重试期间的失败只计数，避免刷屏
+func (w *sinkWorker) failed(err error) {
+    w.mu.Lock()
+    first := w.health.Healthy
+    w.health.Healthy = false
+    w.health.Failures++
+    w.health.LastError = err.Error()
+    w.health.LastErrorTime = time.Now()
+    w.mu.Unlock()
+    if first {
+        w.l.internalError("Sink写入失败:", w.name, err)
+    }
+}
+
+// 放弃一批不可重试的记录
+func (w *sinkWorker) giveUp(batch []Record, err error) {
+    w.mu.Lock()
+    w.retry = nil
+    w.health.Dropped += uint64(len(batch))
+    w.mu.Unlock()
+    w.l.deadLetter(w.name, err.Error(), batch)
+}
+
+func (w *sinkWorker) discard() {
+    w.mu.Lock()
+    batches := append([][]Record{w.retry}, w.queue...)
+    n := 0
+    for _, batch := range batches {
+        n += len(batch)
+    }
+    w.retry, w.queue, w.queued = nil, nil, 0
+    w.health.Dropped += uint64(n)
+    lastError := w.health.LastError
+    w.mu.Unlock()
+    if n > 0 {
+        w.l.internalError("Sink关闭时仍不可用，丢弃记录:", w.name, n)
+        w.l.deadLetter(w.name, "关闭时不可用: "+lastError, batches...)
+    }
+}
+
+func (w *sinkWorker) state() SinkHealth {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    h := w.health
+    h.Backlog = len(w.queue)
+    if w.retry != nil {
+        h.Backlog++
+    }
+    return h
+}
+
+// 停止投递协程并关闭Sink：可用的Sink先补发完积压的批次，不可用的直接丢弃
+func (w *sinkWorker) close() {
+    close(w.stop)
+    <-w.exited
+    if err := w.sink.Close(); err != nil {
+        w.l.internalError("关闭Sink失败:", w.name, err)
+    }
+}
+
+// AddSink 在运行中的Logger上挂载一个输出目标，同名的已有Sink会被替换：
+// 等待旧Sink投递完积压的批次后再关闭它，之后的批次投递给新Sink
+func (l *Logger) AddSink(name string, s Sink) {
+    l = l.pipeline()
+    w := newSinkWorker(l, name, s)
+    l.sinks_mu.Lock()
+    old := l.sinks[name]
+    if l.sinks == nil {
+        l.sinks = make(map[string]*sinkWorker)
+    }
+    l.sinks[name] = w
+    atomic.StoreInt32(&l.sinkCount, int32(len(l.sinks)))
+    l.sinks_mu.Unlock()
+
+    if old != nil {
+        old.close()
+    }
+}
+
+// RemoveSink 卸载并关闭一个输出目标，积压的批次投递完成（或Sink不可用而丢弃）后才返回
+func (l *Logger) RemoveSink(name string) {
+    l = l.pipeline()
+    l.sinks_mu.Lock()
+    w, ok := l.sinks[name]
+    delete(l.sinks, name)
+    atomic.StoreInt32(&l.sinkCount, int32(len(l.sinks)))
+    l.sinks_mu.Unlock()
+
+    if ok {
+        w.close()
+    }
+}
+
+// SinkHealth 返回各Sink的投递状态，按名称排序
+func (l *Logger) SinkHealth() []SinkHealth {
+    l = l.pipeline()
+    l.sinks_mu.RLock()
+    health := make([]SinkHealth, 0, len(l.sinks))
+    for _, w := range l.sinks {
+        health = append(health, w.state())
+    }
+    l.sinks_mu.RUnlock()
+    sort.Slice(health, func(i, j int) bool { return health[i].Name < health[j].Name })
+    return health
+}
+
+// 把一批刚写入文件的记录放入每个Sink的投递队列，不等待投递完成。心跳和START/STOP标记只写文件，不投递
+func (l *Logger) deliver(msgs []logMessage) {
+    if len(msgs) == 0 || atomic.LoadInt32(&l.sinkCount) == 0 {
+        return
+    }
+    batch := make([]Record, 0, len(msgs))
+    for _, msg := range msgs {
+        if !msg.fileOnly {
+            batch = append(batch, l.toRecord(msg))
+        }
+    }
+    if len(batch) == 0 {
+        return
+    }
+
+    l.sinks_mu.RLock()
+    defer l.sinks_mu.RUnlock()
+    for name, w := range l.sinks {
+        if f := l.sinkFields[name]; f != nil {
+            w.push(f.apply(batch))
+        } else {
+            w.push(batch)
+        }
+    }
+}
+
+// Close时关闭所有Sink
+func (l *Logger) closeSinks() {
+    l.sinks_mu.Lock()
+    sinks := l.sinks
+    l.sinks = nil
+    atomic.StoreInt32(&l.sinkCount, 0)
+    l.sinks_mu.Unlock()
+
+    var wg sync.WaitGroup
+    for _, w := range sinks {
+        wg.Add(1)
+        go func(w *sinkWorker) {
+            defer wg.Done()
+            w.close()
+        }(w)
+    }
+    wg.Wait()
+}
@@ -0,0 +1,77 @@
+package jLogger
+
+import (
+    "sync"
+    "sync/atomic"
+    "testing"
+)
+
+func TestSinkBacklogCountsRecords(t *testing.T) {
+    l := newTestLogger(t, "INFO")
+    // 不启动投递协程，只检查积压队列
+    w := &sinkWorker{l: l, name: "test", notify: make(chan struct{}, 1)}
+    w.push(make([]Record, 1000))
+    for i := 0; i < sinkBacklogLimit-1000; i++ {
+        w.push(make([]Record, 1))
+    }
+    if h := w.state(); h.Dropped != 0 {
+        t.Fatalf("没有超过记录数上限时丢弃了%d条", h.Dropped)
+    }
+    // 单条记录的批次把积压推过上限，只丢弃最旧的一批
+    w.push(make([]Record, 1))
+    h := w.state()
+    if h.Dropped != 1000 {
+        t.Fatalf("丢弃了%d条, 期望最旧的1000条", h.Dropped)
+    }
+    if w.queued != sinkBacklogLimit-999 {
+        t.Fatalf("积压%d条, 期望%d条", w.queued, sinkBacklogLimit-999)
+    }
+}
+
+func TestAddSinkHotSwap(t *testing.T) {
+    l := newTestLogger(t, "INFO")
+    var delivered int64
+    var closedWrites int64
+    newSink := func() Sink {
+        var closed int32
+        s := NewCallbackSink(func(batch []Record) error {
+            if atomic.LoadInt32(&closed) == 1 {
+                atomic.AddInt64(&closedWrites, 1)
+            }
+            atomic.AddInt64(&delivered, int64(len(batch)))
+            return nil
+        })
+        return s.OnClose(func() { atomic.StoreInt32(&closed, 1) })
+    }
+    l.AddSink("agent", newSink())
+
+    const n = 3000
+    var wg sync.WaitGroup
+    wg.Add(1)
+    go func() {
+        defer wg.Done()
+        for i := 0; i < n; i++ {
+            l.Info("record", i)
+        }
+    }()
+    // 写入期间反复替换同名Sink，每条记录恰好投递给其中一代
+    for i := 0; i < 20; i++ {
+        l.AddSink("agent", newSink())
+    }
+    wg.Wait()
+    l.Flush()
+    l.RemoveSink("agent")
+
+    if got := atomic.LoadInt64(&delivered); got != n {
+        t.Fatalf("替换Sink期间投递了%d条, 期望%d条", got, n)
+    }
+    if got := atomic.LoadInt64(&closedWrites); got != 0 {
+        t.Fatalf("Sink关闭之后仍有%d批写入", got)
+    }
+
+    l.Info("after remove")
+    l.Flush()
+    if got := atomic.LoadInt64(&delivered); got != n {
+        t.Fatalf("RemoveSink之后仍有记录投递: %d", got-n)
+    }
+}
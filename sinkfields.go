@@ -0,0 +1,189 @@
+package jLogger
+
+import (
+    "fmt"
+    "strings"
+)
+
+// FieldFilter 按字段名过滤投递给某个Sink的字段。Allow不为空时只保留其中的字段，Deny中的字段总是去掉。
+// 分组中的字段既可以用完整路径（如 req.password_hash）也可以只用字段名（password_hash）匹配；
+// Allow中列出分组本身时保留整个分组，否则分组只保留允许的子字段，子字段全部去掉时分组也去掉。
+// 只影响结构化字段，消息、时间、级别、module、tag和seq总是保留；日志文件不受影响
+type FieldFilter struct {
+    Allow []string `json:"allow,omitempty"`
+    Deny  []string `json:"deny,omitempty"`
+}
+
+type fieldFilter struct {
+    allow map[string]bool
+    deny  map[string]bool
+}
+
+func compileFieldFilter(f FieldFilter) (*fieldFilter, error) {
+    c := &fieldFilter{deny: make(map[string]bool, len(f.Deny))}
+    for _, key := range f.Deny {
+        if key == "" {
+            return nil, fmt.Errorf("deny中有空的字段名")
+        }
+        c.deny[key] = true
+    }
+    if len(f.Allow) > 0 {
+        c.allow = make(map[string]bool, len(f.Allow))
+        for _, key := range f.Allow {
+            if key == "" {
+                return nil, fmt.Errorf("allow中有空的字段名")
+            }
+            c.allow[key] = true
+        }
+    }
+    return c, nil
+}
+
+func (f *fieldFilter) denied(path, key string) bool {
+    return f.deny[path] || f.deny[key]
+}
+
+func (f *fieldFilter) allowed(path, key string) bool {
+    return f.allow == nil || f.allow[path] || f.allow[key]
+}
+
+// 过滤一层字段，prefix为所在分组的路径（顶层为空）。没有字段被去掉时返回原切片和false，不复制
+func (f *fieldFilter) filter(prefix string, fields []Field) ([]Field, bool) {
+    var out []Field
+    removed := false
+    for j, field := range fields {
+        path := field.Key
+        if prefix != "" && field.Key != "" {
+            path = prefix + "." + field.Key
+        } else if field.Key == "" {
+            path = prefix
+        }
+        kept, changed := f.keep(path, field)
+        if !changed && !removed {
+            continue
+        }
+        if !removed {
+            removed = true
+            out = append(make([]Field, 0, len(fields)), fields[:j]...)
+        }
+        if kept != nil {
+            out = append(out, *kept)
+        }
+    }
+    if !removed {
+        return fields, false
+    }
+    return out, true
+}
+
+// 过滤单个字段，返回保留的字段（nil表示去掉）以及是否有变化。分组在父级没有被允许时递归过滤。
+// 从文件读回的记录中分组已经展开成带点的key，按最后一段匹配字段名
+func (f *fieldFilter) keep(path string, field Field) (*Field, bool) {
+    name := field.Key
+    if i := strings.LastIndexByte(name, '.'); i >= 0 {
+        name = name[i+1:]
+    }
+    if field.Key != "" && f.denied(path, name) {
+        return nil, true
+    }
+    if field.kind != kindGroup {
+        if f.allowed(path, name) {
+            return &field, false
+        }
+        return nil, true
+    }
+    sub, _ := field.Value.([]Field)
+    if field.Key != "" && f.allow != nil && f.allowed(path, name) {
+        // 分组整体被允许，子字段只再检查deny
+        f = &fieldFilter{deny: f.deny}
+    }
+    kept, changed := f.filter(path, sub)
+    if !changed {
+        return &field, false
+    }
+    if len(kept) == 0 {
+        return nil, true
+    }
+    field.Value = kept
+    return &field, true
+}
+
+// 返回过滤后的批次，没有字段被去掉时返回原批次，不复制
+func (f *fieldFilter) apply(batch []Record) []Record {
+    var out []Record
+    for i, r := range batch {
+        fields, removed := f.filter("", r.Fields)
+        if removed && out == nil {
+            out = append(make([]Record, 0, len(batch)), batch[:i]...)
+        }
+        if out != nil {
+            if removed {
+                r.Fields = fields
+            }
+            out = append(out, r)
+        }
+    }
+    if out == nil {
+        return batch
+    }
+    return out
+}
+
+// SetSinkFields 设置投递给name的字段过滤规则，Sink可以稍后再挂载；Allow和Deny都为空时清除规则。
+// 例如 l.SetSinkFields("remote", jLogger.FieldFilter{Deny: []string{"body", "password_hash"}})
+func (l *Logger) SetSinkFields(name string, f FieldFilter) error {
+    l = l.pipeline()
+    c, err := compileFieldFilter(f)
+    if err != nil {
+        return fmt.Errorf("Sink %s: %v", name, err)
+    }
+    l.sinks_mu.Lock()
+    defer l.sinks_mu.Unlock()
+    if len(f.Allow) == 0 && len(f.Deny) == 0 {
+        delete(l.sinkFields, name)
+        delete(l.sinkFieldSpecs, name)
+        return nil
+    }
+    if l.sinkFields == nil {
+        l.sinkFields = make(map[string]*fieldFilter)
+        l.sinkFieldSpecs = make(map[string]FieldFilter)
+    }
+    l.sinkFields[name] = c
+    l.sinkFieldSpecs[name] = f
+    return nil
+}
+
+// 检查并编译一组过滤规则，任何一条无效时返回错误
+func compileSinkFields(specs map[string]FieldFilter) (map[string]*fieldFilter, error) {
+    compiled := make(map[string]*fieldFilter, len(specs))
+    for name, f := range specs {
+        c, err := compileFieldFilter(f)
+        if err != nil {
+            return nil, fmt.Errorf("Sink %s: %v", name, err)
+        }
+        compiled[name] = c
+    }
+    return compiled, nil
+}
+
+// 整体替换所有Sink的过滤规则，用于远程配置
+func (l *Logger) replaceSinkFields(specs map[string]FieldFilter, compiled map[string]*fieldFilter) {
+    l.sinks_mu.Lock()
+    l.sinkFields = compiled
+    l.sinkFieldSpecs = specs
+    l.sinks_mu.Unlock()
+}
+
+// 当前的过滤规则的副本，用于EffectiveConfig
+func (l *Logger) sinkFieldRules() map[string]FieldFilter {
+    l.sinks_mu.RLock()
+    defer l.sinks_mu.RUnlock()
+    if len(l.sinkFieldSpecs) == 0 {
+        return nil
+    }
+    rules := make(map[string]FieldFilter, len(l.sinkFieldSpecs))
+    for name, f := range l.sinkFieldSpecs {
+        rules[name] = f
+    }
+    return rules
+}
@@ -0,0 +1,62 @@
+package jLogger
+
+import "testing"
+
+func TestFieldFilterGroups(t *testing.T) {
+    batch := []Record{{
+        Message: "login",
+        Fields: []Field{
+            String("user", "alice"),
+            String("password_hash", "x"),
+            Group("req",
+                String("path", "/login"),
+                String("password_hash", "y"),
+                Group("", String("token", "z")),
+            ),
+        },
+    }}
+    keys := func(f *fieldFilter) []string {
+        var out []string
+        for _, field := range flattenGroups(f.apply(batch)[0].Fields) {
+            out = append(out, field.Key)
+        }
+        return out
+    }
+    cases := []struct {
+        filter FieldFilter
+        want   []string
+    }{
+        {FieldFilter{Deny: []string{"password_hash"}}, []string{"user", "req.path", "req.token"}},
+        {FieldFilter{Deny: []string{"req.password_hash"}}, []string{"user", "password_hash", "req.path", "req.token"}},
+        {FieldFilter{Deny: []string{"req.token"}}, []string{"user", "password_hash", "req.path", "req.password_hash"}},
+        {FieldFilter{Allow: []string{"user", "req.path"}}, []string{"user", "req.path"}},
+        {FieldFilter{Allow: []string{"req"}, Deny: []string{"password_hash"}}, []string{"req.path", "req.token"}},
+        {FieldFilter{Allow: []string{"user"}}, []string{"user"}},
+    }
+    for _, c := range cases {
+        f, err := compileFieldFilter(c.filter)
+        if err != nil {
+            t.Fatal(err)
+        }
+        got := keys(f)
+        if len(got) != len(c.want) {
+            t.Errorf("%+v: 保留 %v, 期望 %v", c.filter, got, c.want)
+            continue
+        }
+        for i := range got {
+            if got[i] != c.want[i] {
+                t.Errorf("%+v: 保留 %v, 期望 %v", c.filter, got, c.want)
+                break
+            }
+        }
+    }
+    // 从文件读回的记录，分组已经展开
+    f, _ := compileFieldFilter(FieldFilter{Deny: []string{"password_hash"}})
+    flat := f.apply([]Record{{Fields: []Field{String("req.password_hash", "y"), String("req.path", "/")}}})
+    if len(flat[0].Fields) != 1 || flat[0].Fields[0].Key != "req.path" {
+        t.Errorf("展开的key未被过滤: %v", flat[0].Fields)
+    }
+    if len(batch[0].Fields) != 3 || len(batch[0].Fields[2].Value.([]Field)) != 3 {
+        t.Fatal("过滤不应修改原批次")
+    }
+}
@@ -0,0 +1,154 @@
+package jLogger
+
+import (
+    "context"
+    "log/slog"
+)
+
+// SlogHandlerOptions NewSlogHandler的配置
+type SlogHandlerOptions struct {
+    Level slog.Leveler // 最低级别，为nil时由Logger自己的级别决定
+}
+
+// NewSlogHandler 返回把log/slog的记录写入l的slog.Handler：slog.New(jLogger.NewSlogHandler(log, nil))。
+// Debug写入Debug，Info写入Info，Warn及以上写入Error（jLogger没有Warn级别）。
+// 属性转换为字段，WithGroup和slog.Group转换为Group字段，输出为带点的key或嵌套对象，见WithNestedGroups。
+// slog记录的时间不使用，时间仍由Logger的时钟决定
+func NewSlogHandler(l Interface, opts *SlogHandlerOptions) slog.Handler {
+    h := &slogHandler{l: l}
+    if opts != nil {
+        h.level = opts.Level
+    }
+    return h
+}
+
+type slogHandler struct {
+    l      Interface
+    level  slog.Leveler
+    attrs  []Field     // 打开分组之前添加的属性
+    groups []slogGroup // WithGroup打开的分组，从外到内
+}
+
+type slogGroup struct {
+    name  string
+    attrs []Field // 打开该分组之后、下一个分组之前添加的属性
+}
+
+func slogLevel(level slog.Level) string {
+    switch {
+    case level >= slog.LevelWarn:
+        return "ERROR"
+    case level >= slog.LevelInfo:
+        return "INFO"
+    }
+    return "DEBUG"
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+    if h.level != nil {
+        return level >= h.level.Level()
+    }
+    if e, ok := h.l.(interface{ enabled(string) bool }); ok {
+        return e.enabled(slogLevel(level))
+    }
+    return true
+}
+
+func (h *slogHandler) Handle(_ context.Context, r slog.Record) error {
+    fields := make([]Field, 0, r.NumAttrs())
+    r.Attrs(func(a slog.Attr) bool {
+        if f, ok := attrField(a); ok {
+            fields = append(fields, f)
+        }
+        return true
+    })
+    // 从最内层的分组向外包装，空分组按slog的规则省略
+    for i := len(h.groups) - 1; i >= 0; i-- {
+        g := h.groups[i]
+        inner := append(append(make([]Field, 0, len(g.attrs)+len(fields)), g.attrs...), fields...)
+        fields = nil
+        if len(inner) > 0 {
+            fields = []Field{Group(g.name, inner...)}
+        }
+    }
+    if len(h.attrs) > 0 {
+        fields = append(append(make([]Field, 0, len(h.attrs)+len(fields)), h.attrs...), fields...)
+    }
+
+    switch slogLevel(r.Level) {
+    case "ERROR":
+        h.l.ErrorFields(r.Message, fields...)
+    case "INFO":
+        h.l.InfoFields(r.Message, fields...)
+    default:
+        h.l.DebugFields(r.Message, fields...)
+    }
+    return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+    fields := make([]Field, 0, len(attrs))
+    for _, a := range attrs {
+        if f, ok := attrField(a); ok {
+            fields = append(fields, f)
+        }
+    }
+    if len(fields) == 0 {
+        return h
+    }
+    h2 := *h
+    if len(h.groups) == 0 {
+        h2.attrs = append(append([]Field(nil), h.attrs...), fields...)
+        return &h2
+    }
+    h2.groups = append([]slogGroup(nil), h.groups...)
+    last := &h2.groups[len(h2.groups)-1]
+    last.attrs = append(append([]Field(nil), last.attrs...), fields...)
+    return &h2
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+    if name == "" {
+        return h
+    }
+    h2 := *h
+    h2.groups = append(append([]slogGroup(nil), h.groups...), slogGroup{name: name})
+    return &h2
+}
+
+// 把slog的属性转换为字段，空属性按slog的规则忽略
+func attrField(a slog.Attr) (Field, bool) {
+    v := a.Value.Resolve()
+    if a.Key == "" && v.Kind() != slog.KindGroup {
+        return Field{}, false
+    }
+    switch v.Kind() {
+    case slog.KindString:
+        return String(a.Key, v.String()), true
+    case slog.KindInt64:
+        return Int64(a.Key, v.Int64()), true
+    case slog.KindUint64:
+        return Any(a.Key, v.Uint64()), true
+    case slog.KindFloat64:
+        return Float64(a.Key, v.Float64()), true
+    case slog.KindBool:
+        return Bool(a.Key, v.Bool()), true
+    case slog.KindDuration:
+        return Duration(a.Key, v.Duration()), true
+    case slog.KindTime:
+        return Time(a.Key, v.Time()), true
+    case slog.KindGroup:
+        attrs := v.Group()
+        fields := make([]Field, 0, len(attrs))
+        for _, sub := range attrs {
+            if f, ok := attrField(sub); ok {
+                fields = append(fields, f)
+            }
+        }
+        if len(fields) == 0 {
+            return Field{}, false
+        }
+        return Group(a.Key, fields...), true
+    }
+    return Any(a.Key, v.Any()), true
+}
@@ -0,0 +1,218 @@
+package jLogger
+
+import (
+    "archive/tar"
+    "compress/gzip"
+    "encoding/json"
+    "io"
+    "log"
+    "os"
+    "path/filepath"
+    "strings"
+    "time"
+)
+
+// Snapshot除了刚轮转出的文件，还收集这段时间内修改过的归档
+const snapshotWindow = 24 * time.Hour
+
+// 刷新后轮转三个级别的当前文件，被SetOutput替换成不能轮转的Writer的级别跳过
+func (l *Logger) rotateAll() error {
+    l.Flush()
+    for _, logger := range []*log.Logger{l.InfoLogger, l.DebugLogger, l.ErrorLogger} {
+        if r, ok := logger.Writer().(interface{ Rotate() error }); ok {
+            if err := r.Rotate(); err != nil {
+                return err
+            }
+        }
+    }
+    return nil
+}
+
+// Snapshot 为提交问题准备日志包：刷新缓冲区并轮转所有级别的当前文件，把刚轮转出的文件和最近24小时内的归档
+// 复制到dst目录，同时写入当时的Stats（stats.json）。dst以.tar.gz或.tgz结尾时打成一个压缩包。
+// 返回收进日志包的文件名。轮转后lumberjack在后台压缩旧文件，正在压缩的文件取未压缩的版本
+func (l *Logger) Snapshot(dst string) ([]string, error) {
+    l = l.pipeline()
+    if err := l.rotateAll(); err != nil {
+        return nil, err
+    }
+    files, err := snapshotFiles(l.logDir, l.logPrefix, time.Now().Add(-snapshotWindow))
+    if err != nil {
+        return nil, err
+    }
+    stats, err := json.MarshalIndent(l.Stats(), "", "  ")
+    if err != nil {
+        return nil, err
+    }
+
+    var b snapshotBundle
+    if strings.HasSuffix(dst, ".tar.gz") || strings.HasSuffix(dst, ".tgz") {
+        b, err = newTarBundle(dst)
+    } else {
+        b, err = newDirBundle(dst)
+    }
+    if err != nil {
+        return nil, err
+    }
+    var added []string
+    for _, path := range files {
+        name, err := b.addFile(path)
+        if err != nil {
+            b.close()
+            return added, err
+        }
+        if name != "" {
+            added = append(added, name)
+        }
+    }
+    if err := b.addBytes("stats.json", stats); err != nil {
+        b.close()
+        return added, err
+    }
+    return added, b.close()
+}
+
+// 选出归档（不含当前文件）中在since之后修改过的；同一文件同时有压缩和未压缩的版本时说明正在压缩，只取未压缩的
+func snapshotFiles(logDir, logPrefix string, since time.Time) ([]string, error) {
+    paths, err := LogFiles(logDir, logPrefix)
+    if err != nil {
+        return nil, err
+    }
+    exists := make(map[string]bool, len(paths))
+    for _, path := range paths {
+        exists[path] = true
+    }
+    var files []string
+    for _, path := range paths {
+        if _, current := parseLogFileName(filepath.Base(path), logPrefix); current {
+            continue
+        }
+        if strings.HasSuffix(path, ".gz") && exists[strings.TrimSuffix(path, ".gz")] {
+            continue
+        }
+        info, err := os.Stat(path)
+        if err != nil || info.ModTime().Before(since) {
+            continue
+        }
+        files = append(files, path)
+    }
+    return files, nil
+}
+
+// 未压缩的文件在收集期间压缩完成时改为打开压缩后的文件
+func openSnapshotFile(path string) (*os.File, error) {
+    f, err := os.Open(path)
+    if os.IsNotExist(err) && !strings.HasSuffix(path, ".gz") {
+        return os.Open(path + ".gz")
+    }
+    return f, err
+}
+
+type snapshotBundle interface {
+    addFile(path string) (string, error) // 返回写入的文件名，文件在收集期间被清理掉时返回空
+    addBytes(name string, data []byte) error
+    close() error
+}
+
+type dirBundle struct {
+    dir string
+}
+
+func newDirBundle(dir string) (*dirBundle, error) {
+    if err := os.MkdirAll(dir, 0755); err != nil {
+        return nil, err
+    }
+    return &dirBundle{dir: dir}, nil
+}
+
+func (d *dirBundle) addFile(path string) (string, error) {
+    in, err := openSnapshotFile(path)
+    if os.IsNotExist(err) {
+        return "", nil
+    }
+    if err != nil {
+        return "", err
+    }
+    defer in.Close()
+    name := filepath.Base(in.Name())
+    out, err := os.Create(filepath.Join(d.dir, name))
+    if err != nil {
+        return "", err
+    }
+    if _, err := io.Copy(out, in); err != nil {
+        out.Close()
+        return "", err
+    }
+    return name, out.Close()
+}
+
+func (d *dirBundle) addBytes(name string, data []byte) error {
+    return os.WriteFile(filepath.Join(d.dir, name), data, 0644)
+}
+
+func (d *dirBundle) close() error {
+    return nil
+}
+
+type tarBundle struct {
+    f  *os.File
+    zw *gzip.Writer
+    tw *tar.Writer
+}
+
+func newTarBundle(path string) (*tarBundle, error) {
+    if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+        return nil, err
+    }
+    f, err := os.Create(path)
+    if err != nil {
+        return nil, err
+    }
+    zw := gzip.NewWriter(f)
+    return &tarBundle{f: f, zw: zw, tw: tar.NewWriter(zw)}, nil
+}
+
+func (t *tarBundle) addFile(path string) (string, error) {
+    in, err := openSnapshotFile(path)
+    if os.IsNotExist(err) {
+        return "", nil
+    }
+    if err != nil {
+        return "", err
+    }
+    defer in.Close()
+    // 以打开后的大小为准，文件之后再变化也不会写出不完整的条目
+    info, err := in.Stat()
+    if err != nil {
+        return "", err
+    }
+    name := filepath.Base(in.Name())
+    hdr := &tar.Header{Name: name, Mode: 0644, Size: info.Size(), ModTime: info.ModTime()}
+    if err := t.tw.WriteHeader(hdr); err != nil {
+        return "", err
+    }
+    if _, err := io.CopyN(t.tw, in, info.Size()); err != nil {
+        return "", err
+    }
+    return name, nil
+}
+
+func (t *tarBundle) addBytes(name string, data []byte) error {
+    hdr := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: time.Now()}
+    if err := t.tw.WriteHeader(hdr); err != nil {
+        return err
+    }
+    _, err := t.tw.Write(data)
+    return err
+}
+
+func (t *tarBundle) close() error {
+    err := t.tw.Close()
+    if e := t.zw.Close(); err == nil {
+        err = e
+    }
+    if e := t.f.Close(); err == nil {
+        err = e
+    }
+    return err
+}
@@ -0,0 +1,78 @@
+package jLogger
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/hex"
+    "sync"
+    "time"
+)
+
+type spanKey struct{}
+
+// Span 轻量的作用域日志：记录自动带上 [名称#ID] 前缀，开始和结束各写一条日志，
+// 在普通日志文件里也能看出类似trace的结构
+type Span struct {
+    *Logger
+    name   string
+    id     string
+    parent string
+    start  time.Time
+    ctx    context.Context
+    once   sync.Once
+}
+
+// Span 开始一个span：sp := log.Span(ctx, "sync-job"); defer sp.End(err)
+// ctx中已有span时记录父span的ID
+func (l *Logger) Span(ctx context.Context, name string) *Span {
+    if ctx == nil {
+        ctx = context.Background()
+    }
+    sp := &Span{name: name, id: newSpanID(), start: time.Now()}
+    if parent, ok := ctx.Value(spanKey{}).(*Span); ok {
+        sp.parent = parent.id
+    }
+    sp.Logger = l.CloneWith(WithPrefix("[" + name + "#" + sp.id + "]"))
+    sp.ctx = context.WithValue(ctx, spanKey{}, sp)
+
+    if sp.parent != "" {
+        sp.InfoFields("span start", String("parent_span", sp.parent))
+    } else {
+        sp.InfoFields("span start")
+    }
+    return sp
+}
+
+// ID 返回span的ID
+func (sp *Span) ID() string {
+    return sp.id
+}
+
+// Context 返回携带当前span的context，传给下游后创建的span会成为子span
+func (sp *Span) Context() context.Context {
+    return sp.ctx
+}
+
+// End 结束span并写一条带耗时和状态的日志：err为nil时status=ok写Info，否则status=error写Error。多次调用只生效一次
+func (sp *Span) End(err error) {
+    sp.once.Do(func() {
+        elapsed := Duration("duration", time.Since(sp.start))
+        if err != nil {
+            sp.ErrorFields("span end", String("status", "error"), elapsed, Err(err))
+            return
+        }
+        sp.InfoFields("span end", String("status", "ok"), elapsed)
+    })
+}
+
+// SpanFromContext 取出ctx中的span，没有时返回nil
+func SpanFromContext(ctx context.Context) *Span {
+    sp, _ := ctx.Value(spanKey{}).(*Span)
+    return sp
+}
+
+func newSpanID() string {
+    var b [6]byte
+    rand.Read(b[:])
+    return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,192 @@
+package jLogger
+
+import (
+    "bufio"
+    "encoding/json"
+    "fmt"
+    "os"
+    "path/filepath"
+    "sort"
+    "sync"
+    "time"
+)
+
+// 磁盘预写spool：记录进入通道之前先追加写入spool文件，写入日志文件后再追加一条确认(ack)，
+// 进程崩溃时，spool中有记录但没有ack的部分就是还停留在通道和缓冲区里、尚未落盘的日志。
+// 每行一个JSON对象：记录为 {"s":序号,"l":级别,"t":纳秒时间戳,"m":格式化后的消息}，确认为 {"a":[序号...]}
+// 没有待确认的记录时截断spool文件，避免无限增长
+type spool struct {
+    mu      sync.Mutex
+    path    string
+    file    *os.File
+    pending int // 已写入spool但尚未ack的记录数
+}
+
+type spoolEntry struct {
+    Seq   uint64   `json:"s,omitempty"`
+    Level string   `json:"l,omitempty"`
+    Time  int64    `json:"t,omitempty"`
+    Msg   string   `json:"m,omitempty"`
+    Ack   []uint64 `json:"a,omitempty"`
+}
+
+func spoolPath(logDir, logPrefix string) string {
+    return filepath.Join(logDir, logPrefix+".spool")
+}
+
+// 打开spool文件；上次运行遗留的非空spool说明进程异常退出，改名为 .pending 文件保留下来等待回放
+func openSpool(path string) (*spool, error) {
+    if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+        pending := fmt.Sprintf("%s.%s.pending", path, time.Now().Format("20060102150405.000000000"))
+        if err := os.Rename(path, pending); err != nil {
+            return nil, fmt.Errorf("保留遗留spool文件失败: %v", err)
+        }
+    }
+    file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND|os.O_TRUNC, 0644)
+    if err != nil {
+        return nil, fmt.Errorf("打开spool文件失败: %v", err)
+    }
+    return &spool{path: path, file: file}, nil
+}
+
+func (s *spool) write(e spoolEntry) {
+    data, err := json.Marshal(e)
+    if err != nil {
+        return
+    }
+    data = append(data, '\n')
+    s.file.Write(data)
+}
+
+// 在生产者协程中调用，写入page cache即可，进程崩溃不会丢失
+func (s *spool) append(msg logMessage, text string) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if s.file == nil {
+        return
+    }
+    s.write(spoolEntry{Seq: msg.seq, Level: msg.level, Time: msg.timestamp.UnixNano(), Msg: text})
+    s.pending++
+}
+
+func (s *spool) ack(seqs []uint64) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if s.file == nil {
+        return
+    }
+    s.write(spoolEntry{Ack: seqs})
+    s.pending -= len(seqs)
+}
+
+// 没有待确认的记录时清空spool文件
+func (s *spool) compact() {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if s.file == nil || s.pending > 0 {
+        return
+    }
+    s.file.Truncate(0)
+}
+
+// 正常关闭时所有记录都已落盘，直接删除spool文件
+func (s *spool) close() {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if s.file == nil {
+        return
+    }
+    s.file.Close()
+    s.file = nil
+    if s.pending <= 0 {
+        os.Remove(s.path)
+    }
+}
+
+// 写入日志文件后确认这批记录
+func (l *Logger) spoolAck(msgs []logMessage) {
+    if l.spool == nil || len(msgs) == 0 {
+        return
+    }
+    seqs := make([]uint64, len(msgs))
+    for i, msg := range msgs {
+        seqs[i] = msg.seq
+    }
+    l.spool.ack(seqs)
+}
+
+
+// 回放上次异常退出遗留的 .pending spool文件：只写入没有ack的记录，同一序号只写一次，按序号顺序写入对应级别的日志文件，
+// 恢复的记录带有 recovered=true 字段。在NewLogger中、处理协程启动之前执行，保证恢复的记录排在新记录之前
+func (l *Logger) replaySpool(path string) (int, error) {
+    files, err := filepath.Glob(path + ".*.pending")
+    if err != nil {
+        return 0, err
+    }
+    sort.Strings(files)
+
+    total := 0
+    for _, file := range files {
+        entries, err := readSpool(file)
+        if err != nil {
+            return total, fmt.Errorf("读取遗留spool文件失败: %v", err)
+        }
+        for _, e := range entries {
+            msg := logMessage{level: e.Level, timestamp: time.Unix(0, e.Time), msg: []interface{}{e.Msg}, seq: e.Seq}
+            switch e.Level {
+            case "INFO":
+                l.InfoLogger.Println(l.encodeExtra(msg, Any("recovered", true)))
+            case "DEBUG":
+                l.DebugLogger.Println(l.encodeExtra(msg, Any("recovered", true)))
+            case "ERROR":
+                l.ErrorLogger.Println(l.encodeExtra(msg, Any("recovered", true)))
+            default:
+                continue
+            }
+            total++
+        }
+        if err := os.Remove(file); err != nil {
+            return total, err
+        }
+    }
+    return total, nil
+}
+
+// 读取spool文件，返回未被ack的记录（按序号去重、排序）。崩溃时最后一行可能只写了一半，解析失败的行直接跳过
+func readSpool(path string) ([]spoolEntry, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+
+    records := make(map[uint64]spoolEntry)
+    acked := make(map[uint64]bool)
+    scanner := bufio.NewScanner(f)
+    scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+    for scanner.Scan() {
+        var e spoolEntry
+        if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+            continue
+        }
+        if len(e.Ack) > 0 {
+            for _, seq := range e.Ack {
+                acked[seq] = true
+            }
+            continue
+        }
+        records[e.Seq] = e
+    }
+    if err := scanner.Err(); err != nil {
+        return nil, err
+    }
+
+    entries := make([]spoolEntry, 0, len(records))
+    for seq, e := range records {
+        if !acked[seq] {
+            entries = append(entries, e)
+        }
+    }
+    sort.Slice(entries, func(i, j int) bool { return entries[i].Seq < entries[j].Seq })
+    return entries, nil
+}
\ No newline at end of file
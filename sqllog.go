@@ -0,0 +1,338 @@
+package jLogger
+
+import (
+    "context"
+    "database/sql"
+    "database/sql/driver"
+    "errors"
+    "fmt"
+    "io"
+    "strings"
+    "time"
+)
+
+// SQLOptions SQL日志的配置
+type SQLOptions struct {
+    SlowThreshold time.Duration                      // >0时耗时超过该值的语句以Error记录（sql slow）
+    Redact        func(arg driver.NamedValue) string // 参数的输出形式；为nil时只输出参数类型，不输出值
+}
+
+// RegisterSQLDriver 用name注册一个包装了d的数据库驱动，通过 sql.Open(name, dsn) 使用，
+// 每条语句的SQL、参数（默认只输出类型）、行数和耗时以Debug记录，慢语句和出错的语句以Error记录。
+// 想让SQL日志写到单独的文件，传入一个以"sql"为前缀单独创建的Logger即可；只想区分来源时传 l.Named("sql")
+func RegisterSQLDriver(name string, d driver.Driver, l Interface, opts SQLOptions) {
+    sql.Register(name, WrapDriver(d, l, opts))
+}
+
+// WrapDriver 包装数据库驱动，见RegisterSQLDriver
+func WrapDriver(d driver.Driver, l Interface, opts SQLOptions) driver.Driver {
+    return &sqlDriver{Driver: d, log: &sqlLogger{l: l, opts: opts}}
+}
+
+// WrapConnector 包装Connector，用于 sql.OpenDB 的场景
+func WrapConnector(c driver.Connector, l Interface, opts SQLOptions) driver.Connector {
+    return &sqlConnector{Connector: c, log: &sqlLogger{l: l, opts: opts}}
+}
+
+type sqlLogger struct {
+    l    Interface
+    opts SQLOptions
+}
+
+// rows<0表示行数未知
+func (s *sqlLogger) log(op, query string, args []driver.NamedValue, rows int64, start time.Time, err error) {
+    if err == driver.ErrSkip {
+        return
+    }
+    elapsed := time.Since(start)
+    fields := []Field{String("op", op), String("query", query)}
+    if len(args) > 0 {
+        fields = append(fields, String("args", s.args(args)))
+    }
+    if rows >= 0 {
+        fields = append(fields, Int64("rows", rows))
+    }
+    fields = append(fields, Duration("duration", elapsed))
+    switch {
+    case err != nil:
+        s.l.ErrorFields("sql error", append(fields, Err(err))...)
+    case s.opts.SlowThreshold > 0 && elapsed > s.opts.SlowThreshold:
+        s.l.ErrorFields("sql slow", append(fields, Duration("threshold", s.opts.SlowThreshold))...)
+    default:
+        s.l.DebugFields("sql", fields...)
+    }
+}
+
+func (s *sqlLogger) args(args []driver.NamedValue) string {
+    parts := make([]string, len(args))
+    for i, a := range args {
+        if s.opts.Redact != nil {
+            parts[i] = s.opts.Redact(a)
+        } else {
+            parts[i] = fmt.Sprintf("%T", a.Value)
+        }
+    }
+    return "[" + strings.Join(parts, " ") + "]"
+}
+
+type sqlDriver struct {
+    driver.Driver
+    log *sqlLogger
+}
+
+func (d *sqlDriver) Open(name string) (driver.Conn, error) {
+    conn, err := d.Driver.Open(name)
+    if err != nil {
+        return nil, err
+    }
+    return &sqlConn{Conn: conn, log: d.log}, nil
+}
+
+func (d *sqlDriver) OpenConnector(name string) (driver.Connector, error) {
+    if dc, ok := d.Driver.(driver.DriverContext); ok {
+        c, err := dc.OpenConnector(name)
+        if err != nil {
+            return nil, err
+        }
+        return &sqlConnector{Connector: c, log: d.log}, nil
+    }
+    return &dsnConnector{name: name, d: d}, nil
+}
+
+type dsnConnector struct {
+    name string
+    d    *sqlDriver
+}
+
+func (c *dsnConnector) Connect(context.Context) (driver.Conn, error) {
+    return c.d.Open(c.name)
+}
+
+func (c *dsnConnector) Driver() driver.Driver {
+    return c.d
+}
+
+type sqlConnector struct {
+    driver.Connector
+    log *sqlLogger
+}
+
+func (c *sqlConnector) Connect(ctx context.Context) (driver.Conn, error) {
+    conn, err := c.Connector.Connect(ctx)
+    if err != nil {
+        return nil, err
+    }
+    return &sqlConn{Conn: conn, log: c.log}, nil
+}
+
+func (c *sqlConnector) Driver() driver.Driver {
+    return &sqlDriver{Driver: c.Connector.Driver(), log: c.log}
+}
+
+// 包装连接，底层连接没有实现的可选接口返回driver.ErrSkip或默认行为，database/sql会自动退回到通用路径
+type sqlConn struct {
+    driver.Conn
+    log *sqlLogger
+}
+
+func (c *sqlConn) Prepare(query string) (driver.Stmt, error) {
+    return c.PrepareContext(context.Background(), query)
+}
+
+func (c *sqlConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+    var stmt driver.Stmt
+    var err error
+    if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+        stmt, err = p.PrepareContext(ctx, query)
+    } else {
+        stmt, err = c.Conn.Prepare(query)
+    }
+    if err != nil {
+        return nil, err
+    }
+    return &sqlStmt{Stmt: stmt, query: query, log: c.log}, nil
+}
+
+func (c *sqlConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+    if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+        return b.BeginTx(ctx, opts)
+    }
+    if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) || opts.ReadOnly {
+        return nil, errors.New("驱动不支持设置事务隔离级别或只读事务")
+    }
+    return c.Conn.Begin()
+}
+
+func (c *sqlConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+    e, ok := c.Conn.(driver.ExecerContext)
+    if !ok {
+        return nil, driver.ErrSkip
+    }
+    start := time.Now()
+    res, err := e.ExecContext(ctx, query, args)
+    c.log.log("exec", query, args, rowsAffected(res, err), start, err)
+    return res, err
+}
+
+func (c *sqlConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+    q, ok := c.Conn.(driver.QueryerContext)
+    if !ok {
+        return nil, driver.ErrSkip
+    }
+    start := time.Now()
+    rows, err := q.QueryContext(ctx, query, args)
+    if err != nil {
+        c.log.log("query", query, args, -1, start, err)
+        return nil, err
+    }
+    return &sqlRows{Rows: rows, query: query, args: args, start: start, log: c.log}, nil
+}
+
+func (c *sqlConn) Ping(ctx context.Context) error {
+    if p, ok := c.Conn.(driver.Pinger); ok {
+        return p.Ping(ctx)
+    }
+    return nil
+}
+
+func (c *sqlConn) ResetSession(ctx context.Context) error {
+    if r, ok := c.Conn.(driver.SessionResetter); ok {
+        return r.ResetSession(ctx)
+    }
+    return nil
+}
+
+func (c *sqlConn) IsValid() bool {
+    if v, ok := c.Conn.(driver.Validator); ok {
+        return v.IsValid()
+    }
+    return true
+}
+
+func (c *sqlConn) CheckNamedValue(nv *driver.NamedValue) error {
+    if n, ok := c.Conn.(driver.NamedValueChecker); ok {
+        return n.CheckNamedValue(nv)
+    }
+    return driver.ErrSkip
+}
+
+type sqlStmt struct {
+    driver.Stmt
+    query string
+    log   *sqlLogger
+}
+
+func (s *sqlStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+    start := time.Now()
+    var res driver.Result
+    var err error
+    if e, ok := s.Stmt.(driver.StmtExecContext); ok {
+        res, err = e.ExecContext(ctx, args)
+    } else {
+        var values []driver.Value
+        if values, err = namedValues(args); err == nil {
+            res, err = s.Stmt.Exec(values)
+        }
+    }
+    s.log.log("exec", s.query, args, rowsAffected(res, err), start, err)
+    return res, err
+}
+
+func (s *sqlStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+    start := time.Now()
+    var rows driver.Rows
+    var err error
+    if q, ok := s.Stmt.(driver.StmtQueryContext); ok {
+        rows, err = q.QueryContext(ctx, args)
+    } else {
+        var values []driver.Value
+        if values, err = namedValues(args); err == nil {
+            rows, err = s.Stmt.Query(values)
+        }
+    }
+    if err != nil {
+        s.log.log("query", s.query, args, -1, start, err)
+        return nil, err
+    }
+    return &sqlRows{Rows: rows, query: s.query, args: args, start: start, log: s.log}, nil
+}
+
+func (s *sqlStmt) CheckNamedValue(nv *driver.NamedValue) error {
+    if n, ok := s.Stmt.(driver.NamedValueChecker); ok {
+        return n.CheckNamedValue(nv)
+    }
+    return driver.ErrSkip
+}
+
+func (s *sqlStmt) ColumnConverter(idx int) driver.ValueConverter {
+    if c, ok := s.Stmt.(driver.ColumnConverter); ok {
+        return c.ColumnConverter(idx)
+    }
+    return driver.DefaultParameterConverter
+}
+
+// 查询的日志在结果集关闭时记录，此时才知道行数和完整的耗时
+type sqlRows struct {
+    driver.Rows
+    query string
+    args  []driver.NamedValue
+    start time.Time
+    log   *sqlLogger
+    n     int64
+    err   error
+}
+
+func (r *sqlRows) Next(dest []driver.Value) error {
+    err := r.Rows.Next(dest)
+    if err == nil {
+        r.n++
+    } else if err != io.EOF {
+        r.err = err
+    }
+    return err
+}
+
+func (r *sqlRows) HasNextResultSet() bool {
+    if n, ok := r.Rows.(driver.RowsNextResultSet); ok {
+        return n.HasNextResultSet()
+    }
+    return false
+}
+
+func (r *sqlRows) NextResultSet() error {
+    if n, ok := r.Rows.(driver.RowsNextResultSet); ok {
+        return n.NextResultSet()
+    }
+    return io.EOF
+}
+
+func (r *sqlRows) Close() error {
+    err := r.Rows.Close()
+    if r.err == nil {
+        r.err = err
+    }
+    r.log.log("query", r.query, r.args, r.n, r.start, r.err)
+    return err
+}
+
+func rowsAffected(res driver.Result, err error) int64 {
+    if err != nil || res == nil {
+        return -1
+    }
+    n, err := res.RowsAffected()
+    if err != nil {
+        return -1
+    }
+    return n
+}
+
+func namedValues(args []driver.NamedValue) ([]driver.Value, error) {
+    values := make([]driver.Value, len(args))
+    for i, a := range args {
+        if a.Name != "" {
+            return nil, errors.New("驱动不支持命名参数")
+        }
+        values[i] = a.Value
+    }
+    return values, nil
+}
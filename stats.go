@@ -0,0 +1,157 @@
+package jLogger
+
+import (
+    "fmt"
+    "sort"
+    "sync/atomic"
+    "time"
+)
+
+// 保留最近多少条内部错误
+const recentErrorsMax = 20
+
+// InternalError Logger自身运行中出现的错误（配置解析失败、关闭回调失败等）
+type InternalError struct {
+    Time    time.Time `json:"time"`
+    Message string    `json:"message"`
+}
+
+// Stats Logger运行状态快照
+type Stats struct {
+    ConfigHash        string            `json:"config_hash"` // 同EffectiveConfig().Hash
+    Rotation          map[string]RotationConfig `json:"rotation"`
+    Level             string            `json:"level"`
+    BufferSize        int               `json:"buffer_size"`
+    FlushInterval     string            `json:"flush_interval"`
+    Options           map[string]string `json:"options"`
+    ModuleLevels      map[string]string `json:"module_levels"`
+    BufferDepth       map[string]int    `json:"buffer_depth"`      // 各级别缓冲区中待写入的条数
+    ChannelDepth      int               `json:"channel_depth"`     // Info/Debug通道中排队的条数
+    ErrorChannelDepth int               `json:"error_channel_depth"`
+    Dropped           map[string]uint64 `json:"dropped"` // 各级别累计的通道溢出条数
+    WriteLatency      map[string]LatencyStats `json:"write_latency"` // 各级别从记录产生到写入文件的耗时
+    Sequence          uint64            `json:"sequence"` // 已分配的最大序号
+    SampleRate        float64           `json:"sample_rate"`
+    SampledOut        uint64            `json:"sampled_out"` // 被采样丢弃的条数
+    Degradation       string            `json:"degradation,omitempty"` // 当前降级阶段，未配置WithDegradation时为空
+    DegradedOut       uint64            `json:"degraded_out"`          // 因降级被丢弃的条数
+    LateRecords       uint64            `json:"late_records"`          // Close之后收到的记录数
+    SchemaViolations  uint64            `json:"schema_violations"` // 违反字段约定的记录数
+    SchemaUnreported  uint64            `json:"schema_unreported"` // 违反约定的消息模板过多，没有记内部错误的次数
+    MemoryUsed        int64             `json:"memory_used"`       // 排队记录占用的内存估算，未设置WithMemoryLimit时为0
+    MemoryLimit       int64             `json:"memory_limit"`
+    MemoryRejected    uint64            `json:"memory_rejected"` // 超出内存上限被丢弃或改为同步写入的条数
+    ErrorBudget       *ErrorBudgetState `json:"error_budget,omitempty"` // 未配置WithErrorBudget时为nil
+    Sinks             []string          `json:"sinks"`
+    SinkHealth        []SinkHealth      `json:"sink_health"`
+    RecentErrors      []InternalError   `json:"recent_errors"`
+}
+
+// Stats 返回当前配置和管道状态的快照，克隆的Logger返回共享管道的状态
+func (l *Logger) Stats() Stats {
+    l = l.pipeline()
+    st := Stats{
+        BufferSize:        l.bufferSize,
+        FlushInterval:     l.flushInterval.String(),
+        Options:           l.optionSummary(),
+        ModuleLevels:      make(map[string]string),
+        BufferDepth:       make(map[string]int, 3),
+        ChannelDepth:      len(l.logChannel),
+        ErrorChannelDepth: len(l.errorChannel),
+        Dropped:           make(map[string]uint64, 3),
+        Sequence:          atomic.LoadUint64(&l.seq),
+        SampleRate:        float64(atomic.LoadUint64(&l.sampleThreshold)) / sampleScale,
+        SampledOut:        atomic.LoadUint64(&l.sampledOut),
+        DegradedOut:       atomic.LoadUint64(&l.degradedOut),
+        LateRecords:       atomic.LoadUint64(&l.lateRecords),
+        SchemaViolations:  atomic.LoadUint64(&l.schemaViolations),
+        SchemaUnreported:  atomic.LoadUint64(&l.schemaUnreported),
+        MemoryUsed:        atomic.LoadInt64(&l.memUsed),
+        MemoryLimit:       l.memoryLimit,
+        MemoryRejected:    atomic.LoadUint64(&l.memRejected),
+    }
+
+    l.info_mu.Lock()
+    st.BufferDepth["INFO"] = len(l.bufferInfo)
+    l.info_mu.Unlock()
+    l.debug_mu.Lock()
+    st.BufferDepth["DEBUG"] = len(l.bufferDebug)
+    l.debug_mu.Unlock()
+    l.error_mu.Lock()
+    st.BufferDepth["ERROR"] = len(l.bufferError)
+    l.error_mu.Unlock()
+
+    if l.budget != nil {
+        st.ErrorBudget = l.budget.state()
+    }
+    if l.degrade != nil {
+        st.Degradation = degradeStageNames[atomic.LoadInt32(&l.degradeStage)]
+    }
+
+    st.WriteLatency = make(map[string]LatencyStats, 3)
+    for i, name := range levelNames {
+        st.Dropped[name] = atomic.LoadUint64(&l.dropCounts[i])
+        st.WriteLatency[name] = l.latency[i].stats()
+    }
+
+    l.levels_mu.RLock()
+    st.Level = l.log_level
+    for module, level := range l.moduleLevels {
+        st.ModuleLevels[module] = level
+    }
+    l.levels_mu.RUnlock()
+
+    l.sinks_mu.RLock()
+    for name := range l.sinks {
+        st.Sinks = append(st.Sinks, name)
+    }
+    l.sinks_mu.RUnlock()
+    sort.Strings(st.Sinks)
+    st.SinkHealth = l.SinkHealth()
+
+    c := l.EffectiveConfig()
+    st.ConfigHash, st.Rotation = c.Hash, c.Rotation
+
+    l.errors_mu.Lock()
+    st.RecentErrors = append([]InternalError(nil), l.recentErrors...)
+    l.errors_mu.Unlock()
+    return st
+}
+
+// 可选配置的摘要，用于调试页面展示
+func (l *Logger) optionSummary() map[string]string {
+    return map[string]string{
+        "json":              fmt.Sprint(l.json),
+        "escape":            fmt.Sprint(l.escape),
+        "max_message_bytes": fmt.Sprint(l.maxMessageBytes),
+        "sequence":          fmt.Sprint(l.emitSeq),
+        "record_id":         fmt.Sprint(l.newID != nil),
+        "ordered":           fmt.Sprint(l.ordered),
+        "merged_flush":      fmt.Sprint(l.merged),
+        "spool":             fmt.Sprint(l.spool != nil),
+        "adaptive_flush":    fmt.Sprint(l.adaptiveFlush),
+        "error_flush_delay": l.errorFlushDelay.String(),
+        "schema_validation": fmt.Sprint(l.validateSchema),
+        "write_time":        fmt.Sprint(l.writeTime),
+        "crlf":              fmt.Sprint(l.crlf),
+        "nested_groups":     fmt.Sprint(l.nestedGroups),
+        "memory_limit":      fmt.Sprint(l.memoryLimit),
+        "multi_process":     fmt.Sprint(l.multiProcess),
+        "dead_letter":       fmt.Sprint(l.deadLetters != nil),
+        "daily_rotation":    l.daily.String(),
+    }
+}
+
+// 记录一条内部错误：写入Error日志文件，同时保留在最近错误列表中供Stats和调试页面查看
+func (l *Logger) internalError(v ...interface{}) {
+    l.writeDirect("ERROR", v...)
+
+    e := InternalError{Time: time.Now(), Message: fmt.Sprintln(v...)}
+    e.Message = e.Message[:len(e.Message)-1]
+    l.errors_mu.Lock()
+    if len(l.recentErrors) >= recentErrorsMax {
+        l.recentErrors = append(l.recentErrors[:0], l.recentErrors[1:]...)
+    }
+    l.recentErrors = append(l.recentErrors, e)
+    l.errors_mu.Unlock()
+}
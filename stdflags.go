@@ -0,0 +1,66 @@
+package jLogger
+
+import (
+    "log"
+    "path/filepath"
+    "runtime"
+    "strconv"
+    "strings"
+)
+
+// 支持的标准库log标志，其余标志（如Lmsgprefix）被忽略
+const stdFlagsMask = log.Ldate | log.Ltime | log.Lmicroseconds | log.LUTC | log.Lshortfile | log.Llongfile
+
+// 文本格式的行首时间：默认为jLogger的格式，按WithStdFlags配置了该级别时按标准库log的标志格式化。
+// 时间取事件时间而不是写入时间，文件和行号取业务代码中调用日志方法的位置，而不是管道中写入文件的位置
+func (l *Logger) textHeader(msg logMessage) string {
+    flags, ok := l.stdFlags[msg.level]
+    if !ok {
+        return msg.timestamp.Format(timeFormat) + " "
+    }
+    var b []byte
+    t := msg.timestamp
+    if flags&log.LUTC != 0 {
+        t = t.UTC()
+    }
+    if flags&log.Ldate != 0 {
+        b = t.AppendFormat(b, "2006/01/02 ")
+    }
+    if flags&(log.Ltime|log.Lmicroseconds) != 0 {
+        if flags&log.Lmicroseconds != 0 {
+            b = t.AppendFormat(b, "15:04:05.000000 ")
+        } else {
+            b = t.AppendFormat(b, "15:04:05 ")
+        }
+    }
+    if flags&(log.Lshortfile|log.Llongfile) != 0 {
+        file, line := callerLine(msg.callers)
+        if flags&log.Lshortfile != 0 {
+            file = filepath.Base(file)
+        }
+        b = append(b, file...)
+        b = append(b, ':')
+        b = strconv.AppendInt(b, int64(line), 10)
+        b = append(b, ": "...)
+    }
+    return string(b)
+}
+
+// 该级别的行首是否需要调用位置，需要时在生产者中捕获调用栈
+func (l *Logger) needsCaller(level string) bool {
+    return l.stdFlags[level]&(log.Lshortfile|log.Llongfile) != 0
+}
+
+// 跳过本包内部的栈帧，返回业务代码中调用日志方法的文件和行号，见callerFunction
+func callerLine(pcs []uintptr) (string, int) {
+    if len(pcs) == 0 {
+        return "???", 0
+    }
+    frames := runtime.CallersFrames(pcs)
+    for {
+        frame, more := frames.Next()
+        if !strings.Contains(frame.Function, "/jLogger.") || !more {
+            return frame.File, frame.Line
+        }
+    }
+}
@@ -0,0 +1,46 @@
+package jLogger
+
+import (
+    "encoding/json"
+    "net/http"
+)
+
+// StreamHandler 返回通过Server-Sent Events推送实时日志的http.Handler，浏览器用EventSource即可查看，
+// 不需要登录机器。支持 ?level=INFO（最低级别）和 ?module=server.http（模块及其子模块）过滤。
+// 需要自行挂载在有访问控制的路由下
+func (l *Logger) StreamHandler() http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        flusher, ok := w.(http.Flusher)
+        if !ok {
+            http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+            return
+        }
+        q := r.URL.Query()
+        records, cancel := l.Subscribe(256, LevelFilter(q.Get("level"), q.Get("module")))
+        defer cancel()
+
+        w.Header().Set("Content-Type", "text/event-stream")
+        w.Header().Set("Cache-Control", "no-cache")
+        w.Header().Set("Connection", "keep-alive")
+        flusher.Flush()
+
+        for {
+            select {
+            case <-r.Context().Done():
+                return
+            case rec, ok := <-records:
+                if !ok {
+                    return
+                }
+                data, err := json.Marshal(rec)
+                if err != nil {
+                    continue
+                }
+                w.Write([]byte("data: "))
+                w.Write(data)
+                w.Write([]byte("\n\n"))
+                flusher.Flush()
+            }
+        }
+    })
+}
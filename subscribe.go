@@ -0,0 +1,114 @@
+package jLogger
+
+import (
+    "strings"
+    "sync"
+    "sync/atomic"
+    "time"
+)
+
+// Record 推送给订阅者的一条日志记录
+type Record struct {
+    Time    time.Time `json:"time"`
+    Level   string    `json:"level"`
+    Module  string    `json:"module,omitempty"`
+    Tag     string    `json:"tag,omitempty"`
+    Seq     uint64    `json:"seq"`
+    LogID   string    `json:"log_id,omitempty"` // 见WithRecordID，下游可以按它去重
+    Message string    `json:"msg"`
+    Fields  []Field   `json:"fields,omitempty"`
+    Version int       `json:"v,omitempty"` // 从文件读取的JSON记录的格式版本，见SchemaVersion
+}
+
+type subscriber struct {
+    ch     chan Record
+    filter func(Record) bool
+}
+
+// Subscribe 订阅进程内的实时日志，buffer为通道容量，filter为nil时接收全部记录。
+// 记录在处理协程中推送，订阅者处理不过来时直接丢弃，不会拖慢日志管道；用完后必须调用cancel
+func (l *Logger) Subscribe(buffer int, filter func(Record) bool) (<-chan Record, func()) {
+    l = l.pipeline()
+    sub := &subscriber{ch: make(chan Record, buffer), filter: filter}
+
+    l.subs_mu.Lock()
+    if l.subscribers == nil {
+        l.subscribers = make(map[*subscriber]struct{})
+    }
+    l.subscribers[sub] = struct{}{}
+    atomic.StoreInt32(&l.subCount, int32(len(l.subscribers)))
+    l.subs_mu.Unlock()
+
+    var once sync.Once
+    cancel := func() {
+        once.Do(func() {
+            l.subs_mu.Lock()
+            delete(l.subscribers, sub)
+            atomic.StoreInt32(&l.subCount, int32(len(l.subscribers)))
+            close(sub.ch)
+            l.subs_mu.Unlock()
+        })
+    }
+    return sub.ch, cancel
+}
+
+// 把记录推送给所有订阅者，没有订阅者时只有一次原子读的开销
+func (l *Logger) publish(msg logMessage) {
+    if msg.fileOnly || atomic.LoadInt32(&l.subCount) == 0 {
+        return
+    }
+    r := l.toRecord(msg)
+
+    l.subs_mu.Lock()
+    defer l.subs_mu.Unlock()
+    for sub := range l.subscribers {
+        if sub.filter != nil && !sub.filter(r) {
+            continue
+        }
+        select {
+        case sub.ch <- r:
+        default:
+        }
+    }
+}
+
+func (l *Logger) toRecord(msg logMessage) Record {
+    args, fields := msg.split()
+    return Record{
+        Time:    msg.timestamp,
+        Level:   msg.level,
+        Module:  msg.module,
+        Tag:     msg.tag,
+        Seq:     msg.seq,
+        LogID:   msg.id,
+        Message: l.formatArgs(args),
+        Fields:  fields,
+    }
+}
+
+// 级别从低到高的排序，用于按最低级别过滤
+func levelRank(level string) int {
+    switch level {
+    case "DEBUG":
+        return 0
+    case "INFO":
+        return 1
+    case "ERROR":
+        return 2
+    }
+    return -1
+}
+
+// LevelFilter 返回只接收不低于minLevel、且属于module（含子模块）的记录的过滤函数，参数为空表示不过滤该项
+func LevelFilter(minLevel, module string) func(Record) bool {
+    min := levelRank(strings.ToUpper(minLevel))
+    return func(r Record) bool {
+        if min > 0 && levelRank(r.Level) < min {
+            return false
+        }
+        if module != "" && r.Module != module && !strings.HasPrefix(r.Module, module+".") {
+            return false
+        }
+        return true
+    }
+}
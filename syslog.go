@@ -0,0 +1,160 @@
+package jLogger
+
+import (
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// syslog结构化数据的SD-ID，私有企业号32473为RFC 5612中保留给文档示例的编号
+const syslogSDID = "jlogger@32473"
+
+// SyslogSinkOptions syslog Sink的配置
+type SyslogSinkOptions struct {
+    Facility int           // syslog facility（1-23），为0时使用1（user-level）
+    Hostname string        // HOSTNAME字段，默认os.Hostname
+    AppName  string        // APP-NAME字段，默认可执行文件名
+    Timeout  time.Duration // 连接和写入超时，默认10秒
+    TLS      *SinkTLS      // 不为nil时按RFC 5425通过TLS发送，通常使用6514端口
+}
+
+// SyslogSink 把记录按RFC 5424格式、RFC 6587的octet-counting分帧发送到syslog服务器（TCP或TLS），
+// 结构化字段放在STRUCTURED-DATA中，模块名作为MSGID。连接的建立和重连同TCPSink
+type SyslogSink struct {
+    tcp      *TCPSink
+    facility int
+    hostname string
+    appName  string
+    procID   string
+}
+
+// NewSyslogSink 创建syslog Sink，通过AddSink挂载
+func NewSyslogSink(addr string, opts SyslogSinkOptions) (*SyslogSink, error) {
+    tcp, err := NewTCPSink(addr, TCPSinkOptions{Timeout: opts.Timeout, TLS: opts.TLS})
+    if err != nil {
+        return nil, err
+    }
+    if opts.Facility < 0 || opts.Facility > 23 {
+        return nil, fmt.Errorf("无效的syslog facility: %d", opts.Facility)
+    }
+    if opts.Facility == 0 {
+        opts.Facility = 1
+    }
+    if opts.Hostname == "" {
+        opts.Hostname, _ = os.Hostname()
+    }
+    if opts.AppName == "" {
+        opts.AppName = filepath.Base(os.Args[0])
+    }
+    s := &SyslogSink{
+        tcp:      tcp,
+        facility: opts.Facility,
+        hostname: syslogHeaderField(opts.Hostname, 255),
+        appName:  syslogHeaderField(opts.AppName, 48),
+        procID:   strconv.Itoa(os.Getpid()),
+    }
+    tcp.encode = s.encodeBatch
+    return s, nil
+}
+
+func (s *SyslogSink) WriteBatch(batch []Record) error {
+    return s.tcp.WriteBatch(batch)
+}
+
+func (s *SyslogSink) Close() error {
+    return s.tcp.Close()
+}
+
+// 每条记录一帧：MSG-LEN SP SYSLOG-MSG
+func (s *SyslogSink) encodeBatch(w io.Writer, batch []Record) error {
+    for _, r := range batch {
+        msg := s.format(r)
+        if _, err := io.WriteString(w, strconv.Itoa(len(msg))+" "+msg); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD] MSG
+func (s *SyslogSink) format(r Record) string {
+    var b strings.Builder
+    b.WriteByte('<')
+    b.WriteString(strconv.Itoa(s.facility*8 + syslogSeverity(r.Level)))
+    b.WriteString(">1 ")
+    b.WriteString(r.Time.Format("2006-01-02T15:04:05.000000Z07:00"))
+    b.WriteByte(' ')
+    b.WriteString(s.hostname)
+    b.WriteByte(' ')
+    b.WriteString(s.appName)
+    b.WriteByte(' ')
+    b.WriteString(s.procID)
+    b.WriteByte(' ')
+    b.WriteString(syslogHeaderField(r.Module, 32))
+    b.WriteByte(' ')
+    if len(r.Fields) == 0 {
+        b.WriteByte('-')
+    } else {
+        b.WriteString("[" + syslogSDID)
+        for _, f := range flattenGroups(r.Fields) {
+            name := syslogParamName(f.Key)
+            if name == "" {
+                continue
+            }
+            b.WriteString(" " + name + `="`)
+            b.WriteString(syslogParamValue(f.Text()))
+            b.WriteByte('"')
+        }
+        b.WriteByte(']')
+    }
+    if r.Message != "" {
+        b.WriteByte(' ')
+        b.WriteString(r.Message)
+    }
+    return b.String()
+}
+
+// 级别对应的syslog severity
+func syslogSeverity(level string) int {
+    switch level {
+    case "DEBUG":
+        return 7
+    case "INFO":
+        return 6
+    }
+    return 3
+}
+
+// 头部字段只能是可打印的ASCII、不能含空格，空值写 "-"
+func syslogHeaderField(s string, max int) string {
+    var b strings.Builder
+    for i := 0; i < len(s) && b.Len() < max; i++ {
+        if c := s[i]; c > 32 && c < 127 {
+            b.WriteByte(c)
+        }
+    }
+    if b.Len() == 0 {
+        return "-"
+    }
+    return b.String()
+}
+
+// PARAM-NAME最长32个字符，不能含 =、空格、]、"
+func syslogParamName(key string) string {
+    var b strings.Builder
+    for i := 0; i < len(key) && b.Len() < 32; i++ {
+        if c := key[i]; c > 32 && c < 127 && c != '=' && c != ']' && c != '"' {
+            b.WriteByte(c)
+        }
+    }
+    return b.String()
+}
+
+// PARAM-VALUE中的 "、\、] 需要转义
+func syslogParamValue(s string) string {
+    return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(s)
+}
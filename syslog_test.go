@@ -0,0 +1,81 @@
+package jLogger
+
+import (
+    "bufio"
+    "crypto/tls"
+    "encoding/pem"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestSyslogSinkTLS(t *testing.T) {
+    // 借用httptest的自签名证书，对127.0.0.1有效
+    srv := httptest.NewTLSServer(http.NotFoundHandler())
+    defer srv.Close()
+    ca := filepath.Join(t.TempDir(), "ca.pem")
+    if err := os.WriteFile(ca, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0644); err != nil {
+        t.Fatal(err)
+    }
+    ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: srv.TLS.Certificates})
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer ln.Close()
+    frames := make(chan string, 2)
+    go func() {
+        conn, err := ln.Accept()
+        if err != nil {
+            return
+        }
+        defer conn.Close()
+        br := bufio.NewReader(conn)
+        for {
+            size, err := br.ReadString(' ')
+            if err != nil {
+                return
+            }
+            n, _ := strconv.Atoi(strings.TrimSpace(size))
+            buf := make([]byte, n)
+            if _, err := io.ReadFull(br, buf); err != nil {
+                return
+            }
+            frames <- string(buf)
+        }
+    }()
+
+    s, err := NewSyslogSink(ln.Addr().String(), SyslogSinkOptions{Hostname: "web 1", AppName: "api", TLS: &SinkTLS{CAFile: ca}})
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer s.Close()
+    ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+    batch := []Record{
+        {Time: ts, Level: "ERROR", Module: "db", Message: "query failed", Fields: []Field{String("sql", `a"b]`), Int("n", 3)}},
+        {Time: ts, Level: "INFO", Message: "ok"},
+    }
+    if err := s.WriteBatch(batch); err != nil {
+        t.Fatal(err)
+    }
+    pid := strconv.Itoa(os.Getpid())
+    want := []string{
+        `<11>1 2024-01-02T03:04:05.000000Z web1 api ` + pid + ` db [jlogger@32473 sql="a\"b\]" n="3"] query failed`,
+        `<14>1 2024-01-02T03:04:05.000000Z web1 api ` + pid + ` - - ok`,
+    }
+    for _, w := range want {
+        select {
+        case got := <-frames:
+            if got != w {
+                t.Fatalf("收到\n%s\n期望\n%s", got, w)
+            }
+        case <-time.After(5 * time.Second):
+            t.Fatal("syslog服务器没有收到记录")
+        }
+    }
+}
@@ -0,0 +1,42 @@
+package jLogger
+
+import "time"
+
+// Timer 记录一次操作的耗时，由StartTimer创建
+type Timer struct {
+    l         Interface
+    name      string
+    start     time.Time
+    threshold time.Duration
+}
+
+// StartTimer 开始计时并写一条Debug日志，配合defer使用：
+// t := log.StartTimer("load users"); defer t.Done()
+// 取代到处手写的 time.Since 日志
+func (l *Logger) StartTimer(name string) *Timer {
+    return StartTimer(l, name)
+}
+
+// StartTimer 同l.StartTimer，接受任意Interface实现，包装过的Logger和jloggermock也可以使用
+func StartTimer(l Interface, name string) *Timer {
+    l.DebugFields(name + " start")
+    return &Timer{l: l, name: name, start: time.Now()}
+}
+
+// SlowAfter 设置慢操作阈值，耗时超过d时Done写Error日志（没有Warn级别，慢操作按Error处理以便告警）
+func (t *Timer) SlowAfter(d time.Duration) *Timer {
+    t.threshold = d
+    return t
+}
+
+// Done 结束计时，写一条带duration字段的Info日志，超过阈值时改为Error
+func (t *Timer) Done(fields ...Field) time.Duration {
+    elapsed := time.Since(t.start)
+    fields = append(fields, Duration("duration", elapsed))
+    if t.threshold > 0 && elapsed > t.threshold {
+        t.l.ErrorFields(t.name+" slow", append(fields, Duration("threshold", t.threshold))...)
+    } else {
+        t.l.InfoFields(t.name+" done", fields...)
+    }
+    return elapsed
+}
@@ -0,0 +1,44 @@
+package jLogger
+
+import (
+    "crypto/tls"
+    "crypto/x509"
+    "errors"
+    "os"
+)
+
+// SinkTLS 网络Sink（HTTPSink、TCPSink、SyslogSink）的TLS配置。CertFile和KeyFile同时设置时启用双向认证（mTLS）
+type SinkTLS struct {
+    CAFile     string // 校验服务端证书的CA（PEM），为空时使用系统根证书
+    CertFile   string // 客户端证书（PEM）
+    KeyFile    string // 客户端私钥（PEM）
+    ServerName string // 校验证书时使用的主机名，为空时取连接地址中的主机名
+    MinVersion uint16 // 最低TLS版本，如tls.VersionTLS13，默认TLS 1.2
+}
+
+// 生成tls.Config，证书文件在创建Sink时读取，配置错误在启动时就能发现
+func (c *SinkTLS) config() (*tls.Config, error) {
+    cfg := &tls.Config{ServerName: c.ServerName, MinVersion: c.MinVersion}
+    if cfg.MinVersion == 0 {
+        cfg.MinVersion = tls.VersionTLS12
+    }
+    if c.CAFile != "" {
+        pem, err := os.ReadFile(c.CAFile)
+        if err != nil {
+            return nil, err
+        }
+        pool := x509.NewCertPool()
+        if !pool.AppendCertsFromPEM(pem) {
+            return nil, errors.New("CA文件中没有可用的证书: " + c.CAFile)
+        }
+        cfg.RootCAs = pool
+    }
+    if c.CertFile != "" || c.KeyFile != "" {
+        cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+        if err != nil {
+            return nil, err
+        }
+        cfg.Certificates = []tls.Certificate{cert}
+    }
+    return cfg, nil
+}
@@ -0,0 +1,165 @@
+package jLogger
+
+import (
+    "fmt"
+    "strings"
+    "sync/atomic"
+    "time"
+)
+
+// 校验失败时附加到记录上的字段
+const schemaViolationKey = "schema_violation"
+
+// 最多记住多少个已经报告过的消息模板，模板中拼接了变量时种类没有上限，超出后不再记内部错误，只计数
+const schemaReportedMax = 1024
+
+// FieldType 字段约定中的期望类型
+type FieldType int
+
+const (
+    TypeAny FieldType = iota
+    TypeString
+    TypeInt
+    TypeFloat
+    TypeBool
+    TypeDuration
+    TypeTime
+    TypeError
+)
+
+func (t FieldType) String() string {
+    switch t {
+    case TypeString:
+        return "string"
+    case TypeInt:
+        return "int"
+    case TypeFloat:
+        return "float"
+    case TypeBool:
+        return "bool"
+    case TypeDuration:
+        return "duration"
+    case TypeTime:
+        return "time"
+    case TypeError:
+        return "error"
+    }
+    return "any"
+}
+
+// FieldSchema 一类日志记录的字段约定：必须出现的key和各key的类型
+type FieldSchema struct {
+    Required []string
+    Types    map[string]FieldType
+}
+
+// RegisterSchema 为消息模板（日志语句的第一个参数）登记字段约定，message为"*"时对所有记录生效。
+// 需要配合WithSchemaValidation开启校验
+func (l *Logger) RegisterSchema(message string, schema FieldSchema) {
+    l = l.pipeline()
+    l.schemas_mu.Lock()
+    defer l.schemas_mu.Unlock()
+    if l.schemas == nil {
+        l.schemas = make(map[string]FieldSchema)
+    }
+    l.schemas[message] = schema
+}
+
+// 按登记的约定校验一条记录，不符合时返回附加了schema_violation字段的字段列表。
+// 每个消息模板第一次违反约定时额外记一条内部错误，之后只计数，避免刷屏；记住的模板数有上限，见schemaReportedMax
+func (l *Logger) checkSchema(v []interface{}, fields []Field) []Field {
+    var template string
+    if len(v) > 0 {
+        template, _ = v[0].(string)
+    }
+    l.schemas_mu.RLock()
+    schema, ok := l.schemas[template]
+    all, okAll := l.schemas["*"]
+    l.schemas_mu.RUnlock()
+    if !ok && !okAll {
+        return fields
+    }
+
+    _, inline := splitFields(v)
+    present := make(map[string]Field, len(inline)+len(fields))
+    for _, f := range inline {
+        present[f.Key] = f
+    }
+    for _, f := range fields {
+        present[f.Key] = f
+    }
+
+    var problems []string
+    for _, s := range []FieldSchema{all, schema} {
+        for _, key := range s.Required {
+            if _, ok := present[key]; !ok {
+                problems = append(problems, "missing "+key)
+            }
+        }
+        for key, want := range s.Types {
+            if f, ok := present[key]; ok && want != TypeAny {
+                if got := fieldType(f); got != want {
+                    problems = append(problems, fmt.Sprintf("%s: want %v, got %v", key, want, got))
+                }
+            }
+        }
+    }
+    if len(problems) == 0 {
+        return fields
+    }
+
+    atomic.AddUint64(&l.schemaViolations, 1)
+    violation := strings.Join(problems, "; ")
+    l.schemas_mu.Lock()
+    if l.schemaReported == nil {
+        l.schemaReported = make(map[string]bool)
+    }
+    first := !l.schemaReported[template]
+    if first && len(l.schemaReported) >= schemaReportedMax {
+        first = false
+        atomic.AddUint64(&l.schemaUnreported, 1)
+    } else {
+        l.schemaReported[template] = true
+    }
+    l.schemas_mu.Unlock()
+    if first {
+        l.internalError("日志字段不符合约定:", quoteValue(template), violation)
+    }
+    // 复制一份，不修改调用方的切片
+    return append(append(make([]Field, 0, len(fields)+1), fields...), String(schemaViolationKey, violation))
+}
+
+// 字段值的实际类型
+func fieldType(f Field) FieldType {
+    switch f.kind {
+    case kindString:
+        return TypeString
+    case kindInt64:
+        return TypeInt
+    case kindFloat64:
+        return TypeFloat
+    case kindBool:
+        return TypeBool
+    case kindDuration:
+        return TypeDuration
+    case kindTime:
+        return TypeTime
+    }
+    switch f.Value.(type) {
+    case string:
+        return TypeString
+    case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+        return TypeInt
+    case float32, float64:
+        return TypeFloat
+    case bool:
+        return TypeBool
+    case time.Duration:
+        return TypeDuration
+    case time.Time:
+        return TypeTime
+    case error:
+        return TypeError
+    }
+    return TypeAny
+}
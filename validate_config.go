@@ -0,0 +1,167 @@
+package jLogger
+
+import (
+    "errors"
+    "fmt"
+    "net"
+    "net/url"
+    "os"
+    "path/filepath"
+    "strings"
+    "time"
+)
+
+// Config NewLogger的参数和常用的外部依赖，用于在部署前检查配置，见ValidateConfig
+type Config struct {
+    Dir           string            `json:"dir"`
+    Prefix        string            `json:"prefix"`
+    BufferSize    int               `json:"buffer_size"`
+    FlushInterval string            `json:"flush_interval"` // time.ParseDuration的格式，如 "5s"
+    Level         string            `json:"level"`
+    Modules       map[string]string `json:"modules"` // 模块级别，同SetModuleLevel
+    Sinks         []string          `json:"sinks"`   // Sink的地址：http(s)://、tcp://host:port 或 unix:///path
+    SinkFields    map[string]FieldFilter `json:"sink_fields"` // 各Sink的字段过滤规则，见SetSinkFields
+}
+
+// 检查Sink连通性的超时
+const validateDialTimeout = 3 * time.Second
+
+// ValidateConfig 不启动管道，检查目录和权限、当前日志文件能否写入和轮转、缓冲参数、级别字符串以及Sink能否连接，
+// 返回发现的全部问题（errors.Join），没有问题时返回nil。用于CI和部署前的预检，见 jlog validate
+func ValidateConfig(cfg Config) error {
+    var errs []error
+    add := func(format string, args ...interface{}) {
+        errs = append(errs, fmt.Errorf(format, args...))
+    }
+
+    if cfg.Prefix == "" {
+        add("prefix不能为空")
+    } else if strings.ContainsAny(cfg.Prefix, `/\`) {
+        add("prefix不能包含路径分隔符: %s", cfg.Prefix)
+    }
+    if cfg.BufferSize <= 0 {
+        add("buffer_size必须大于0: %d", cfg.BufferSize)
+    }
+    if d, err := time.ParseDuration(cfg.FlushInterval); err != nil {
+        add("flush_interval无效: %v", err)
+    } else if d <= 0 {
+        add("flush_interval必须大于0: %s", cfg.FlushInterval)
+    }
+    if _, ok := normalizeLevel(cfg.Level); !ok {
+        add("无效的日志级别: %q", cfg.Level)
+    }
+    for module, level := range cfg.Modules {
+        if _, ok := normalizeLevel(level); !ok {
+            add("无效的日志级别: %s=%q", module, level)
+        }
+    }
+    for name, f := range cfg.SinkFields {
+        if _, err := compileFieldFilter(f); err != nil {
+            add("sink_fields %s: %v", name, err)
+        }
+    }
+
+    if cfg.Dir == "" {
+        add("dir不能为空")
+    } else if err := checkLogDir(cfg.Dir, cfg.Prefix); err != nil {
+        errs = append(errs, err)
+    }
+
+    for _, addr := range cfg.Sinks {
+        if err := checkSink(addr); err != nil {
+            add("Sink %s 无法连接: %v", addr, err)
+        }
+    }
+    return errors.Join(errs...)
+}
+
+// 目录不存在时检查能否创建（NewLogger会MkdirAll）；存在时检查能否在其中创建和重命名文件（轮转需要），
+// 以及已有的当前日志文件能否以追加方式打开
+func checkLogDir(dir, prefix string) error {
+    fi, err := os.Stat(dir)
+    if os.IsNotExist(err) {
+        parent := dir
+        for {
+            parent = filepath.Dir(parent)
+            if _, err := os.Stat(parent); err == nil || parent == filepath.Dir(parent) {
+                break
+            }
+        }
+        return checkWritableDir(parent, dir)
+    }
+    if err != nil {
+        return fmt.Errorf("日志目录 %s 无法访问: %v", dir, err)
+    }
+    if !fi.IsDir() {
+        return fmt.Errorf("日志目录 %s 不是目录", dir)
+    }
+    if err := checkWritableDir(dir, dir); err != nil {
+        return err
+    }
+    if prefix == "" {
+        return nil
+    }
+    var errs []error
+    for _, level := range levelNames {
+        path := filepath.Join(dir, prefix+"_"+strings.ToLower(level)+".log")
+        f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+        if os.IsNotExist(err) {
+            continue
+        }
+        if err != nil {
+            errs = append(errs, fmt.Errorf("日志文件 %s 无法写入: %v", path, err))
+            continue
+        }
+        f.Close()
+    }
+    return errors.Join(errs...)
+}
+
+// 在dir中创建并重命名一个临时文件，target为报告错误时提到的日志目录
+func checkWritableDir(dir, target string) error {
+    f, err := os.CreateTemp(dir, ".jlogger-validate-*")
+    if err != nil {
+        return fmt.Errorf("日志目录 %s 无法创建文件: %v", target, err)
+    }
+    name := f.Name()
+    f.Close()
+    defer os.Remove(name)
+    if err := os.Rename(name, name+".rotated"); err != nil {
+        return fmt.Errorf("日志目录 %s 无法重命名文件，轮转会失败: %v", target, err)
+    }
+    os.Remove(name + ".rotated")
+    return nil
+}
+
+// 只检查能否建立连接，不发送任何数据
+func checkSink(addr string) error {
+    u, err := url.Parse(addr)
+    if err != nil {
+        return err
+    }
+    switch u.Scheme {
+    case "http", "https":
+        host := u.Host
+        if u.Port() == "" {
+            port := "80"
+            if u.Scheme == "https" {
+                port = "443"
+            }
+            host = net.JoinHostPort(u.Hostname(), port)
+        }
+        return dialCheck("tcp", host)
+    case "tcp":
+        return dialCheck("tcp", u.Host)
+    case "unix":
+        return dialCheck("unix", u.Path)
+    }
+    return fmt.Errorf("不支持的地址: %s", addr)
+}
+
+func dialCheck(network, address string) error {
+    conn, err := net.DialTimeout(network, address, validateDialTimeout)
+    if err != nil {
+        return err
+    }
+    return conn.Close()
+}
@@ -0,0 +1,29 @@
+package jLogger
+
+import (
+    "fmt"
+    "testing"
+)
+
+func TestSchemaReportedBounded(t *testing.T) {
+    l := newTestLogger(t, "INFO", WithSchemaValidation())
+    l.RegisterSchema("*", FieldSchema{Required: []string{"request_id"}})
+    // 模板中拼接了变量，每条记录都是不同的模板
+    const n = schemaReportedMax + 10
+    for i := 0; i < n; i++ {
+        l.Info(fmt.Sprintf("request %d done", i))
+    }
+    st := l.Stats()
+    if st.SchemaViolations != n {
+        t.Fatalf("SchemaViolations = %d, 期望%d", st.SchemaViolations, n)
+    }
+    if st.SchemaUnreported != 10 {
+        t.Fatalf("SchemaUnreported = %d, 期望10", st.SchemaUnreported)
+    }
+    l.schemas_mu.RLock()
+    size := len(l.schemaReported)
+    l.schemas_mu.RUnlock()
+    if size != schemaReportedMax {
+        t.Fatalf("记住了%d个模板, 期望上限%d", size, schemaReportedMax)
+    }
+}